@@ -1,7 +1,194 @@
 package main
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gerthdala/webcrawler/internal/domain/analysis"
+	"github.com/gerthdala/webcrawler/internal/domain/content"
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
+	crawlerinfra "github.com/gerthdala/webcrawler/internal/infrastructure/crawler"
+	"github.com/gerthdala/webcrawler/internal/infrastructure/ml"
+	contentdb "github.com/gerthdala/webcrawler/internal/infrastructure/persistence/postgres/content"
+	crawlerdb "github.com/gerthdala/webcrawler/internal/infrastructure/persistence/postgres/crawler"
+	watchdb "github.com/gerthdala/webcrawler/internal/infrastructure/persistence/postgres/watch"
+	"github.com/gerthdala/webcrawler/internal/interfaces/cli"
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+	"gorm.io/gorm"
+)
 
 func main() {
-	fmt.Println("Hello Gertudes")
-}
\ No newline at end of file
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run wires the services to the database and runs the command named by args
+func run(ctx context.Context, args []string, out io.Writer) error {
+	dbResult := crawlerdb.NewDB(crawlerdb.DBConfig{
+		Host:         env("WEBCRAWLER_DB_HOST", "localhost"),
+		Port:         envInt("WEBCRAWLER_DB_PORT", 5432),
+		User:         env("WEBCRAWLER_DB_USER", "postgres"),
+		Password:     env("WEBCRAWLER_DB_PASSWORD", "postgres"),
+		Database:     env("WEBCRAWLER_DB_NAME", "webcrawler"),
+		SSLMode:      env("WEBCRAWLER_DB_SSLMODE", "disable"),
+		MaxOpenConns: 10,
+		MaxIdleConns: 5,
+		MaxLifetime:  time.Hour,
+	})
+	if dbResult.IsErr() {
+		return dbResult.Error()
+	}
+	db := dbResult.Unwrap()
+
+	if err := autoMigrate(db); err != nil {
+		return err
+	}
+
+	crawlService, err := newCrawlService(db)
+	if err != nil {
+		return err
+	}
+	reanalysisService := newReanalysisService(db)
+
+	app := cli.New(out,
+		cli.NewExplainCommand(crawlService, out),
+		cli.NewStatsCommand(crawlService, out),
+		cli.NewReanalyzeCommand(reanalysisService, out),
+	)
+	return app.Run(ctx, args)
+}
+
+// autoMigrate creates or updates the tables of every store, with their search vectors
+func autoMigrate(db *gorm.DB) error {
+	migrations := []struct {
+		store   string
+		migrate func(*gorm.DB) result.Result[bool]
+	}{
+		{"crawler", crawlerdb.Migrate},
+		{"content", contentdb.Migrate},
+		{"watch", watchdb.Migrate},
+	}
+	for _, migration := range migrations {
+		if migrateResult := migration.migrate(db); migrateResult.IsErr() {
+			return fmt.Errorf("failed to migrate %s store: %w", migration.store, migrateResult.Error())
+		}
+	}
+	return nil
+}
+
+// newCrawlService creates the crawl service, filtering URLs as configured by the environment
+func newCrawlService(db *gorm.DB) (*crawler.CrawlService, error) {
+	userAgent := env("WEBCRAWLER_USER_AGENT", "WebCrawler/1.0")
+
+	filterResult := crawlerinfra.NewURLFilter(crawlerinfra.URLFilterConfig{
+		AllowedDomains:      envList("WEBCRAWLER_ALLOWED_DOMAINS", nil),
+		DomainScope:         crawlerinfra.DomainScope(env("WEBCRAWLER_DOMAIN_SCOPE", "")),
+		MaxOffDomainHops:    envInt("WEBCRAWLER_MAX_OFF_DOMAIN_HOPS", 0),
+		AllowedExtensions:   envList("WEBCRAWLER_ALLOWED_EXTENSIONS", []string{"html", "htm", "php", "asp", "aspx", "jsp"}),
+		DisallowedPaths:     envList("WEBCRAWLER_DISALLOWED_PATHS", []string{"/admin", "/login", "/logout", "/register", "/cart", "/checkout"}),
+		AllowedContentTypes: envList("WEBCRAWLER_ALLOWED_CONTENT_TYPES", []string{"text/html", "application/xhtml+xml"}),
+		MaxURLLength:        envInt("WEBCRAWLER_MAX_URL_LENGTH", 2048),
+	})
+	if filterResult.IsErr() {
+		return nil, fmt.Errorf("failed to create URL filter: %w", filterResult.Error())
+	}
+	filter := filterResult.Unwrap()
+
+	urlRepo := crawlerdb.NewURLRepository(db)
+	crawlJobRepo := crawlerdb.NewCrawlJobRepository(db)
+
+	return crawler.NewCrawlService(
+		urlRepo,
+		crawlerdb.NewPageRepository(db),
+		crawlJobRepo,
+		crawlerinfra.NewHTTPFetcher(filter, crawlerinfra.HTTPFetcherConfig{
+			UserAgent:       userAgent,
+			Timout:          30 * time.Second,
+			MaxRedirects:    5,
+			FollowRedirects: true,
+		}),
+		crawlerinfra.NewHTMLParser(),
+		filter,
+		nil,
+		crawlerinfra.NewSitemapReader(crawlerinfra.SitemapReaderConfig{UserAgent: userAgent, Timeout: 30 * time.Second}),
+		nil,
+		crawler.NewRecrawlService(urlRepo, crawlJobRepo, crawler.RecrawlServiceConfig{}),
+		nil,
+		crawlerdb.NewFetchErrorRepository(db),
+		crawler.CrawlServiceConfig{
+			MaxDepth:    envInt("WEBCRAWLER_MAX_DEPTH", 3),
+			Concurrency: envInt("WEBCRAWLER_CONCURRENCY", 10),
+			UserAgent:   userAgent,
+		},
+	), nil
+}
+
+// newReanalysisService creates the reanalysis service, running the steps whose analyzers are available
+func newReanalysisService(db *gorm.DB) *analysis.ReanalysisService {
+	analysisService := analysis.NewAnalysisService(
+		ml.NewTextVectorizer(ml.TextVectorizerConfig{}),
+		nil,
+		ml.NewNamedEntityRecognizer(),
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		ml.NewSimilarityCalculator(),
+		ml.NewTextChunker(ml.TextChunkerConfig{}),
+		analysis.AnalysisServiceConfig{
+			EnabledSteps: []content.AnalysisStep{
+				content.AnalysisStepEmbedding,
+				content.AnalysisStepEntities,
+				content.AnalysisStepChunks,
+			},
+			StepTimeout: time.Minute,
+		},
+	)
+	return analysis.NewReanalysisService(analysisService, contentdb.NewContentRepository(db))
+}
+
+// env returns the value of an environment variable, or fallback when it is not set
+func env(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
+// envInt returns the integer value of an environment variable, or fallback when it is not set or invalid
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(env(key, ""))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// envList returns the comma-separated values of an environment variable, or fallback when it is not set
+func envList(key string, fallback []string) []string {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	values := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
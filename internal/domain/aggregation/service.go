@@ -0,0 +1,181 @@
+package aggregation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gerthdala/webcrawler/internal/domain/content"
+	"github.com/gerthdala/webcrawler/internal/domain/search/query"
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+)
+
+// ErrInvalidRequest is returned when an aggregation request names an unknown facet, interval or field
+var ErrInvalidRequest = errors.New("invalid aggregation request")
+
+// AggregationRequest selects the Content to aggregate and the aggregations to compute
+type AggregationRequest struct {
+	// Query is an optional search query in the query language of query.Parse
+	Query  string
+	Filter content.SearchFilter
+	// Facets to count, all facets when empty
+	Facets []content.Facet
+	// FacetLimit is the number of most frequent values returned per facet
+	FacetLimit int
+	// DateInterval is the bucket width of the crawl date facet, a day by default
+	DateInterval content.DateInterval
+	// Histograms maps each numeric field to bucket to a bucket width, 0 for the configured default.
+	// Every field is bucketed when nil.
+	Histograms map[content.HistogramField]float64
+}
+
+// Aggregation holds the counts of the Content matching a request
+type Aggregation struct {
+	Total      int
+	Facets     map[content.Facet][]content.FacetBucket
+	Histograms map[content.HistogramField][]content.HistogramBucket
+}
+
+// AggregationService counts Content by facet and histogram for dashboards
+type AggregationService struct {
+	contentRepo     content.ContentRepository
+	facetLimit      int
+	histogramWidths map[content.HistogramField]float64
+}
+
+// AggregationServiceConfig configuration for the aggregation service
+type AggregationServiceConfig struct {
+	// FacetLimit is the default number of values returned per facet, 10 by default
+	FacetLimit int
+	// HistogramWidths are the default bucket widths, 10 for readability scores and 250 for word counts
+	HistogramWidths map[content.HistogramField]float64
+}
+
+// NewAggregationService creates a new AggregationService
+func NewAggregationService(contentRepo content.ContentRepository, config AggregationServiceConfig) *AggregationService {
+	facetLimit := config.FacetLimit
+	if facetLimit <= 0 {
+		facetLimit = 10
+	}
+
+	widths := map[content.HistogramField]float64{
+		content.HistogramReadabilityScore: 10,
+		content.HistogramWordCount:        250,
+	}
+	for field, width := range config.HistogramWidths {
+		if width > 0 {
+			widths[field] = width
+		}
+	}
+
+	return &AggregationService{
+		contentRepo:     contentRepo,
+		facetLimit:      facetLimit,
+		histogramWidths: widths,
+	}
+}
+
+// Aggregate counts the Content matching the request filter and query by facet and histogram.
+// Malformed queries return a *query.ParseError, unknown facets, intervals and fields ErrInvalidRequest.
+func (s *AggregationService) Aggregate(ctx context.Context, req AggregationRequest) result.Result[Aggregation] {
+	filter := req.Filter
+	if strings.TrimSpace(req.Query) != "" {
+		parsed, err := query.Parse(req.Query)
+		if err != nil {
+			return result.Err[Aggregation](err)
+		}
+		filter.Query = parsed
+	}
+
+	facets := req.Facets
+	if len(facets) == 0 {
+		facets = content.AllFacets
+	}
+	facetLimit := req.FacetLimit
+	if facetLimit <= 0 {
+		facetLimit = s.facetLimit
+	}
+	interval := req.DateInterval
+	if interval == "" {
+		interval = content.DateIntervalDay
+	}
+
+	histograms := req.Histograms
+	if histograms == nil {
+		histograms = make(map[content.HistogramField]float64, len(s.histogramWidths))
+		for field := range s.histogramWidths {
+			histograms[field] = 0
+		}
+	}
+
+	if err := s.validate(facets, interval, histograms); err != nil {
+		return result.Err[Aggregation](err)
+	}
+
+	totalResult := s.contentRepo.CountMatching(ctx, filter)
+	if totalResult.IsErr() {
+		return result.Err[Aggregation](fmt.Errorf("failed to count content: %w", totalResult.Error()))
+	}
+
+	agg := Aggregation{
+		Total:      totalResult.Unwrap(),
+		Facets:     make(map[content.Facet][]content.FacetBucket, len(facets)),
+		Histograms: make(map[content.HistogramField][]content.HistogramBucket, len(histograms)),
+	}
+
+	for _, facet := range facets {
+		var bucketsResult result.Result[[]content.FacetBucket]
+		if facet == content.FacetCrawlDate {
+			bucketsResult = s.contentRepo.CountByDate(ctx, interval, filter)
+		} else {
+			bucketsResult = s.contentRepo.CountByFacet(ctx, facet, filter, facetLimit)
+		}
+		if bucketsResult.IsErr() {
+			return result.Err[Aggregation](fmt.Errorf("failed to count content by %s: %w", facet, bucketsResult.Error()))
+		}
+		agg.Facets[facet] = bucketsResult.Unwrap()
+	}
+
+	for field, width := range histograms {
+		if width <= 0 {
+			width = s.histogramWidths[field]
+		}
+		bucketsResult := s.contentRepo.Histogram(ctx, field, width, filter)
+		if bucketsResult.IsErr() {
+			return result.Err[Aggregation](fmt.Errorf("failed to compute %s histogram: %w", field, bucketsResult.Error()))
+		}
+		agg.Histograms[field] = bucketsResult.Unwrap()
+	}
+
+	return result.Ok(agg)
+}
+
+func (s *AggregationService) validate(facets []content.Facet, interval content.DateInterval, histograms map[content.HistogramField]float64) error {
+	known := make(map[content.Facet]bool, len(content.AllFacets))
+	for _, facet := range content.AllFacets {
+		known[facet] = true
+	}
+	for _, facet := range facets {
+		if !known[facet] {
+			return fmt.Errorf("%w: unknown facet %q", ErrInvalidRequest, facet)
+		}
+	}
+
+	switch interval {
+	case content.DateIntervalDay, content.DateIntervalWeek, content.DateIntervalMonth:
+	default:
+		return fmt.Errorf("%w: unknown date interval %q", ErrInvalidRequest, interval)
+	}
+
+	for field, width := range histograms {
+		if _, ok := s.histogramWidths[field]; !ok {
+			return fmt.Errorf("%w: unknown histogram field %q", ErrInvalidRequest, field)
+		}
+		if width < 0 {
+			return fmt.Errorf("%w: negative width for %s", ErrInvalidRequest, field)
+		}
+	}
+
+	return nil
+}
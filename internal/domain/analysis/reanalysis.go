@@ -0,0 +1,148 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gerthdala/webcrawler/internal/domain/content"
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+	"github.com/google/uuid"
+)
+
+// ReanalysisOptions selects the Content to re-analyse and the steps to re-run
+type ReanalysisOptions struct {
+	// Filter restricts the Content to re-analyse
+	Filter content.ReanalysisFilter
+	// Steps forces the given steps to re-run on every matching Content.
+	// When empty, only the steps analysed with an outdated model version are re-run.
+	Steps []content.AnalysisStep
+	// BatchSize is the number of Content loaded per batch
+	BatchSize int
+}
+
+// ReanalysisProgress reports the progress of a re-analysis run
+type ReanalysisProgress struct {
+	Scanned int
+	Updated int
+	Failed  int
+	// LastID is the ID of the last scanned Content, pass it as Filter.AfterID to resume
+	LastID uuid.UUID
+	Done   bool
+}
+
+// ReanalysisService re-runs analysis steps on stored Content after models change
+type ReanalysisService struct {
+	analysis    *AnalysisService
+	contentRepo content.ContentRepository
+}
+
+// NewReanalysisService creates a new ReanalysisService
+func NewReanalysisService(analysis *AnalysisService, contentRepo content.ContentRepository) *ReanalysisService {
+	return &ReanalysisService{
+		analysis:    analysis,
+		contentRepo: contentRepo,
+	}
+}
+
+// EnabledSteps returns the steps the analysis service can re-run
+func (s *ReanalysisService) EnabledSteps() []content.AnalysisStep {
+	return s.analysis.EnabledSteps()
+}
+
+// RunBatch re-analyses a single batch of Content and returns the progress of the batch
+func (s *ReanalysisService) RunBatch(ctx context.Context, opts ReanalysisOptions) result.Result[ReanalysisProgress] {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	current := s.analysis.ModelVersions()
+	filter := opts.Filter
+	if len(opts.Steps) == 0 {
+		filter.StaleVersions = current
+	}
+
+	findResult := s.contentRepo.FindForReanalysis(ctx, filter, batchSize)
+	if findResult.IsErr() {
+		return result.Err[ReanalysisProgress](fmt.Errorf("failed to find content for reanalysis: %w", findResult.Error()))
+	}
+
+	contents := findResult.Unwrap()
+	progress := ReanalysisProgress{
+		LastID: filter.AfterID,
+		Done:   len(contents) < batchSize,
+	}
+
+	for i := range contents {
+		c := &contents[i]
+		progress.Scanned++
+		progress.LastID = c.ID
+
+		steps := opts.Steps
+		if len(steps) == 0 {
+			steps = c.StaleSteps(current)
+		}
+		if len(steps) == 0 {
+			continue
+		}
+
+		// Entities and topics are not loaded with the batch, reload the full Content before updating it
+		fullResult := s.contentRepo.FindByID(ctx, c.ID)
+		if fullResult.IsErr() {
+			progress.Failed++
+			continue
+		}
+
+		analysed := s.analysis.AnalyseSteps(ctx, fullResult.Unwrap(), steps)
+		if analysed.IsErr() {
+			progress.Failed++
+			continue
+		}
+
+		if updateResult := s.contentRepo.Update(ctx, analysed.Unwrap()); updateResult.IsErr() {
+			progress.Failed++
+			continue
+		}
+		progress.Updated++
+	}
+
+	return result.Ok(progress)
+}
+
+// Run re-analyses matching Content batch by batch until no Content is left.
+// checkpoint is called after every batch with the cumulative progress; returning an
+// error from it stops the run, which can later be resumed from progress.LastID.
+func (s *ReanalysisService) Run(
+	ctx context.Context,
+	opts ReanalysisOptions,
+	checkpoint func(progress ReanalysisProgress) error,
+) result.Result[ReanalysisProgress] {
+	total := ReanalysisProgress{LastID: opts.Filter.AfterID}
+
+	for !total.Done {
+		if err := ctx.Err(); err != nil {
+			return result.Err[ReanalysisProgress](err)
+		}
+
+		opts.Filter.AfterID = total.LastID
+		batchResult := s.RunBatch(ctx, opts)
+		if batchResult.IsErr() {
+			return batchResult
+		}
+
+		batch := batchResult.Unwrap()
+		total.Scanned += batch.Scanned
+		total.Updated += batch.Updated
+		total.Failed += batch.Failed
+		total.LastID = batch.LastID
+		total.Done = batch.Done
+
+		if checkpoint != nil {
+			if err := checkpoint(total); err != nil {
+				return result.Err[ReanalysisProgress](fmt.Errorf("checkpoint failed: %w", err))
+			}
+		}
+	}
+
+	return result.Ok(total)
+}
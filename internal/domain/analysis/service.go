@@ -2,11 +2,20 @@ package analysis
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gerthdala/webcrawler/internal/domain/content"
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
 	result "github.com/gerthdala/webcrawler/pkg/utils/result"
 )
 
+// ErrDuplicatePage is returned when analysing a page that mirrors an already crawled canonical page
+var ErrDuplicatePage = errors.New("page is a duplicate")
+
 // TextVectorizer generates vector embeddings for text
 type TextVectorizer interface {
 	// Vectorize generates a vector embedding for text
@@ -70,6 +79,12 @@ type ReadabilityAnalyzer interface {
 	CountSentences(ctx context.Context, text string) result.Result[int]
 }
 
+// TextChunker splits text into overlapping passages
+type TextChunker interface {
+	// Chunk splits text into chunks with their character offsets in text
+	Chunk(ctx context.Context, text string) result.Result[[]content.Chunk]
+}
+
 // SimilarityCalculator calculates similarity between content
 type SimilarityCalculator interface {
 	// CalculateSimilarity calculates similarity between two pieces of content
@@ -79,6 +94,12 @@ type SimilarityCalculator interface {
 	FindMostSimilar(ctx context.Context, embedding []float32, embeddings [][]float32, limit int) result.Result[[]int]
 }
 
+// Versioned is implemented by analyzers that report the version of their underlying model
+type Versioned interface {
+	// ModelVersion returns the version of the model, e.g. a training run or release tag
+	ModelVersion() string
+}
+
 // AnalysisService orchestrates content analysis
 type AnalysisService struct {
 	vectorizer      TextVectorizer
@@ -90,6 +111,23 @@ type AnalysisService struct {
 	languageDetector LanguageDetector
 	readabilityAnalyzer ReadabilityAnalyzer
 	similarityCalculator SimilarityCalculator
+	chunker         TextChunker
+	enabledSteps    []content.AnalysisStep
+	stepTimeout     time.Duration
+	stepTimeouts    map[content.AnalysisStep]time.Duration
+	modelVersions   map[content.AnalysisStep]string
+}
+
+// AnalysisServiceConfig configuration for the analysis service
+type AnalysisServiceConfig struct {
+	// EnabledSteps lists the steps to run, all steps run when empty
+	EnabledSteps []content.AnalysisStep
+	// StepTimeout bounds the duration of each step, zero means no timeout
+	StepTimeout time.Duration
+	// StepTimeouts overrides StepTimeout for individual steps
+	StepTimeouts map[content.AnalysisStep]time.Duration
+	// ModelVersions overrides the model version reported by the analyzer of a step
+	ModelVersions map[content.AnalysisStep]string
 }
 
 // NewAnalysisService creates a new AnalysisService
@@ -103,7 +141,14 @@ func NewAnalysisService(
 	languageDetector LanguageDetector,
 	readabilityAnalyzer ReadabilityAnalyzer,
 	similarityCalculator SimilarityCalculator,
+	chunker TextChunker,
+	config AnalysisServiceConfig,
 ) *AnalysisService {
+	enabledSteps := config.EnabledSteps
+	if len(enabledSteps) == 0 {
+		enabledSteps = content.AllAnalysisSteps
+	}
+
 	return &AnalysisService{
 		vectorizer:      vectorizer,
 		topicModeler:    topicModeler,
@@ -114,73 +159,292 @@ func NewAnalysisService(
 		languageDetector: languageDetector,
 		readabilityAnalyzer: readabilityAnalyzer,
 		similarityCalculator: similarityCalculator,
+		chunker:         chunker,
+		enabledSteps:    enabledSteps,
+		stepTimeout:     config.StepTimeout,
+		stepTimeouts:    config.StepTimeouts,
+		modelVersions:   config.ModelVersions,
+	}
+}
+
+// stepFunc runs an analysis step and returns a function applying its output to the content
+type stepFunc func(ctx context.Context, text string) (func(c *content.Content), error)
+
+// stepOutcome is the outcome of a single step run
+type stepOutcome struct {
+	apply  func(c *content.Content)
+	report content.StepReport
+}
+
+// EnabledSteps returns the steps run by AnalyseContent
+func (s *AnalysisService) EnabledSteps() []content.AnalysisStep {
+	return s.enabledSteps
+}
+
+// ModelVersions returns the current model version of every enabled step
+func (s *AnalysisService) ModelVersions() map[content.AnalysisStep]string {
+	versions := make(map[content.AnalysisStep]string, len(s.enabledSteps))
+	for _, step := range s.enabledSteps {
+		versions[step] = s.modelVersion(step)
 	}
+	return versions
 }
 
-// AnalyseContent performs full analysis on content
+// modelVersion returns the current model version of a step
+func (s *AnalysisService) modelVersion(step content.AnalysisStep) string {
+	if version, ok := s.modelVersions[step]; ok {
+		return version
+	}
+
+	var analyzer interface{}
+	switch step {
+	case content.AnalysisStepEmbedding:
+		analyzer = s.vectorizer
+	case content.AnalysisStepTopics:
+		analyzer = s.topicModeler
+	case content.AnalysisStepEntities:
+		analyzer = s.entityRecognizer
+	case content.AnalysisStepClassification:
+		analyzer = s.classifier
+	case content.AnalysisStepSummary:
+		analyzer = s.summarizer
+	case content.AnalysisStepKeywords:
+		analyzer = s.keywordExtractor
+	case content.AnalysisStepLanguage:
+		analyzer = s.languageDetector
+	case content.AnalysisStepReadability, content.AnalysisStepWordCount, content.AnalysisStepSentenceCount:
+		analyzer = s.readabilityAnalyzer
+	case content.AnalysisStepChunks:
+		// Chunk embeddings depend on both the chunker and the vectorizer
+		versions := make([]string, 0, 2)
+		for _, component := range []interface{}{s.chunker, s.vectorizer} {
+			if versioned, ok := component.(Versioned); ok && versioned.ModelVersion() != "" {
+				versions = append(versions, versioned.ModelVersion())
+			}
+		}
+		return strings.Join(versions, "/")
+	}
+
+	if versioned, ok := analyzer.(Versioned); ok {
+		return versioned.ModelVersion()
+	}
+	return ""
+}
+
+// AnalyseContent performs full analysis on content.
+// Enabled steps run concurrently, each bounded by its timeout. Step failures do not
+// fail the analysis; they are recorded in the AnalysisReport attached to the content.
 func (s *AnalysisService) AnalyseContent(ctx context.Context, c *content.Content) result.Result[*content.Content] {
-	// Extrat embedding
-	embeddingResult := s.vectorizer.Vectorize(ctx, c.Text)
-	if embeddingResult.IsOk() {
-		c.SetVectorEmbedding(embeddingResult.Unwrap())
+	return s.AnalyseSteps(ctx, c, s.enabledSteps)
+}
+
+// AnalysePage creates Content from a crawled page, with the metadata of the feed item
+// it was discovered from, and performs full analysis on it.
+// Duplicate pages are not analysed, ErrDuplicatePage is returned instead.
+func (s *AnalysisService) AnalysePage(ctx context.Context, page *crawler.Page) result.Result[*content.Content] {
+	if page.IsDuplicate() {
+		return result.Err[*content.Content](fmt.Errorf("%w: %s mirrors %s", ErrDuplicatePage, page.URL, page.DuplicateOf))
 	}
+	return s.AnalyseContent(ctx, content.NewContentFromPage(page))
+}
+
+// AnalyseSteps runs only the given steps on content, e.g. to refresh the output of retrained models.
+// The reports of the steps are merged into the existing AnalysisReport of the content.
+func (s *AnalysisService) AnalyseSteps(ctx context.Context, c *content.Content, steps []content.AnalysisStep) result.Result[*content.Content] {
+	startedAt := time.Now()
+	funcs := s.stepFuncs()
 
-	// Extract topics
-	topicsResult := s.topicModeler.ExtractTopics(ctx, c.Text, 5)
-	if topicsResult.IsOk() {
-		c.AddTopics(topicsResult.Unwrap())
+	outcomes := make([]stepOutcome, len(steps))
+	var wg sync.WaitGroup
+	for i, step := range steps {
+		wg.Add(1)
+		go func(i int, step content.AnalysisStep) {
+			defer wg.Done()
+			outcomes[i] = s.runStep(ctx, step, funcs[step], c.Text)
+		}(i, step)
 	}
+	wg.Wait()
 
-	// Extract named entities
-	entitiesResult := s.entityRecognizer.ExtractEntities(ctx, c.Text)
-	if entitiesResult.IsOk() {
-		c.AddNamedEntities(entitiesResult.Unwrap())
+	// Apply outputs sequentially, content setters are not safe for concurrent use
+	reports := make([]content.StepReport, len(outcomes))
+	for i, outcome := range outcomes {
+		if outcome.apply != nil {
+			outcome.apply(c)
+			c.SetModelVersion(outcome.report.Step, outcome.report.ModelVersion)
+		}
+		reports[i] = outcome.report
 	}
 
-	// Classify content
-	classificationResult := s.classifier.Classify(ctx, c.Text)
-	if classificationResult.IsOk() {
-		c.SetClassification(classificationResult.Unwrap())
+	c.SetAnalysisReport(c.AnalysisReport.Merge(content.NewAnalysisReport(startedAt, reports)))
+
+	return result.Ok(c)
+}
+
+// runStep runs a single step within its timeout and reports its outcome
+func (s *AnalysisService) runStep(ctx context.Context, step content.AnalysisStep, fn stepFunc, text string) stepOutcome {
+	if timeout := s.timeoutFor(step); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
 
-	// Summarize content
-	summaryResult := s.summarizer.Summarize(ctx, c.Text, 200)
-	if summaryResult.IsOk() {
-		c.SetSummary(summaryResult.Unwrap())
+	type stepResult struct {
+		apply func(c *content.Content)
+		err   error
 	}
 
-	// Extract keywords
-	keywordsResult := s.keywordExtractor.ExtractKeywords(ctx, c.Text, 10)
-	if keywordsResult.IsOk() {
-		c.SetKeywords(keywordsResult.Unwrap())
+	start := time.Now()
+	done := make(chan stepResult, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- stepResult{err: fmt.Errorf("step panicked: %v", r)}
+			}
+		}()
+
+		if fn == nil {
+			done <- stepResult{err: fmt.Errorf("unknown analysis step: %s", step)}
+			return
+		}
+		apply, err := fn(ctx, text)
+		done <- stepResult{apply: apply, err: err}
+	}()
+
+	// A step that ignores its context is abandoned once the timeout expires
+	var res stepResult
+	select {
+	case res = <-done:
+	case <-ctx.Done():
+		res = stepResult{err: ctx.Err()}
 	}
 
-	// Detect language
-	languageResult := s.languageDetector.DetectLanguage(ctx, c.Text)
-	if languageResult.IsOk() {
-		c.SetLanguage(languageResult.Unwrap())
+	report := content.StepReport{
+		Step:         step,
+		ModelVersion: s.modelVersion(step),
+		Duration:     time.Since(start),
+		Succeeded:    res.err == nil,
 	}
-	
-	// Analyze readability
-	readabilityResult := s.readabilityAnalyzer.AnalyzeReadability(ctx, c.Text)
-	if readabilityResult.IsOk() {
-		c.SetReadabilityScore(readabilityResult.Unwrap())
+	if res.err != nil {
+		report.Error = res.err.Error()
 	}
-	
-	// Count words
-	wordCountResult := s.readabilityAnalyzer.CountWords(ctx, c.Text)
-	if wordCountResult.IsOk() {
-		c.SetWordCount(wordCountResult.Unwrap())
+
+	return stepOutcome{apply: res.apply, report: report}
+}
+
+// timeoutFor returns the timeout of a step
+func (s *AnalysisService) timeoutFor(step content.AnalysisStep) time.Duration {
+	if timeout, ok := s.stepTimeouts[step]; ok {
+		return timeout
 	}
-	
-	// Count sentences
-	sentenceCountResult := s.readabilityAnalyzer.CountSentences(ctx, c.Text)
-	if sentenceCountResult.IsOk() {
-		c.SetSentenceCount(sentenceCountResult.Unwrap())
+	return s.stepTimeout
+}
+
+// stepFuncs maps every analysis step to the analyzer call implementing it
+func (s *AnalysisService) stepFuncs() map[content.AnalysisStep]stepFunc {
+	return map[content.AnalysisStep]stepFunc{
+		content.AnalysisStepEmbedding: func(ctx context.Context, text string) (func(*content.Content), error) {
+			if s.vectorizer == nil {
+				return nil, errNotConfigured("vectorizer")
+			}
+			return applyResult(s.vectorizer.Vectorize(ctx, text), (*content.Content).SetVectorEmbedding)
+		},
+		content.AnalysisStepTopics: func(ctx context.Context, text string) (func(*content.Content), error) {
+			if s.topicModeler == nil {
+				return nil, errNotConfigured("topic modeler")
+			}
+			return applyResult(s.topicModeler.ExtractTopics(ctx, text, 5), (*content.Content).AddTopics)
+		},
+		content.AnalysisStepEntities: func(ctx context.Context, text string) (func(*content.Content), error) {
+			if s.entityRecognizer == nil {
+				return nil, errNotConfigured("entity recognizer")
+			}
+			return applyResult(s.entityRecognizer.ExtractEntities(ctx, text), (*content.Content).AddNamedEntities)
+		},
+		content.AnalysisStepClassification: func(ctx context.Context, text string) (func(*content.Content), error) {
+			if s.classifier == nil {
+				return nil, errNotConfigured("classifier")
+			}
+			return applyResult(s.classifier.Classify(ctx, text), (*content.Content).SetClassification)
+		},
+		content.AnalysisStepSummary: func(ctx context.Context, text string) (func(*content.Content), error) {
+			if s.summarizer == nil {
+				return nil, errNotConfigured("summarizer")
+			}
+			return applyResult(s.summarizer.Summarize(ctx, text, 200), (*content.Content).SetSummary)
+		},
+		content.AnalysisStepKeywords: func(ctx context.Context, text string) (func(*content.Content), error) {
+			if s.keywordExtractor == nil {
+				return nil, errNotConfigured("keyword extractor")
+			}
+			return applyResult(s.keywordExtractor.ExtractKeywords(ctx, text, 10), (*content.Content).SetKeywords)
+		},
+		content.AnalysisStepLanguage: func(ctx context.Context, text string) (func(*content.Content), error) {
+			if s.languageDetector == nil {
+				return nil, errNotConfigured("language detector")
+			}
+			return applyResult(s.languageDetector.DetectLanguage(ctx, text), (*content.Content).SetLanguage)
+		},
+		content.AnalysisStepReadability: func(ctx context.Context, text string) (func(*content.Content), error) {
+			if s.readabilityAnalyzer == nil {
+				return nil, errNotConfigured("readability analyzer")
+			}
+			return applyResult(s.readabilityAnalyzer.AnalyzeReadability(ctx, text), (*content.Content).SetReadabilityScore)
+		},
+		content.AnalysisStepWordCount: func(ctx context.Context, text string) (func(*content.Content), error) {
+			if s.readabilityAnalyzer == nil {
+				return nil, errNotConfigured("readability analyzer")
+			}
+			return applyResult(s.readabilityAnalyzer.CountWords(ctx, text), (*content.Content).SetWordCount)
+		},
+		content.AnalysisStepSentenceCount: func(ctx context.Context, text string) (func(*content.Content), error) {
+			if s.readabilityAnalyzer == nil {
+				return nil, errNotConfigured("readability analyzer")
+			}
+			return applyResult(s.readabilityAnalyzer.CountSentences(ctx, text), (*content.Content).SetSentenceCount)
+		},
+		content.AnalysisStepChunks: func(ctx context.Context, text string) (func(*content.Content), error) {
+			if s.chunker == nil {
+				return nil, errNotConfigured("chunker")
+			}
+			if s.vectorizer == nil {
+				return nil, errNotConfigured("vectorizer")
+			}
+
+			chunksResult := s.chunker.Chunk(ctx, text)
+			if chunksResult.IsErr() {
+				return nil, chunksResult.Error()
+			}
+
+			chunks := chunksResult.Unwrap()
+			for i := range chunks {
+				embeddingResult := s.vectorizer.Vectorize(ctx, chunks[i].Text)
+				if embeddingResult.IsErr() {
+					return nil, fmt.Errorf("failed to vectorize chunk %d: %w", i, embeddingResult.Error())
+				}
+				chunks[i].VectorEmbedding = embeddingResult.Unwrap()
+			}
+
+			return func(c *content.Content) {
+				c.SetChunks(chunks)
+			}, nil
+		},
 	}
-	
-	return result.Ok(c)
+}
+
+// applyResult turns an analyzer result into a function applying its value to the content
+func applyResult[T any](r result.Result[T], set func(*content.Content, T)) (func(*content.Content), error) {
+	if r.IsErr() {
+		return nil, r.Error()
+	}
+
+	value := r.Unwrap()
+	return func(c *content.Content) {
+		set(c, value)
+	}, nil
+}
 
+func errNotConfigured(component string) error {
+	return fmt.Errorf("%s is not configured", component)
 }
 
 // FindSimilarContent finds content similar to the given content
@@ -3,6 +3,7 @@ package content
 import (
 	"time"
 
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
 	"github.com/google/uuid"
 )
 
@@ -34,6 +35,10 @@ type Content struct {
 	SentenceCount    int
 	VectorEmbedding  []float32
 	Topics           []Topic
+	Chunks           []Chunk
+	AnalysisReport   *AnalysisReport
+	ModelVersions    map[AnalysisStep]string
+	Feed             *FeedMetadata
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
 }
@@ -51,6 +56,16 @@ func NewContent(url, title, text, html string) *Content {
 	}
 }
 
+// NewContentFromPage creates a new Content entity from a crawled page, with the metadata
+// of the feed item the page was discovered from
+func NewContentFromPage(page *crawler.Page) *Content {
+	c := NewContent(page.URL, page.Title, page.PlainText, page.HTML)
+	if page.FeedItem != nil {
+		c.SetFeedMetadata(FeedMetadataFromItem(page.FeedItem))
+	}
+	return c
+}
+
 func (c *Content) AddNamedEntities(entities []NamedEntity) {
 
 	c.NamedEntities = entities
@@ -111,6 +126,164 @@ func (c *Content) SetSentenceCount(count int) {
 	c.UpdatedAt = time.Now()
 }
 
+// SetChunks sets the content chunks
+func (c *Content) SetChunks(chunks []Chunk) {
+	for i := range chunks {
+		chunks[i].ContentID = c.ID
+	}
+	c.Chunks = chunks
+	c.UpdatedAt = time.Now()
+}
+
+// SetFeedMetadata sets the metadata of the feed item the content was discovered from
+func (c *Content) SetFeedMetadata(feed *FeedMetadata) {
+	c.Feed = feed
+	c.UpdatedAt = time.Now()
+}
+
+// SetAnalysisReport sets the report of the last analysis run
+func (c *Content) SetAnalysisReport(report *AnalysisReport) {
+	c.AnalysisReport = report
+	c.UpdatedAt = time.Now()
+}
+
+// SetModelVersion records the model version that produced the output of an analysis step
+func (c *Content) SetModelVersion(step AnalysisStep, version string) {
+	if c.ModelVersions == nil {
+		c.ModelVersions = make(map[AnalysisStep]string)
+	}
+	c.ModelVersions[step] = version
+	c.UpdatedAt = time.Now()
+}
+
+// StaleSteps returns the steps whose recorded model version differs from the current one
+func (c *Content) StaleSteps(current map[AnalysisStep]string) []AnalysisStep {
+	stale := make([]AnalysisStep, 0)
+	for _, step := range AllAnalysisSteps {
+		version, ok := current[step]
+		if !ok {
+			continue
+		}
+		if recorded, analysed := c.ModelVersions[step]; !analysed || recorded != version {
+			stale = append(stale, step)
+		}
+	}
+	return stale
+}
+
+// FeedMetadata is the metadata of the feed item a Content was discovered from
+type FeedMetadata struct {
+	FeedURL    string
+	Published  time.Time
+	Author     string
+	Categories []string
+}
+
+// FeedMetadataFromItem returns the metadata of a feed item
+func FeedMetadataFromItem(item *crawler.FeedItem) *FeedMetadata {
+	return &FeedMetadata{
+		FeedURL:    item.FeedURL,
+		Published:  item.Published,
+		Author:     item.Author,
+		Categories: item.Categories,
+	}
+}
+
+// AnalysisStep identifies a single step of the content analysis pipeline
+type AnalysisStep string
+
+const (
+	AnalysisStepEmbedding      AnalysisStep = "embedding"
+	AnalysisStepTopics         AnalysisStep = "topics"
+	AnalysisStepEntities       AnalysisStep = "entities"
+	AnalysisStepClassification AnalysisStep = "classification"
+	AnalysisStepSummary        AnalysisStep = "summary"
+	AnalysisStepKeywords       AnalysisStep = "keywords"
+	AnalysisStepLanguage       AnalysisStep = "language"
+	AnalysisStepReadability    AnalysisStep = "readability"
+	AnalysisStepWordCount      AnalysisStep = "word_count"
+	AnalysisStepSentenceCount  AnalysisStep = "sentence_count"
+	AnalysisStepChunks         AnalysisStep = "chunks"
+)
+
+// AllAnalysisSteps lists every analysis step in pipeline order
+var AllAnalysisSteps = []AnalysisStep{
+	AnalysisStepEmbedding,
+	AnalysisStepTopics,
+	AnalysisStepEntities,
+	AnalysisStepClassification,
+	AnalysisStepSummary,
+	AnalysisStepKeywords,
+	AnalysisStepLanguage,
+	AnalysisStepReadability,
+	AnalysisStepWordCount,
+	AnalysisStepSentenceCount,
+	AnalysisStepChunks,
+}
+
+// StepReport records the outcome of a single analysis step
+type StepReport struct {
+	Step         AnalysisStep
+	ModelVersion string
+	Duration     time.Duration
+	Succeeded    bool
+	Error        string
+}
+
+// AnalysisReport records the outcome of every step of an analysis run
+type AnalysisReport struct {
+	Steps      []StepReport
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// NewAnalysisReport creates a new AnalysisReport
+func NewAnalysisReport(startedAt time.Time, steps []StepReport) *AnalysisReport {
+	return &AnalysisReport{
+		Steps:      steps,
+		StartedAt:  startedAt,
+		FinishedAt: time.Now(),
+	}
+}
+
+// Merge returns a report combining the steps of r with the steps of next,
+// steps present in both are taken from next
+func (r *AnalysisReport) Merge(next *AnalysisReport) *AnalysisReport {
+	if r == nil {
+		return next
+	}
+
+	rerun := make(map[AnalysisStep]bool, len(next.Steps))
+	for _, step := range next.Steps {
+		rerun[step.Step] = true
+	}
+
+	steps := make([]StepReport, 0, len(r.Steps)+len(next.Steps))
+	for _, step := range r.Steps {
+		if !rerun[step.Step] {
+			steps = append(steps, step)
+		}
+	}
+	steps = append(steps, next.Steps...)
+
+	return &AnalysisReport{
+		Steps:      steps,
+		StartedAt:  next.StartedAt,
+		FinishedAt: next.FinishedAt,
+	}
+}
+
+// Failed returns the reports of the steps that did not succeed
+func (r *AnalysisReport) Failed() []StepReport {
+	failed := make([]StepReport, 0)
+	for _, step := range r.Steps {
+		if !step.Succeeded {
+			failed = append(failed, step)
+		}
+	}
+	return failed
+}
+
 type EntityType string
 
 const (
@@ -160,6 +333,40 @@ func NewTopic(name string, keywords []string, confidence float64) Topic {
 	}
 }
 
+// Chunk is a passage of a Content's text with its own embedding
+type Chunk struct {
+	ID              uuid.UUID
+	ContentID       uuid.UUID
+	Index           int
+	Heading         string
+	Text            string
+	StartOffset     int // character offset of the chunk start in the content text
+	EndOffset       int // character offset of the chunk end in the content text
+	TokenCount      int
+	VectorEmbedding []float32
+}
+
+// NewChunk creates a new Chunk
+func NewChunk(index int, heading, text string, startOffset, endOffset, tokenCount int) Chunk {
+	return Chunk{
+		ID:          uuid.New(),
+		Index:       index,
+		Heading:     heading,
+		Text:        text,
+		StartOffset: startOffset,
+		EndOffset:   endOffset,
+		TokenCount:  tokenCount,
+	}
+}
+
+// ChunkMatch is a Chunk found by a nearest-neighbour search
+type ChunkMatch struct {
+	Chunk        Chunk
+	ContentURL   string
+	ContentTitle string
+	Distance     float64 // cosine distance between the chunk and the searched embedding
+}
+
 type SimilarContent struct {
 	ContentID       uuid.UUID
 	SimilarToID     uuid.UUID
@@ -2,19 +2,114 @@ package content
 
 import (
 	"context"
+	"time"
 
+	"github.com/gerthdala/webcrawler/internal/domain/search/query"
 	result "github.com/gerthdala/webcrawler/pkg/utils/result"
 	"github.com/google/uuid"
 )
 
+// ReanalysisFilter selects Content for re-analysis
+type ReanalysisFilter struct {
+	// StaleVersions matches Content where any listed step was analysed with another model version
+	StaleVersions map[AnalysisStep]string
+	ContentType   ContentType
+	Language      string
+	URLPrefix     string
+	CreatedBefore time.Time
+	// AfterID resumes a scan after the Content with this ID, Content is scanned in ID order
+	AfterID uuid.UUID
+}
+
+// SearchFilter restricts a Content search
+type SearchFilter struct {
+	Domain        string
+	Language      string
+	ContentType   ContentType
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// Query is a parsed search query that Content must match, nil matches all Content
+	Query query.Node
+}
+
+// ScoredContent is a Content ranked by a search
+type ScoredContent struct {
+	Content Content
+	Score   float64
+}
+
+// ScoredID is the ID of a Content ranked by a search
+type ScoredID struct {
+	ID    uuid.UUID
+	Score float64
+}
+
+// Facet is a field Content is counted by
+type Facet string
+
+const (
+	FacetClassification Facet = "classification"
+	FacetLanguage       Facet = "language"
+	FacetDomain         Facet = "domain"
+	FacetEntityType     Facet = "entity_type"
+	FacetTopic          Facet = "topic"
+	FacetCrawlDate      Facet = "crawl_date"
+)
+
+// AllFacets lists every Facet
+var AllFacets = []Facet{
+	FacetClassification,
+	FacetLanguage,
+	FacetDomain,
+	FacetEntityType,
+	FacetTopic,
+	FacetCrawlDate,
+}
+
+// DateInterval is the width of the buckets of the crawl date facet
+type DateInterval string
+
+const (
+	DateIntervalDay   DateInterval = "day"
+	DateIntervalWeek  DateInterval = "week"
+	DateIntervalMonth DateInterval = "month"
+)
+
+// FacetBucket counts the Content having a facet value
+type FacetBucket struct {
+	Value string
+	Count int
+}
+
+// HistogramField is a numeric field Content is bucketed by
+type HistogramField string
+
+const (
+	HistogramReadabilityScore HistogramField = "readability_score"
+	HistogramWordCount        HistogramField = "word_count"
+)
+
+// HistogramBucket counts the Content with a field value in [From, To)
+type HistogramBucket struct {
+	From  float64
+	To    float64
+	Count int
+}
+
 // ContentRepository handles Content storage and retrieval
 type ContentRepository interface {
 	// Save stores a Content
 	Save(ctx context.Context, content *Content) result.Result[*Content]
 
+	// Update updates a stored Content, replacing its entities, topics and chunks
+	Update(ctx context.Context, content *Content) result.Result[*Content]
+
 	// FindByID finds a Content by its ID
 	FindByID(ctx context.Context, id uuid.UUID) result.Result[*Content]
 
+	// FindByIDs finds the stored Content among the given IDs with their entities and topics, in no particular order
+	FindByIDs(ctx context.Context, ids []uuid.UUID) result.Result[[]Content]
+
 	// FindByURL finds a Content by its URL
 	FindByURL(ctx context.Context, url string) result.Result[*Content]
 
@@ -36,9 +131,42 @@ type ContentRepository interface {
 	// Search searches Content by text
 	Search(ctx context.Context, query string, limit int) result.Result[[]Content]
 
+	// KeywordSearch searches Content matching the filter by text, ordered by text rank.
+	// When the filter has a Query, the Query selects the Content and the text only ranks it.
+	// An empty text matches all Content matching the filter, ordered by creation date.
+	KeywordSearch(ctx context.Context, query string, filter SearchFilter, limit int) result.Result[[]ScoredContent]
+
+	// VectorSearch finds Content matching the filter nearest to the given vector embedding, ordered by similarity
+	VectorSearch(ctx context.Context, embedding []float32, filter SearchFilter, limit int) result.Result[[]ScoredContent]
+
+	// KeywordRanking ranks Content as KeywordSearch does, without loading the Content
+	KeywordRanking(ctx context.Context, query string, filter SearchFilter, limit int) result.Result[[]ScoredID]
+
+	// VectorRanking ranks Content as VectorSearch does, without loading the Content
+	VectorRanking(ctx context.Context, embedding []float32, filter SearchFilter, limit int) result.Result[[]ScoredID]
+
 	// CountByContentType counts Content by content type
 	CountByContentType(ctx context.Context, contentType ContentType) result.Result[int]
 
+	// CountMatching counts Content matching the filter
+	CountMatching(ctx context.Context, filter SearchFilter) result.Result[int]
+
+	// CountByFacet counts Content matching the filter by facet value, for the limit most frequent values.
+	// Content is counted once per distinct entity type or topic it has.
+	CountByFacet(ctx context.Context, facet Facet, filter SearchFilter, limit int) result.Result[[]FacetBucket]
+
+	// CountByDate counts Content matching the filter by crawl date, in chronological order
+	CountByDate(ctx context.Context, interval DateInterval, filter SearchFilter) result.Result[[]FacetBucket]
+
+	// Histogram counts Content matching the filter in buckets of the given width of a numeric field
+	Histogram(ctx context.Context, field HistogramField, width float64, filter SearchFilter) result.Result[[]HistogramBucket]
+
+	// FindForReanalysis finds Content matching the filter, ordered by ID
+	FindForReanalysis(ctx context.Context, filter ReanalysisFilter, limit int) result.Result[[]Content]
+
+	// FindIDsOlderThan finds the IDs of the Content DeleteOlderThan deletes
+	FindIDsOlderThan(ctx context.Context, days int) result.Result[[]uuid.UUID]
+
 	// DeleteOlderThan deletes Content older than the given duration
 	DeleteOlderThan(ctx context.Context, days int) result.Result[int]
 }
@@ -85,6 +213,29 @@ type TopicRepository interface {
 	FindMostPopular(ctx context.Context, limit int) result.Result[[]Topic]
 }
 
+// ChunkFilter restricts a Chunk search to the chunks of matching Content
+type ChunkFilter struct {
+	Domain      string
+	Language    string
+	ContentType ContentType
+	URLPrefix   string
+}
+
+// ChunkRepository handles Chunk storage and retrieval
+type ChunkRepository interface {
+	// SaveAll replaces the Chunks of a Content
+	SaveAll(ctx context.Context, contentID uuid.UUID, chunks []Chunk) result.Result[[]Chunk]
+
+	// FindByContentID finds the Chunks of a Content, ordered by index
+	FindByContentID(ctx context.Context, contentID uuid.UUID) result.Result[[]Chunk]
+
+	// FindNearest finds the Chunks of Content matching the filter nearest to the given vector embedding
+	FindNearest(ctx context.Context, embedding []float32, filter ChunkFilter, limit int) result.Result[[]ChunkMatch]
+
+	// DeleteByContentID deletes the Chunks of a Content
+	DeleteByContentID(ctx context.Context, contentID uuid.UUID) result.Result[int]
+}
+
 // SimilarContentRepository handles SimilarContent storage and retrieval
 type SimilarContentRepository interface {
 	// Save stores a SimilarContent
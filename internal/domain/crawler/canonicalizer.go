@@ -0,0 +1,425 @@
+package crawler
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"golang.org/x/net/idna"
+)
+
+// DefaultTrackingParams are the query parameters removed from every URL by default
+var DefaultTrackingParams = []string{
+	"utm_*", "fbclid", "gclid", "gclsrc", "dclid", "msclkid", "yclid", "igshid",
+	"mc_cid", "mc_eid", "_ga", "_gl", "_hsenc", "_hsmi", "mkt_tok", "oly_anon_id", "oly_enc_id",
+}
+
+// DefaultIndexPages are the directory index file names removed from paths by default
+var DefaultIndexPages = []string{
+	"index.html", "index.htm", "index.shtml", "index.php", "index.asp", "index.aspx", "index.jsp",
+	"default.asp", "default.aspx", "default.htm", "default.html",
+}
+
+// CanonicalizerConfig configuration for the URL canonicalizer
+type CanonicalizerConfig struct {
+	// TrackingParams are the query parameters removed from every URL, matched case-insensitively,
+	// a trailing * matching any suffix. DefaultTrackingParams when nil.
+	TrackingParams []string
+	// AllowedParams maps a domain to the only query parameters kept on the URLs of the domain
+	// and its subdomains, for sites whose other parameters are session or display state
+	AllowedParams map[string][]string
+	// IndexPages are the directory index file names removed from paths. DefaultIndexPages when nil.
+	IndexPages []string
+	// KeepQueryOrder disables the sorting of query parameters, for sites where their order is significant
+	KeepQueryOrder bool
+}
+
+// CanonicalRule is a canonicalization rule, rewriting a part of a URL
+type CanonicalRule func(u *canonicalURL)
+
+// Canonicalizer rewrites URLs to a canonical form by applying its rules in order,
+// so that the variants of a URL are crawled once
+type Canonicalizer struct {
+	rules []CanonicalRule
+}
+
+// NewCanonicalizer creates a new Canonicalizer with the standard rules:
+// lowercasing of the scheme and host, IDN to punycode, removal of default ports,
+// percent-encoding normalization, dot-segment removal as in RFC 3986, duplicate slash
+// and index page removal, tracking parameter removal, per-domain parameter allowlists
+// and query sorting
+func NewCanonicalizer(config CanonicalizerConfig) *Canonicalizer {
+	trackingParams := config.TrackingParams
+	if trackingParams == nil {
+		trackingParams = DefaultTrackingParams
+	}
+	indexPages := config.IndexPages
+	if indexPages == nil {
+		indexPages = DefaultIndexPages
+	}
+
+	rules := []CanonicalRule{
+		normalizeScheme,
+		normalizeHost,
+		removeDefaultPort,
+		normalizePathEncoding,
+		removeDotSegments,
+		collapseSlashes,
+		removeIndexPage(indexPages),
+		normalizeQueryEncoding,
+		removeQueryParams(trackingParams),
+	}
+	if len(config.AllowedParams) > 0 {
+		rules = append(rules, allowQueryParams(config.AllowedParams))
+	}
+	if !config.KeepQueryOrder {
+		rules = append(rules, sortQuery)
+	}
+
+	return &Canonicalizer{rules: rules}
+}
+
+// Canonicalize returns the canonical form of a URL, without fragment
+func (c *Canonicalizer) Canonicalize(u *url.URL) string {
+	// URLs without hierarchical part, such as mailto:, are only stripped of their fragment
+	if u.Opaque != "" {
+		opaque := *u
+		opaque.Scheme = strings.ToLower(u.Scheme)
+		opaque.Fragment = ""
+		opaque.RawFragment = ""
+		return opaque.String()
+	}
+
+	cu := newCanonicalURL(u)
+	for _, rule := range c.rules {
+		rule(cu)
+	}
+	return cu.String()
+}
+
+// defaultCanonicalizer canonicalizes the URLs created by NewURL
+var defaultCanonicalizer atomic.Pointer[Canonicalizer]
+
+func init() {
+	defaultCanonicalizer.Store(NewCanonicalizer(CanonicalizerConfig{}))
+}
+
+// SetDefaultCanonicalizer replaces the canonicalizer of the URLs created by NewURL
+func SetDefaultCanonicalizer(c *Canonicalizer) {
+	defaultCanonicalizer.Store(c)
+}
+
+// queryParam is a query parameter, with its key and value percent-encoded
+type queryParam struct {
+	key      string
+	value    string
+	hasValue bool
+}
+
+// canonicalURL is a URL being canonicalized, its path and query percent-encoded
+type canonicalURL struct {
+	scheme   string
+	userinfo string
+	host     string
+	port     string
+	path     string
+	query    []queryParam
+}
+
+func newCanonicalURL(u *url.URL) *canonicalURL {
+	cu := &canonicalURL{
+		scheme: u.Scheme,
+		host:   u.Hostname(),
+		port:   u.Port(),
+		path:   u.EscapedPath(),
+	}
+	if u.User != nil {
+		cu.userinfo = u.User.String()
+	}
+
+	for _, pair := range strings.Split(u.RawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, hasValue := strings.Cut(pair, "=")
+		cu.query = append(cu.query, queryParam{key: key, value: value, hasValue: hasValue})
+	}
+
+	return cu
+}
+
+// String assembles the URL
+func (cu *canonicalURL) String() string {
+	var b strings.Builder
+	if cu.scheme != "" {
+		b.WriteString(cu.scheme)
+		b.WriteString(":")
+	}
+	if cu.host != "" || cu.userinfo != "" {
+		b.WriteString("//")
+		if cu.userinfo != "" {
+			b.WriteString(cu.userinfo)
+			b.WriteString("@")
+		}
+		if strings.Contains(cu.host, ":") {
+			b.WriteString("[" + cu.host + "]")
+		} else {
+			b.WriteString(cu.host)
+		}
+		if cu.port != "" {
+			b.WriteString(":" + cu.port)
+		}
+	}
+	b.WriteString(cu.path)
+
+	for i, param := range cu.query {
+		if i == 0 {
+			b.WriteString("?")
+		} else {
+			b.WriteString("&")
+		}
+		b.WriteString(param.key)
+		if param.hasValue {
+			b.WriteString("=" + param.value)
+		}
+	}
+
+	return b.String()
+}
+
+// normalizeScheme lowercases the scheme, https when missing
+func normalizeScheme(cu *canonicalURL) {
+	cu.scheme = strings.ToLower(cu.scheme)
+	if cu.scheme == "" {
+		cu.scheme = "https"
+	}
+}
+
+// normalizeHost lowercases the host, converts internationalized domain names to punycode
+// and removes the trailing dot of fully qualified names
+func normalizeHost(cu *canonicalURL) {
+	host := strings.TrimSuffix(strings.ToLower(cu.host), ".")
+	if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+		host = ascii
+	}
+	cu.host = host
+}
+
+// removeDefaultPort removes the port when it is the default port of the scheme
+func removeDefaultPort(cu *canonicalURL) {
+	if (cu.scheme == "http" && cu.port == "80") || (cu.scheme == "https" && cu.port == "443") {
+		cu.port = ""
+	}
+}
+
+// normalizePathEncoding decodes percent-encoded unreserved characters, uppercases the
+// remaining percent-encodings and encodes the characters not allowed in paths
+func normalizePathEncoding(cu *canonicalURL) {
+	cu.path = normalizeEncoding(cu.path, "/:@!$&'()*+,;=")
+	if cu.path == "" && cu.host != "" {
+		cu.path = "/"
+	}
+}
+
+// removeDotSegments resolves the . and .. segments of the path as in RFC 3986 section 5.2.4
+func removeDotSegments(cu *canonicalURL) {
+	input := cu.path
+	output := make([]string, 0)
+
+	for input != "" {
+		switch {
+		case strings.HasPrefix(input, "../"):
+			input = input[3:]
+		case strings.HasPrefix(input, "./"):
+			input = input[2:]
+		case strings.HasPrefix(input, "/./"):
+			input = input[2:]
+		case input == "/.":
+			input = "/"
+		case strings.HasPrefix(input, "/../"):
+			input = input[3:]
+			if len(output) > 0 {
+				output = output[:len(output)-1]
+			}
+		case input == "/..":
+			input = "/"
+			if len(output) > 0 {
+				output = output[:len(output)-1]
+			}
+		case input == "." || input == "..":
+			input = ""
+		default:
+			// Move the first segment, with its leading slash, to the output
+			end := strings.Index(input[1:], "/")
+			if end < 0 {
+				output = append(output, input)
+				input = ""
+			} else {
+				output = append(output, input[:end+1])
+				input = input[end+1:]
+			}
+		}
+	}
+
+	cu.path = strings.Join(output, "")
+	if cu.path == "" && cu.host != "" {
+		cu.path = "/"
+	}
+}
+
+// collapseSlashes replaces runs of slashes in the path with a single slash
+func collapseSlashes(cu *canonicalURL) {
+	for strings.Contains(cu.path, "//") {
+		cu.path = strings.ReplaceAll(cu.path, "//", "/")
+	}
+}
+
+// removeIndexPage removes a directory index file name ending the path
+func removeIndexPage(pages []string) CanonicalRule {
+	return func(cu *canonicalURL) {
+		slash := strings.LastIndex(cu.path, "/")
+		last := cu.path[slash+1:]
+		for _, page := range pages {
+			if strings.EqualFold(last, page) {
+				cu.path = cu.path[:slash+1]
+				return
+			}
+		}
+	}
+}
+
+// normalizeQueryEncoding normalizes the percent-encoding of query keys and values
+func normalizeQueryEncoding(cu *canonicalURL) {
+	for i := range cu.query {
+		cu.query[i].key = normalizeEncoding(cu.query[i].key, "/:@!$'()*+,;?")
+		cu.query[i].value = normalizeEncoding(cu.query[i].value, "/:@!$'()*+,;=?")
+	}
+}
+
+// removeQueryParams removes the query parameters matching any of names,
+// case-insensitively, a trailing * matching any suffix
+func removeQueryParams(names []string) CanonicalRule {
+	return func(cu *canonicalURL) {
+		kept := cu.query[:0]
+		for _, param := range cu.query {
+			if !matchesParam(param.key, names) {
+				kept = append(kept, param)
+			}
+		}
+		cu.query = kept
+	}
+}
+
+// allowQueryParams keeps only the query parameters allowed for the domain of the URL,
+// the most specific domain applying when several match
+func allowQueryParams(allowed map[string][]string) CanonicalRule {
+	return func(cu *canonicalURL) {
+		var names []string
+		matched := ""
+		for domain, domainNames := range allowed {
+			domain = strings.ToLower(domain)
+			if cu.host != domain && !strings.HasSuffix(cu.host, "."+domain) {
+				continue
+			}
+			if len(domain) > len(matched) {
+				names, matched = domainNames, domain
+			}
+		}
+		if matched == "" {
+			return
+		}
+
+		kept := cu.query[:0]
+		for _, param := range cu.query {
+			if matchesParam(param.key, names) {
+				kept = append(kept, param)
+			}
+		}
+		cu.query = kept
+	}
+}
+
+// sortQuery sorts the query parameters by key, then value, keeping the order of repeated pairs
+func sortQuery(cu *canonicalURL) {
+	sort.SliceStable(cu.query, func(i, j int) bool {
+		if cu.query[i].key != cu.query[j].key {
+			return cu.query[i].key < cu.query[j].key
+		}
+		return cu.query[i].value < cu.query[j].value
+	})
+}
+
+// matchesParam reports whether a percent-encoded query key matches any of names
+func matchesParam(key string, names []string) bool {
+	if decoded, err := url.QueryUnescape(key); err == nil {
+		key = decoded
+	}
+	key = strings.ToLower(key)
+
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if prefix, ok := strings.CutSuffix(name, "*"); ok {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if key == name {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeEncoding decodes percent-encoded unreserved characters, uppercases the remaining
+// percent-encodings and percent-encodes the bytes that are neither unreserved nor in allowed
+func normalizeEncoding(s, allowed string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+			decoded := unhex(s[i+1])<<4 | unhex(s[i+2])
+			if isUnreserved(decoded) {
+				b.WriteByte(decoded)
+			} else {
+				b.WriteByte('%')
+				b.WriteByte(hex[decoded>>4])
+				b.WriteByte(hex[decoded&0x0f])
+			}
+			i += 2
+			continue
+		}
+
+		if isUnreserved(c) || strings.IndexByte(allowed, c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0x0f])
+		}
+	}
+
+	return b.String()
+}
+
+// isUnreserved reports whether c is an unreserved character of RFC 3986
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
@@ -0,0 +1,208 @@
+package crawler
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DiffGranularity is the unit compared by a diff
+type DiffGranularity string
+
+const (
+	DiffLines DiffGranularity = "line"
+	DiffWords DiffGranularity = "word"
+)
+
+// DiffOp is the kind of a diff edit
+type DiffOp string
+
+const (
+	DiffEqual  DiffOp = "equal"
+	DiffInsert DiffOp = "insert"
+	DiffDelete DiffOp = "delete"
+)
+
+// DiffEdit is a run of text kept, inserted or deleted between two versions
+type DiffEdit struct {
+	Op   DiffOp
+	Text string
+}
+
+// maxDiffEdits bounds the edit distance searched by Diff, beyond which the changed
+// region is reported as deleted and inserted as a whole
+const maxDiffEdits = 2000
+
+// Diff returns the edits turning a into b, comparing lines or words.
+// Whitespace is kept in the edits so that joining the equal and inserted texts gives b.
+func Diff(a, b string, granularity DiffGranularity) []DiffEdit {
+	split := splitLines
+	if granularity == DiffWords {
+		split = splitWords
+	}
+	x, y := split(a), split(b)
+
+	// Common prefixes and suffixes are trimmed before searching for the shortest edit script
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]DiffEdit, 0)
+	edits = appendEdits(edits, DiffEqual, x[:prefix])
+	edits = append(edits, myers(x[prefix:len(x)-suffix], y[prefix:len(y)-suffix])...)
+	edits = appendEdits(edits, DiffEqual, x[len(x)-suffix:])
+
+	return mergeEdits(edits)
+}
+
+// myers returns the shortest edit script turning x into y, from Myers, "An O(ND) Difference Algorithm"
+func myers(x, y []string) []DiffEdit {
+	n, m := len(x), len(y)
+	if n == 0 || m == 0 {
+		return appendEdits(appendEdits(nil, DiffDelete, x), DiffInsert, y)
+	}
+
+	maxD := min(n+m, maxDiffEdits)
+	offset := maxD + 1
+	v := make([]int, 2*offset+1)
+	trace := make([][]int, 0)
+
+	found := false
+	for d := 0; d <= maxD && !found; d++ {
+		// Only the diagonals reachable in d edits are saved, trace[d][k+d+1] being diagonal k
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var i int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				i = v[offset+k+1]
+			} else {
+				i = v[offset+k-1] + 1
+			}
+			j := i - k
+			for i < n && j < m && x[i] == y[j] {
+				i++
+				j++
+			}
+			v[offset+k] = i
+			if i >= n && j >= m {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		return appendEdits(appendEdits(nil, DiffDelete, x), DiffInsert, y)
+	}
+
+	// Backtrack through the saved frontiers from the end of both sequences
+	reversed := make([]DiffEdit, 0)
+	i, j := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d]
+		k := i - j
+		var prevK int
+		if k == -d || (k != d && prev[k+d] < prev[k+d+2]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevI := prev[prevK+d+1]
+		prevJ := prevI - prevK
+
+		for i > prevI && j > prevJ {
+			i--
+			j--
+			reversed = append(reversed, DiffEdit{Op: DiffEqual, Text: x[i]})
+		}
+		if i > prevI {
+			i--
+			reversed = append(reversed, DiffEdit{Op: DiffDelete, Text: x[i]})
+		} else {
+			j--
+			reversed = append(reversed, DiffEdit{Op: DiffInsert, Text: y[j]})
+		}
+	}
+	for i > 0 && j > 0 {
+		i--
+		j--
+		reversed = append(reversed, DiffEdit{Op: DiffEqual, Text: x[i]})
+	}
+
+	edits := make([]DiffEdit, len(reversed))
+	for k, edit := range reversed {
+		edits[len(reversed)-1-k] = edit
+	}
+	return edits
+}
+
+// appendEdits appends an edit of the given kind per token
+func appendEdits(edits []DiffEdit, op DiffOp, tokens []string) []DiffEdit {
+	for _, token := range tokens {
+		edits = append(edits, DiffEdit{Op: op, Text: token})
+	}
+	return edits
+}
+
+// mergeEdits joins adjacent edits of the same kind, deletions before insertions
+func mergeEdits(edits []DiffEdit) []DiffEdit {
+	merged := make([]DiffEdit, 0)
+	var deleted, inserted strings.Builder
+
+	flush := func() {
+		if deleted.Len() > 0 {
+			merged = append(merged, DiffEdit{Op: DiffDelete, Text: deleted.String()})
+			deleted.Reset()
+		}
+		if inserted.Len() > 0 {
+			merged = append(merged, DiffEdit{Op: DiffInsert, Text: inserted.String()})
+			inserted.Reset()
+		}
+	}
+
+	for _, edit := range edits {
+		switch edit.Op {
+		case DiffDelete:
+			deleted.WriteString(edit.Text)
+		case DiffInsert:
+			inserted.WriteString(edit.Text)
+		default:
+			flush()
+			if n := len(merged); n > 0 && merged[n-1].Op == DiffEqual {
+				merged[n-1].Text += edit.Text
+			} else {
+				merged = append(merged, edit)
+			}
+		}
+	}
+	flush()
+
+	return merged
+}
+
+// splitLines splits text into lines, each keeping its line break
+func splitLines(text string) []string {
+	return strings.SplitAfter(text, "\n")
+}
+
+// splitWords splits text into words, each keeping the whitespace that follows it
+func splitWords(text string) []string {
+	tokens := make([]string, 0)
+	start := 0
+	inSpace := false
+	for i, r := range text {
+		space := unicode.IsSpace(r)
+		if inSpace && !space {
+			tokens = append(tokens, text[start:i])
+			start = i
+		}
+		inSpace = space
+	}
+	if start < len(text) {
+		tokens = append(tokens, text[start:])
+	}
+	return tokens
+}
@@ -1,7 +1,13 @@
 package crawler
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	result "github.com/gerthdala/webcrawler/pkg/utils/result"
@@ -9,16 +15,6 @@ import (
 	"github.com/google/uuid"
 )
 
-// Status represents the status of a URL in the crawling process
-type Status string
-
-const (
-	StatusPending  Status = "pending"
-	StatusFetching Status = "fetching"
-	StatusFetched  Status = "fetched"
-	StatusFailed   Status = "failed"
-)
-
 // URL represents a URL to be crawled
 type URL struct {
 	ID            uuid.UUID
@@ -27,10 +23,34 @@ type URL struct {
 	Depth         int
 	Status        Status
 	ParentURL     string
-	AttemptCount  int
-	LastAttempt   time.Time
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
+	// OffDomainHops counts the consecutive links outside the allowed domains followed to reach the URL
+	OffDomainHops int
+	// StatusReason is a code for why the URL is in its status, and StatusDetail explains it
+	StatusReason string
+	StatusDetail string
+	AttemptCount int
+	LastAttempt  time.Time
+	// LastModified, ChangeFrequency and Priority are declared by sitemaps, when listed in one
+	LastModified    time.Time
+	ChangeFrequency ChangeFrequency
+	Priority        float64
+	// NextCrawlAt is when the URL is due to be crawled again, zero when not scheduled
+	NextCrawlAt time.Time
+	// ContentHash is the hash of the content of the last fetch, compared to detect changes
+	ContentHash   string
+	LastFetchedAt time.Time
+	LastChangedAt time.Time
+	// Revisits counts the fetches compared to a previous one, over a total of RevisitSpan,
+	// and Changes those that found the content changed
+	Revisits    int
+	Changes     int
+	RevisitSpan time.Duration
+	// Failures counts the consecutive failed fetches, retried while retryable
+	Failures int
+	// FeedItem is the feed item the URL was discovered from, if any
+	FeedItem  *FeedItem
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
 // NewURL creates a new URL entity
@@ -57,32 +77,91 @@ func NewURL(rawURL string, depth int, parentURL string) result.Result[*URL] {
 	})
 }
 
-// normalizeURL standardizes a URL by removing fragments, default ports, etc.
-func normalizeURL(u *url.URL) string {
-	// Make a copy to avoid modifying the original
-	normalized := *u
+// Host returns the hostname of the URL
+func (u *URL) Host() string {
+	parsed, err := url.Parse(u.URL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Hostname()
+}
 
-	// Remove fragments
-	normalized.Fragment = ""
+// Transition moves the URL to a new status for the given reason, failing on transitions
+// the URL lifecycle does not allow
+func (u *URL) Transition(status Status, reason, detail string) error {
+	if !u.Status.CanTransitionTo(status) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, u.Status, status)
+	}
 
-	// Use https by default if the scheme is empty
-	if normalized.Scheme == "" {
-		normalized.Scheme = "https"
+	u.Status = status
+	u.StatusReason = reason
+	u.StatusDetail = detail
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// ApplySitemapEntry records the metadata a sitemap declares for the URL and schedules its recrawl:
+// right away when the sitemap declares it modified since its last fetch, from its change frequency
+// when no recrawl is scheduled yet
+func (u *URL) ApplySitemapEntry(entry SitemapEntry) {
+	now := time.Now()
+	u.LastModified = entry.LastModified
+	u.ChangeFrequency = entry.ChangeFrequency
+	u.Priority = entry.Priority
+	if u.ModifiedSinceFetch() {
+		u.NextCrawlAt = now
+	} else if interval := entry.ChangeFrequency.Interval(); interval > 0 && u.NextCrawlAt.IsZero() {
+		u.NextCrawlAt = now.Add(interval)
 	}
+	u.UpdatedAt = now
+}
 
-	// Remove default ports
-	if (normalized.Scheme == "http" && normalized.Port() == "80") ||
-		(normalized.Scheme == "https" && normalized.Port() == "443") {
-		normalized.Host = normalized.Hostname()
+// ModifiedSinceFetch checks if the URL was fetched before the last modification its sitemap declares
+func (u *URL) ModifiedSinceFetch() bool {
+	return !u.LastFetchedAt.IsZero() && u.LastModified.After(u.LastFetchedAt)
+}
+
+// RecordFetch records the content hash of a fetch of the URL, reporting whether the content changed
+func (u *URL) RecordFetch(contentHash string, fetchedAt time.Time) bool {
+	changed := false
+	if u.LastFetchedAt.IsZero() {
+		u.LastChangedAt = fetchedAt
+	} else {
+		u.Revisits++
+		u.RevisitSpan += fetchedAt.Sub(u.LastFetchedAt)
+		if contentHash != u.ContentHash {
+			u.Changes++
+			u.LastChangedAt = fetchedAt
+			changed = true
+		}
 	}
 
-	// Ensure paths end with a trailing slash if they don't have an extension
-	// This helps with canonical URLs
-	if normalized.Path == "" {
-		normalized.Path = "/"
+	u.ContentHash = contentHash
+	u.LastFetchedAt = fetchedAt
+	u.UpdatedAt = time.Now()
+	return changed
+}
+
+// ChangeRate estimates the rate at which the content of the URL changes, in changes per second,
+// reporting false before the URL has been revisited.
+// Revisits only detect whether the content changed at least once since the previous fetch, so the
+// rate is estimated as in Cho and Garcia-Molina, "Estimating Frequency of Change" (2003):
+// -log((n - X + 0.5) / (n + 0.5)) / I for X changes detected over n revisits of mean interval I.
+func (u *URL) ChangeRate() (float64, bool) {
+	if u.Revisits == 0 || u.RevisitSpan <= 0 {
+		return 0, false
 	}
 
-	return normalized.String()
+	n := float64(u.Revisits)
+	changes := float64(min(u.Changes, u.Revisits))
+	meanInterval := u.RevisitSpan.Seconds() / n
+
+	return -math.Log((n-changes+0.5)/(n+0.5)) / meanInterval, true
+}
+
+// normalizeURL returns the canonical form of a URL, by the default canonicalizer
+func normalizeURL(u *url.URL) string {
+	return defaultCanonicalizer.Load().Canonicalize(u)
 }
 
 // Page represents a fetched web page
@@ -96,8 +175,31 @@ type Page struct {
 	Headers     map[string]string
 	Links       []string
 	ContentType string
-	FetchedAt   time.Time
-	ParsedAt    time.Time
+	Language    string
+	// Feeds are the RSS and Atom feeds the page links to
+	Feeds []string
+	// FeedItem is the feed item the page was discovered from, if any
+	FeedItem *FeedItem
+	// CanonicalURL is the URL the page declares canonical with rel=canonical, if any
+	CanonicalURL string
+	// DuplicateOf is the URL of the already crawled canonical page this page mirrors, if any
+	DuplicateOf string
+	// RequestedURL is the URL fetched, and Redirects the chain followed from it to URL, if any
+	RequestedURL string
+	Redirects    []Redirect
+	// Truncated is set when the body exceeded the maximum size and was cut to it
+	Truncated bool
+	// Charset is the charset the body was served in and transcoded to UTF-8 from
+	Charset   string
+	FetchedAt time.Time
+	ParsedAt  time.Time
+}
+
+// Redirect is a redirect response followed when fetching a page
+type Redirect struct {
+	URL        string
+	StatusCode int
+	Location   string
 }
 
 // NewPage creates a new Page entity
@@ -113,6 +215,17 @@ func NewPage(url string, statusCode int, html string, headers map[string]string)
 	}
 }
 
+// SetRedirects records the URL fetched and the redirects followed from it to the page URL
+func (p *Page) SetRedirects(requestedURL string, redirects []Redirect) {
+	p.RequestedURL = requestedURL
+	p.Redirects = redirects
+}
+
+// IsRedirected checks if the page was fetched following redirects
+func (p *Page) IsRedirected() bool {
+	return len(p.Redirects) > 0
+}
+
 // AddLinks adds extracted links to the page
 func (p *Page) AddLinks(links []string) {
 	p.Links = links
@@ -131,6 +244,283 @@ func (p *Page) SetPlainText(text string) {
 	p.ParsedAt = time.Now()
 }
 
+// SetLanguage sets the declared page language
+func (p *Page) SetLanguage(language string) {
+	p.Language = language
+	p.ParsedAt = time.Now()
+}
+
+// SetCanonicalURL sets the canonical URL declared by the page
+func (p *Page) SetCanonicalURL(canonicalURL string) {
+	p.CanonicalURL = canonicalURL
+	p.ParsedAt = time.Now()
+}
+
+// MarkDuplicateOf marks the page as a mirror of an already crawled canonical page
+func (p *Page) MarkDuplicateOf(canonicalURL string) {
+	p.DuplicateOf = canonicalURL
+}
+
+// IsDuplicate reports whether the page mirrors an already crawled canonical page,
+// in which case its content is not indexed nor analyzed
+func (p *Page) IsDuplicate() bool {
+	return p.DuplicateOf != ""
+}
+
+// Validators returns the cache validators of the page, sent back to fetch it conditionally
+func (p *Page) Validators() Validators {
+	var v Validators
+	for name, value := range p.Headers {
+		switch {
+		case strings.EqualFold(name, "ETag"):
+			v.ETag = value
+		case strings.EqualFold(name, "Last-Modified"):
+			v.LastModified = value
+		}
+	}
+	return v
+}
+
+// ContentHash returns the SHA-256 hash of the page content, its plain text when parsed and its HTML otherwise
+func (p *Page) ContentHash() string {
+	content := p.PlainText
+	if content == "" {
+		content = p.HTML
+	}
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsNotModified reports whether the page is a 304 Not Modified response to a conditional fetch
+func (p *Page) IsNotModified() bool {
+	return p.StatusCode == http.StatusNotModified
+}
+
+// FetchError returns the error of a page fetched with a 4xx or 5xx status code, nil otherwise
+func (p *Page) FetchError() *FetchError {
+	return StatusError(p.URL, p.StatusCode)
+}
+
+// IsHTML checks if the page content is HTML, assumed when no content type is declared
+func (p *Page) IsHTML() bool {
+	return p.ContentType == "" || strings.Contains(strings.ToLower(p.ContentType), "html")
+}
+
+// SetFeeds sets the feeds the page links to
+func (p *Page) SetFeeds(feeds []string) {
+	p.Feeds = feeds
+	p.ParsedAt = time.Now()
+}
+
+// PageVersion is a version of the content of a page, recorded each time it is fetched changed
+type PageVersion struct {
+	ID     uuid.UUID
+	PageID uuid.UUID
+	URL    string
+	// Version numbers the versions of a URL from 1
+	Version     int
+	ContentHash string
+	StatusCode  int
+	Title       string
+	HTML        string
+	PlainText   string
+	FetchedAt   time.Time
+}
+
+// IsAvailable reports whether the version was fetched successfully
+func (v *PageVersion) IsAvailable() bool {
+	return v.StatusCode >= 200 && v.StatusCode < 300
+}
+
+// IsGone reports whether the version records the page as removed
+func (v *PageVersion) IsGone() bool {
+	return v.StatusCode == http.StatusNotFound || v.StatusCode == http.StatusGone
+}
+
+// NewPageVersion creates a new version of a page
+func NewPageVersion(page *Page, version int) *PageVersion {
+	return &PageVersion{
+		ID:          uuid.New(),
+		PageID:      page.ID,
+		URL:         page.URL,
+		Version:     version,
+		ContentHash: page.ContentHash(),
+		StatusCode:  page.StatusCode,
+		Title:       page.Title,
+		HTML:        page.HTML,
+		PlainText:   page.PlainText,
+		FetchedAt:   page.FetchedAt,
+	}
+}
+
+// ChangeKind is the kind of change of a page between two versions
+type ChangeKind string
+
+const (
+	ChangeAppeared    ChangeKind = "appeared"
+	ChangeChanged     ChangeKind = "changed"
+	ChangeDisappeared ChangeKind = "disappeared"
+)
+
+// PageChangeEvent records that a page appeared, changed or disappeared
+type PageChangeEvent struct {
+	Kind ChangeKind
+	URL  string
+	// Previous is the version before the change, nil when the page appeared for the first time
+	Previous *PageVersion
+	Current  *PageVersion
+}
+
+// PageChange summarizes the versions of a page fetched within a period
+type PageChange struct {
+	URL string
+	// Created is true when the first version of the page was fetched within the period
+	Created bool
+	// Versions is the number of versions fetched within the period
+	Versions      int
+	LatestVersion int
+	ChangedAt     time.Time
+}
+
+// ChangeFrequency is how often a sitemap declares a page changes
+type ChangeFrequency string
+
+const (
+	ChangeAlways  ChangeFrequency = "always"
+	ChangeHourly  ChangeFrequency = "hourly"
+	ChangeDaily   ChangeFrequency = "daily"
+	ChangeWeekly  ChangeFrequency = "weekly"
+	ChangeMonthly ChangeFrequency = "monthly"
+	ChangeYearly  ChangeFrequency = "yearly"
+	ChangeNever   ChangeFrequency = "never"
+)
+
+// Interval returns the recrawl interval of the change frequency, 0 when unknown or never.
+// Pages changing always are recrawled hourly.
+func (f ChangeFrequency) Interval() time.Duration {
+	switch f {
+	case ChangeAlways, ChangeHourly:
+		return time.Hour
+	case ChangeDaily:
+		return 24 * time.Hour
+	case ChangeWeekly:
+		return 7 * 24 * time.Hour
+	case ChangeMonthly:
+		return 30 * 24 * time.Hour
+	case ChangeYearly:
+		return 365 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// DefaultSitemapPriority is the priority of sitemap entries that do not declare one
+const DefaultSitemapPriority = 0.5
+
+// SitemapEntry is a URL listed in a sitemap
+type SitemapEntry struct {
+	Loc             string
+	LastModified    time.Time
+	ChangeFrequency ChangeFrequency
+	// Priority ranges from 0 to 1, relative to the other URLs of the site
+	Priority float64
+	// Sitemap is the URL of the sitemap listing the entry
+	Sitemap string
+}
+
+// JobPriority returns the crawl job priority of the entry, from 0 to 10
+func (e SitemapEntry) JobPriority() int {
+	return int(math.Round(e.Priority * 10))
+}
+
+// DomainRecrawls counts the recrawls of a domain scheduled within a period
+type DomainRecrawls struct {
+	Domain string
+	Count  int
+	// NextAt is when the first of the recrawls is due
+	NextAt time.Time
+}
+
+// Validators are the cache validators of a previously fetched page
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// IsZero reports whether there are no validators
+func (v Validators) IsZero() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+// Feed is an RSS or Atom feed polled for new items
+type Feed struct {
+	ID    uuid.UUID
+	URL   string
+	Title string
+	// SiteURL is the page the feed was discovered on
+	SiteURL string
+	// ETag and LastModified are the validators of the last response, sent back in conditional requests
+	ETag         string
+	LastModified string
+	PollInterval time.Duration
+	LastPolledAt time.Time
+	NextPollAt   time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// NewFeed creates a new Feed entity, due to be polled immediately
+func NewFeed(feedURL, siteURL string, pollInterval time.Duration) *Feed {
+	now := time.Now()
+	return &Feed{
+		ID:           uuid.New(),
+		URL:          feedURL,
+		SiteURL:      siteURL,
+		PollInterval: pollInterval,
+		NextPollAt:   now,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// RecordPoll records a poll of the feed and schedules the next one after interval
+func (f *Feed) RecordPoll(fetch *FeedFetch, interval time.Duration) {
+	now := time.Now()
+	if !fetch.NotModified {
+		f.ETag = fetch.ETag
+		f.LastModified = fetch.LastModified
+		if fetch.Title != "" {
+			f.Title = fetch.Title
+		}
+	}
+	f.PollInterval = interval
+	f.LastPolledAt = now
+	f.NextPollAt = now.Add(interval)
+	f.UpdatedAt = now
+}
+
+// FeedItem is an item of a feed
+type FeedItem struct {
+	// GUID identifies the item within its feed, its link when the feed gives no identifier
+	GUID       string
+	FeedURL    string
+	Link       string
+	Title      string
+	Published  time.Time
+	Author     string
+	Categories []string
+}
+
+// FeedFetch is the response to a feed poll
+type FeedFetch struct {
+	Title string
+	Items []FeedItem
+	// NotModified is true when the feed has not changed since the last poll
+	NotModified  bool
+	ETag         string
+	LastModified string
+}
+
 // CrawlJob represents a job to crawl a URL
 type CrawlJob struct {
 	URL       *URL
@@ -0,0 +1,196 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+)
+
+// FeedService discovers feeds and polls them for new items to crawl
+type FeedService struct {
+	feedRepo     FeedRepository
+	urlRepo      URLRepository
+	crawlJobRepo CrawlJobRepository
+	fetcher      FeedFetcherService
+	minInterval  time.Duration
+	maxInterval  time.Duration
+	itemPriority int
+}
+
+// FeedServiceConfig configuration for the feed service
+type FeedServiceConfig struct {
+	// MinInterval and MaxInterval bound the poll interval of a feed, 15 minutes and 24 hours by default.
+	// The interval halves when a poll finds new items and doubles when it does not.
+	MinInterval time.Duration
+	MaxInterval time.Duration
+	// ItemPriority is the crawl job priority of new items, 20 by default to crawl them before links
+	ItemPriority int
+}
+
+// NewFeedService creates a new FeedService
+func NewFeedService(
+	feedRepo FeedRepository,
+	urlRepo URLRepository,
+	crawlJobRepo CrawlJobRepository,
+	fetcher FeedFetcherService,
+	config FeedServiceConfig,
+) *FeedService {
+	minInterval := config.MinInterval
+	if minInterval <= 0 {
+		minInterval = 15 * time.Minute
+	}
+	maxInterval := config.MaxInterval
+	if maxInterval < minInterval {
+		maxInterval = max(24*time.Hour, minInterval)
+	}
+	itemPriority := config.ItemPriority
+	if itemPriority <= 0 {
+		itemPriority = 20
+	}
+
+	return &FeedService{
+		feedRepo:     feedRepo,
+		urlRepo:      urlRepo,
+		crawlJobRepo: crawlJobRepo,
+		fetcher:      fetcher,
+		minInterval:  minInterval,
+		maxInterval:  maxInterval,
+		itemPriority: itemPriority,
+	}
+}
+
+// DiscoverFeeds registers the feeds a page links to and returns the number of new feeds
+func (s *FeedService) DiscoverFeeds(ctx context.Context, page *Page) result.Result[int] {
+	added := 0
+	for _, feedURL := range page.Feeds {
+		if existing := s.feedRepo.FindByURL(ctx, feedURL); existing.IsOk() {
+			continue
+		}
+		if saveResult := s.feedRepo.Save(ctx, NewFeed(feedURL, page.URL, s.minInterval)); saveResult.IsErr() {
+			return result.Err[int](fmt.Errorf("failed to save feed %s: %w", feedURL, saveResult.Error()))
+		}
+		added++
+	}
+
+	return result.Ok(added)
+}
+
+// Run polls due feeds every tick until the context is done
+func (s *FeedService) Run(ctx context.Context, tick time.Duration, batchSize int) error {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		if polled := s.PollDue(ctx, batchSize); polled.IsErr() {
+			log.Printf("Failed to poll feeds: %v", polled.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// PollDue polls up to limit feeds due to be polled and returns the number of new items enqueued
+func (s *FeedService) PollDue(ctx context.Context, limit int) result.Result[int] {
+	feedsResult := s.feedRepo.FindDue(ctx, time.Now(), limit)
+	if feedsResult.IsErr() {
+		return result.Err[int](fmt.Errorf("failed to find due feeds: %w", feedsResult.Error()))
+	}
+
+	enqueued := 0
+	for _, feed := range feedsResult.Unwrap() {
+		if err := ctx.Err(); err != nil {
+			return result.Err[int](err)
+		}
+
+		pollResult := s.Poll(ctx, &feed)
+		if pollResult.IsErr() {
+			log.Printf("Failed to poll feed %s: %v", feed.URL, pollResult.Error())
+			continue
+		}
+		enqueued += pollResult.Unwrap()
+	}
+
+	return result.Ok(enqueued)
+}
+
+// Poll fetches a feed and enqueues the links of its new items, then schedules its next poll
+func (s *FeedService) Poll(ctx context.Context, feed *Feed) result.Result[int] {
+	fetchResult := s.fetcher.FetchFeed(ctx, feed)
+	if fetchResult.IsErr() {
+		// Failing feeds are retried later rather than every tick
+		feed.RecordPoll(&FeedFetch{NotModified: true}, s.nextInterval(feed, false))
+		s.feedRepo.Update(ctx, feed)
+		return result.Err[int](fetchResult.Error())
+	}
+	fetch := fetchResult.Unwrap()
+
+	newItems := make([]FeedItem, 0)
+	if !fetch.NotModified && len(fetch.Items) > 0 {
+		itemsResult := s.feedRepo.SaveItems(ctx, feed.ID, fetch.Items)
+		if itemsResult.IsErr() {
+			return result.Err[int](fmt.Errorf("failed to save feed items: %w", itemsResult.Error()))
+		}
+		newItems = itemsResult.Unwrap()
+	}
+
+	enqueued := 0
+	for i := range newItems {
+		if s.enqueueItem(ctx, feed, &newItems[i]) {
+			enqueued++
+		}
+	}
+
+	feed.RecordPoll(fetch, s.nextInterval(feed, len(newItems) > 0))
+	if updateResult := s.feedRepo.Update(ctx, feed); updateResult.IsErr() {
+		return result.Err[int](fmt.Errorf("failed to update feed: %w", updateResult.Error()))
+	}
+
+	return result.Ok(enqueued)
+}
+
+// enqueueItem saves and enqueues the link of a feed item, reporting whether it was new
+func (s *FeedService) enqueueItem(ctx context.Context, feed *Feed, item *FeedItem) bool {
+	if item.Link == "" {
+		return false
+	}
+
+	urlResult := NewURL(item.Link, 0, feed.URL)
+	if urlResult.IsErr() {
+		log.Printf("Skipping malformed feed item URL: %s (error: %v)", item.Link, urlResult.Error())
+		return false
+	}
+	url := urlResult.Unwrap()
+
+	if existing := s.urlRepo.FindByNormalizedURL(ctx, url.NormalizedURL); existing.IsOk() {
+		return false
+	}
+
+	url.FeedItem = item
+	if saveResult := s.urlRepo.Save(ctx, url); saveResult.IsErr() {
+		return false
+	}
+
+	s.crawlJobRepo.Enqueue(ctx, NewCrawlJob(url, s.itemPriority))
+	return true
+}
+
+// nextInterval halves the poll interval of a feed with new items and doubles it otherwise
+func (s *FeedService) nextInterval(feed *Feed, hasNewItems bool) time.Duration {
+	interval := feed.PollInterval
+	if interval <= 0 {
+		interval = s.minInterval
+	}
+	if hasNewItems {
+		interval /= 2
+	} else {
+		interval *= 2
+	}
+	return min(max(interval, s.minInterval), s.maxInterval)
+}
@@ -0,0 +1,110 @@
+package crawler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// FetchErrorKind classifies why fetching a URL failed
+type FetchErrorKind string
+
+const (
+	FetchErrorDNS               FetchErrorKind = "dns"
+	FetchErrorTLS               FetchErrorKind = "tls"
+	FetchErrorTimeout           FetchErrorKind = "timeout"
+	FetchErrorConnectionRefused FetchErrorKind = "connection_refused"
+	// FetchErrorConnection is a connection reset or closed before the response completed
+	FetchErrorConnection       FetchErrorKind = "connection"
+	FetchErrorTooManyRedirects FetchErrorKind = "too_many_redirects"
+	FetchErrorRedirectLoop     FetchErrorKind = "redirect_loop"
+	FetchErrorRateLimited      FetchErrorKind = "rate_limited"
+	FetchErrorClient           FetchErrorKind = "http_4xx"
+	FetchErrorServer           FetchErrorKind = "http_5xx"
+	FetchErrorBodyTooLarge     FetchErrorKind = "body_too_large"
+	FetchErrorContentType      FetchErrorKind = "content_type"
+	FetchErrorInvalidRequest   FetchErrorKind = "invalid_request"
+	FetchErrorUnknown          FetchErrorKind = "unknown"
+)
+
+// IsRetryable checks if fetches failing with the kind of error may succeed when retried
+func (k FetchErrorKind) IsRetryable() bool {
+	switch k {
+	case FetchErrorDNS, FetchErrorTimeout, FetchErrorConnectionRefused, FetchErrorConnection,
+		FetchErrorRateLimited, FetchErrorServer, FetchErrorUnknown:
+		return true
+	}
+	return false
+}
+
+// FetchError is a failed fetch of a URL, classified by kind
+type FetchError struct {
+	Kind FetchErrorKind
+	URL  string
+	// StatusCode is the status of the response, zero when none was received
+	StatusCode int
+	Retryable  bool
+	Err        error
+}
+
+// NewFetchError creates a new FetchError, retryable depending on its kind
+func NewFetchError(kind FetchErrorKind, url string, err error) *FetchError {
+	return &FetchError{
+		Kind:      kind,
+		URL:       url,
+		Retryable: kind.IsRetryable(),
+		Err:       err,
+	}
+}
+
+// StatusError returns the FetchError of a response with a 4xx or 5xx status code, nil for other codes
+func StatusError(url string, statusCode int) *FetchError {
+	var kind FetchErrorKind
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		kind = FetchErrorRateLimited
+	case statusCode >= 500:
+		kind = FetchErrorServer
+	case statusCode >= 400:
+		kind = FetchErrorClient
+	default:
+		return nil
+	}
+
+	fetchErr := NewFetchError(kind, url, fmt.Errorf("%d %s", statusCode, http.StatusText(statusCode)))
+	fetchErr.StatusCode = statusCode
+	// A request timeout is retryable, while a server not implementing the request is not
+	switch statusCode {
+	case http.StatusRequestTimeout:
+		fetchErr.Retryable = true
+	case http.StatusNotImplemented:
+		fetchErr.Retryable = false
+	}
+	return fetchErr
+}
+
+// Error returns the kind and the cause of the error
+func (e *FetchError) Error() string {
+	return fmt.Sprintf("fetching %s failed (%s): %v", e.URL, e.Kind, e.Err)
+}
+
+// Unwrap returns the cause of the error
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryableFetchError checks if err is a FetchError that may succeed when retried
+func IsRetryableFetchError(err error) bool {
+	var fetchErr *FetchError
+	return errors.As(err, &fetchErr) && fetchErr.Retryable
+}
+
+// HostFetchErrors counts the fetch errors of a kind for a host
+type HostFetchErrors struct {
+	Host      string
+	Kind      FetchErrorKind
+	Count     int
+	LastError string
+	LastAt    time.Time
+}
@@ -0,0 +1,132 @@
+package crawler
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+var (
+	// ErrInvalidFilterRule is returned for filter rules with an unknown field or an invalid pattern
+	ErrInvalidFilterRule = errors.New("invalid filter rule")
+	// ErrURLNotAllowed is returned for URLs denied by the admission pipeline
+	ErrURLNotAllowed = errors.New("URL should not be crawled")
+)
+
+// RuleField is the part of a URL a filter rule matches
+type RuleField string
+
+const (
+	FieldURL   RuleField = "url"
+	FieldHost  RuleField = "host"
+	FieldPath  RuleField = "path"
+	FieldQuery RuleField = "query"
+)
+
+// IsValid checks if the field is a known RuleField
+func (f RuleField) IsValid() bool {
+	switch f {
+	case FieldURL, FieldHost, FieldPath, FieldQuery:
+		return true
+	}
+	return false
+}
+
+// Value returns the part of a URL matched by the field
+func (f RuleField) Value(rawURL string, u *url.URL) string {
+	switch f {
+	case FieldHost:
+		return u.Hostname()
+	case FieldPath:
+		return u.Path
+	case FieldQuery:
+		return u.RawQuery
+	default:
+		return rawURL
+	}
+}
+
+// FilterRule is a regular expression matched against a part of URLs
+type FilterRule struct {
+	Field   RuleField `json:"field"`
+	Pattern string    `json:"pattern"`
+}
+
+// FilterRules are the include and exclude rules of the URL filter. A URL is crawled when it
+// matches no exclude rule and, when include rules are set, at least one of them.
+type FilterRules struct {
+	Include []FilterRule `json:"include"`
+	Exclude []FilterRule `json:"exclude"`
+}
+
+// FilterCheck is a check of the pipeline admitting URLs to the crawl.
+// There is no budget check, the crawl has no page or host budget.
+type FilterCheck string
+
+const (
+	CheckURL          FilterCheck = "url"
+	CheckLength       FilterCheck = "length"
+	CheckDomain       FilterCheck = "domain"
+	CheckExtension    FilterCheck = "extension"
+	CheckExcludeRules FilterCheck = "exclude_rules"
+	CheckIncludeRules FilterCheck = "include_rules"
+	CheckDepth        FilterCheck = "depth"
+	CheckRobotsTxt    FilterCheck = "robots_txt"
+)
+
+// FilterDecision is the outcome of a check of the admission pipeline, and why it was made
+type FilterDecision struct {
+	Check   FilterCheck
+	Allowed bool
+	Reason  string
+}
+
+// Allow creates a FilterDecision passing a check
+func Allow(check FilterCheck, format string, args ...interface{}) FilterDecision {
+	return FilterDecision{Check: check, Allowed: true, Reason: fmt.Sprintf(format, args...)}
+}
+
+// Deny creates a FilterDecision failing a check
+func Deny(check FilterCheck, format string, args ...interface{}) FilterDecision {
+	return FilterDecision{Check: check, Reason: fmt.Sprintf(format, args...)}
+}
+
+// String returns the check and the reason of the decision
+func (d FilterDecision) String() string {
+	return fmt.Sprintf("%s: %s", d.Check, d.Reason)
+}
+
+// Status returns the status of a URL denied by the decision, blocked by robots.txt or filtered otherwise
+func (d FilterDecision) Status() Status {
+	if d.Check == CheckRobotsTxt {
+		return StatusBlocked
+	}
+	return StatusFiltered
+}
+
+// FilterTrace is the decisions of the admission pipeline in order, ending at the first denial
+type FilterTrace []FilterDecision
+
+// Allowed checks if every check of the trace passed
+func (t FilterTrace) Allowed() bool {
+	_, denied := t.Denial()
+	return !denied
+}
+
+// Denial returns the decision denying the URL, if any
+func (t FilterTrace) Denial() (FilterDecision, bool) {
+	for _, decision := range t {
+		if !decision.Allowed {
+			return decision, true
+		}
+	}
+	return FilterDecision{}, false
+}
+
+// Admission is the dry run of the admission pipeline for a URL
+type Admission struct {
+	// URL is the stored URL when Known, and a new one otherwise
+	URL   *URL
+	Known bool
+	Trace FilterTrace
+}
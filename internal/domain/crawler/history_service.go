@@ -0,0 +1,118 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+)
+
+var (
+	// ErrInvalidDiff is returned when a diff request names an unknown granularity
+	ErrInvalidDiff = errors.New("invalid diff request")
+	// ErrVersionNotFound is returned when a diff request names a version that was not recorded
+	ErrVersionNotFound = errors.New("page version not found")
+)
+
+// HistoryService keeps the version history of pages and compares their versions
+type HistoryService struct {
+	versionRepo PageVersionRepository
+	notifier    ChangeNotifier
+}
+
+// NewHistoryService creates a new HistoryService, notifying notifier of the pages that appear,
+// change or disappear when it is not nil
+func NewHistoryService(versionRepo PageVersionRepository, notifier ChangeNotifier) *HistoryService {
+	return &HistoryService{
+		versionRepo: versionRepo,
+		notifier:    notifier,
+	}
+}
+
+// PageDiff is the difference between two versions of a page
+type PageDiff struct {
+	From  *PageVersion
+	To    *PageVersion
+	Edits []DiffEdit
+}
+
+// Record stores a new version of a fetched page when its content differs from its latest version,
+// reporting whether a version was stored
+func (s *HistoryService) Record(ctx context.Context, page *Page) result.Result[bool] {
+	var previous *PageVersion
+	next := 1
+	if latestResult := s.versionRepo.FindLatest(ctx, page.URL); latestResult.IsOk() {
+		previous = latestResult.Unwrap()
+		if previous.ContentHash == page.ContentHash() && previous.StatusCode == page.StatusCode {
+			return result.Ok(false)
+		}
+		next = previous.Version + 1
+	}
+
+	current := NewPageVersion(page, next)
+	if saveResult := s.versionRepo.Save(ctx, current); saveResult.IsErr() {
+		return result.Err[bool](fmt.Errorf("failed to save page version: %w", saveResult.Error()))
+	}
+
+	if kind, ok := changeKind(previous, current); ok && s.notifier != nil {
+		event := PageChangeEvent{Kind: kind, URL: page.URL, Previous: previous, Current: current}
+		if notifyResult := s.notifier.NotifyChange(ctx, event); notifyResult.IsErr() {
+			log.Printf("Failed to notify %s change of %s: %v", kind, page.URL, notifyResult.Error())
+		}
+	}
+
+	return result.Ok(true)
+}
+
+// changeKind classifies the change between two versions of a page, reporting false for changes
+// from or to server errors and other transient statuses
+func changeKind(previous, current *PageVersion) (ChangeKind, bool) {
+	switch {
+	case (previous == nil || previous.IsGone()) && current.IsAvailable():
+		return ChangeAppeared, true
+	case previous != nil && previous.IsAvailable() && current.IsAvailable():
+		return ChangeChanged, true
+	case previous != nil && previous.IsAvailable() && current.IsGone():
+		return ChangeDisappeared, true
+	default:
+		return "", false
+	}
+}
+
+// Versions returns up to limit versions of a URL, latest first
+func (s *HistoryService) Versions(ctx context.Context, url string, limit int) result.Result[[]PageVersion] {
+	return s.versionRepo.FindByURL(ctx, url, limit)
+}
+
+// Diff compares the plain text of two versions of a URL by line or word
+func (s *HistoryService) Diff(ctx context.Context, url string, from, to int, granularity DiffGranularity) result.Result[PageDiff] {
+	switch granularity {
+	case DiffLines, DiffWords:
+	default:
+		return result.Err[PageDiff](fmt.Errorf("%w: unknown granularity %q", ErrInvalidDiff, granularity))
+	}
+
+	fromResult := s.versionRepo.FindVersion(ctx, url, from)
+	if fromResult.IsErr() {
+		return result.Err[PageDiff](fmt.Errorf("%w: version %d of %s: %v", ErrVersionNotFound, from, url, fromResult.Error()))
+	}
+	toResult := s.versionRepo.FindVersion(ctx, url, to)
+	if toResult.IsErr() {
+		return result.Err[PageDiff](fmt.Errorf("%w: version %d of %s: %v", ErrVersionNotFound, to, url, toResult.Error()))
+	}
+
+	fromVersion, toVersion := fromResult.Unwrap(), toResult.Unwrap()
+	return result.Ok(PageDiff{
+		From:  fromVersion,
+		To:    toVersion,
+		Edits: Diff(fromVersion.PlainText, toVersion.PlainText, granularity),
+	})
+}
+
+// ChangesSince reports the pages of a domain created or changed since the given time
+func (s *HistoryService) ChangesSince(ctx context.Context, domain string, since time.Time, limit int) result.Result[[]PageChange] {
+	return s.versionRepo.FindChangesSince(ctx, domain, since, limit)
+}
@@ -0,0 +1,208 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+	"github.com/google/uuid"
+)
+
+// RecrawlService schedules revisits of fetched URLs from the rate at which their content changes
+type RecrawlService struct {
+	urlRepo         URLRepository
+	crawlJobRepo    CrawlJobRepository
+	minInterval     time.Duration
+	maxInterval     time.Duration
+	defaultInterval time.Duration
+	minRevisits     int
+	priority        int
+	maxRetries      int
+	retryDelay      time.Duration
+}
+
+// RecrawlServiceConfig configuration for the recrawl service
+type RecrawlServiceConfig struct {
+	// MinInterval and MaxInterval bound the recrawl interval of a URL, an hour and 30 days by default
+	MinInterval time.Duration
+	MaxInterval time.Duration
+	// DefaultInterval is the recrawl interval of URLs with too few revisits to estimate
+	// their change rate and no sitemap change frequency, a day by default
+	DefaultInterval time.Duration
+	// MinRevisits is the number of revisits from which the observed change rate is trusted
+	// over the sitemap change frequency, 3 by default
+	MinRevisits int
+	// Priority is the crawl job priority of recrawls
+	Priority int
+	// MaxRetries is the number of consecutive retryable failures retried with an exponential
+	// backoff from RetryDelay, 3 and a minute by default
+	MaxRetries int
+	RetryDelay time.Duration
+}
+
+// NewRecrawlService creates a new RecrawlService
+func NewRecrawlService(urlRepo URLRepository, crawlJobRepo CrawlJobRepository, config RecrawlServiceConfig) *RecrawlService {
+	minInterval := config.MinInterval
+	if minInterval <= 0 {
+		minInterval = time.Hour
+	}
+	maxInterval := config.MaxInterval
+	if maxInterval < minInterval {
+		maxInterval = max(30*24*time.Hour, minInterval)
+	}
+	defaultInterval := config.DefaultInterval
+	if defaultInterval <= 0 {
+		defaultInterval = 24 * time.Hour
+	}
+	minRevisits := config.MinRevisits
+	if minRevisits <= 0 {
+		minRevisits = 3
+	}
+	maxRetries := config.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = 3
+	}
+	retryDelay := config.RetryDelay
+	if retryDelay <= 0 {
+		retryDelay = time.Minute
+	}
+
+	return &RecrawlService{
+		urlRepo:         urlRepo,
+		crawlJobRepo:    crawlJobRepo,
+		minInterval:     minInterval,
+		maxInterval:     maxInterval,
+		defaultInterval: defaultInterval,
+		minRevisits:     minRevisits,
+		priority:        config.Priority,
+		maxRetries:      maxRetries,
+		retryDelay:      retryDelay,
+	}
+}
+
+// RecordFetch records whether the content of a fetched URL changed since its previous fetch
+// and schedules its next recrawl
+func (s *RecrawlService) RecordFetch(ctx context.Context, urlID uuid.UUID, page *Page) result.Result[*URL] {
+	urlResult := s.urlRepo.FindByID(ctx, urlID)
+	if urlResult.IsErr() {
+		return urlResult
+	}
+	url := urlResult.Unwrap()
+
+	url.RecordFetch(page.ContentHash(), page.FetchedAt)
+	url.Failures = 0
+	url.NextCrawlAt = page.FetchedAt.Add(s.nextInterval(url))
+
+	if updateResult := s.urlRepo.Update(ctx, url); updateResult.IsErr() {
+		return result.Err[*URL](fmt.Errorf("failed to schedule recrawl: %w", updateResult.Error()))
+	}
+
+	return result.Ok(url)
+}
+
+// RecordFailure records a failed fetch of a URL. Retryable failures are retried with an exponential
+// backoff up to the maximum retries, other failures are recrawled on the regular schedule.
+func (s *RecrawlService) RecordFailure(ctx context.Context, urlID uuid.UUID, fetchErr *FetchError) result.Result[*URL] {
+	urlResult := s.urlRepo.FindByID(ctx, urlID)
+	if urlResult.IsErr() {
+		return urlResult
+	}
+	url := urlResult.Unwrap()
+
+	now := time.Now()
+	url.Failures++
+	if fetchErr.Retryable && url.Failures <= s.maxRetries {
+		url.NextCrawlAt = now.Add(min(s.retryDelay<<(url.Failures-1), s.maxInterval))
+	} else {
+		url.NextCrawlAt = now.Add(s.nextInterval(url))
+	}
+
+	if updateResult := s.urlRepo.Update(ctx, url); updateResult.IsErr() {
+		return result.Err[*URL](fmt.Errorf("failed to schedule retry: %w", updateResult.Error()))
+	}
+
+	return result.Ok(url)
+}
+
+// Run enqueues due recrawls every tick until the context is done
+func (s *RecrawlService) Run(ctx context.Context, tick time.Duration, batchSize int) error {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		if enqueued := s.EnqueueDue(ctx, batchSize); enqueued.IsErr() {
+			log.Printf("Failed to enqueue recrawls: %v", enqueued.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// EnqueueDue enqueues up to limit URLs due to be recrawled and returns the number enqueued.
+// Their next recrawl is pushed back so that URLs failing to fetch are not enqueued every tick.
+func (s *RecrawlService) EnqueueDue(ctx context.Context, limit int) result.Result[int] {
+	now := time.Now()
+	urlsResult := s.urlRepo.FindDueForRecrawl(ctx, now, limit)
+	if urlsResult.IsErr() {
+		return result.Err[int](fmt.Errorf("failed to find due recrawls: %w", urlsResult.Error()))
+	}
+
+	enqueued := 0
+	for _, url := range urlsResult.Unwrap() {
+		if err := url.Transition(StatusPending, ReasonRecrawl, ""); err != nil {
+			log.Printf("Failed to schedule recrawl of %s: %v", url.URL, err)
+			continue
+		}
+		url.NextCrawlAt = now.Add(s.nextInterval(&url))
+		if updateResult := s.urlRepo.Update(ctx, &url); updateResult.IsErr() {
+			log.Printf("Failed to schedule recrawl of %s: %v", url.URL, updateResult.Error())
+			continue
+		}
+
+		if enqueueResult := s.crawlJobRepo.Enqueue(ctx, NewCrawlJob(&url, s.priority)); enqueueResult.IsErr() {
+			log.Printf("Failed to enqueue recrawl of %s: %v", url.URL, enqueueResult.Error())
+			continue
+		}
+		enqueued++
+	}
+
+	return result.Ok(enqueued)
+}
+
+// Upcoming counts the recrawls scheduled within the given period by domain
+func (s *RecrawlService) Upcoming(ctx context.Context, within time.Duration) result.Result[[]DomainRecrawls] {
+	return s.urlRepo.CountRecrawlsByDomain(ctx, time.Now().Add(within))
+}
+
+// nextInterval returns the recrawl interval of a URL: the mean interval between changes once it has
+// been revisited enough, the change frequency declared by its sitemap until then, bounded by the
+// minimum and maximum intervals
+func (s *RecrawlService) nextInterval(url *URL) time.Duration {
+	interval := s.defaultInterval
+	rate, ok := url.ChangeRate()
+
+	switch {
+	case ok && url.Revisits >= s.minRevisits:
+		if rate > 0 {
+			interval = time.Duration(float64(time.Second) / rate)
+		} else {
+			interval = s.maxInterval
+		}
+	case url.ChangeFrequency == ChangeNever:
+		interval = s.maxInterval
+	case url.ChangeFrequency.Interval() > 0:
+		interval = url.ChangeFrequency.Interval()
+	}
+
+	// Very small rates overflow to negative durations
+	if interval <= 0 {
+		interval = s.maxInterval
+	}
+	return min(max(interval, s.minInterval), s.maxInterval)
+}
@@ -2,6 +2,7 @@ package crawler
 
 import (
 	"context"
+	"time"
 
 	result "github.com/gerthdala/webcrawler/pkg/utils/result"
 	"github.com/google/uuid"
@@ -17,8 +18,9 @@ type URLRepository interface {
 	// FindPending finds URLs with pending status, with limit
 	FindPending(ctx context.Context, limit int) result.Result[[]URL]
 
-	// UpdateStatus updates the status of a URL
-	UpdateStatus(ctx context.Context, id uuid.UUID, status Status) result.Result[*URL]
+	// UpdateStatus moves a URL from a status to another with its reason code and detail,
+	// failing with ErrInvalidTransition when the URL is no longer in the from status
+	UpdateStatus(ctx context.Context, id uuid.UUID, from, status Status, reason, detail string) result.Result[*URL]
 	
 	// IncrementAttemptCount increments the attempt count of a URL
 	IncrementAttemptCount(ctx context.Context, id uuid.UUID) result.Result[*URL]
@@ -28,18 +30,48 @@ type URLRepository interface {
 	
 	// CountByStatus counts URLs by status
 	CountByStatus(ctx context.Context, status Status) result.Result[int]
+
+	// CountByStatusAndReason counts URLs by status and reason code, by status then most URLs first
+	CountByStatusAndReason(ctx context.Context) result.Result[[]StatusCount]
 	
 	// DeleteOlderThan deletes URLs older than the given duration
 	DeleteOlderThan(ctx context.Context, days int) result.Result[int]
+
+	// Update updates a stored URL
+	Update(ctx context.Context, url *URL) result.Result[*URL]
+
+	// FindDueForRecrawl finds crawled URLs due to be recrawled at the given time, most overdue first
+	FindDueForRecrawl(ctx context.Context, now time.Time, limit int) result.Result[[]URL]
+
+	// CountRecrawlsByDomain counts the recrawls scheduled before the given time by domain, most recrawls first
+	CountRecrawlsByDomain(ctx context.Context, before time.Time) result.Result[[]DomainRecrawls]
+}
+
+// FetchErrorRepository counts fetch errors by host and kind
+type FetchErrorRepository interface {
+	// Record counts a fetch error of a host
+	Record(ctx context.Context, host string, fetchErr *FetchError, at time.Time) result.Result[bool]
+
+	// FindByHost finds the fetch error counts of a host, most errors first
+	FindByHost(ctx context.Context, host string) result.Result[[]HostFetchErrors]
+
+	// FindTop finds the fetch error counts with the most errors
+	FindTop(ctx context.Context, limit int) result.Result[[]HostFetchErrors]
 }
 
 // PageRepository handles Page storage and retrieval
 type PageRepository interface {
-	// Save stores a Page
+	// Save stores a Page, updating the stored Page with the same URL in place
 	Save(ctx context.Context, page *Page) result.Result[*Page]
+
+	// MarkNotModified records that a stored Page was fetched again unchanged
+	MarkNotModified(ctx context.Context, id uuid.UUID, fetchedAt time.Time) result.Result[*Page]
 	
 	// FindByID finds a Page by its ID
 	FindByID(ctx context.Context, id uuid.UUID) result.Result[*Page]
+
+	// FindByIDs finds the stored Pages among the given IDs, in no particular order
+	FindByIDs(ctx context.Context, ids []uuid.UUID) result.Result[[]Page]
 	
 	// FindByURL finds a Page by its URL
 	FindByURL(ctx context.Context, url string) result.Result[*Page]
@@ -53,10 +85,50 @@ type PageRepository interface {
 	// Search searches pages by content
 	Search(ctx context.Context, query string, limit int) result.Result[[]Page]
 	
+	// FindIDsOlderThan finds the IDs of the pages DeleteOlderThan deletes
+	FindIDsOlderThan(ctx context.Context, days int) result.Result[[]uuid.UUID]
+
 	// DeleteOlderThan deletes pages older than the given duration
 	DeleteOlderThan(ctx context.Context, days int) result.Result[int]
 }
 
+// PageVersionRepository handles PageVersion storage and retrieval
+type PageVersionRepository interface {
+	// Save stores a PageVersion
+	Save(ctx context.Context, version *PageVersion) result.Result[*PageVersion]
+
+	// FindLatest finds the latest PageVersion of a URL
+	FindLatest(ctx context.Context, url string) result.Result[*PageVersion]
+
+	// FindByURL finds the PageVersions of a URL, latest first
+	FindByURL(ctx context.Context, url string, limit int) result.Result[[]PageVersion]
+
+	// FindVersion finds a PageVersion of a URL by its number
+	FindVersion(ctx context.Context, url string, version int) result.Result[*PageVersion]
+
+	// FindChangesSince summarizes the versions of the pages of a domain fetched since the given time,
+	// most recently changed first
+	FindChangesSince(ctx context.Context, domain string, since time.Time, limit int) result.Result[[]PageChange]
+}
+
+// FeedRepository handles Feed and FeedItem storage and retrieval
+type FeedRepository interface {
+	// Save stores a Feed
+	Save(ctx context.Context, feed *Feed) result.Result[*Feed]
+
+	// Update updates a stored Feed
+	Update(ctx context.Context, feed *Feed) result.Result[*Feed]
+
+	// FindByURL finds a Feed by its URL
+	FindByURL(ctx context.Context, url string) result.Result[*Feed]
+
+	// FindDue finds Feeds due to be polled at the given time, most overdue first
+	FindDue(ctx context.Context, now time.Time, limit int) result.Result[[]Feed]
+
+	// SaveItems stores the items of a Feed and returns the items that were not stored before
+	SaveItems(ctx context.Context, feedID uuid.UUID, items []FeedItem) result.Result[[]FeedItem]
+}
+
 // CrawlJobRepository handles CrawlJob queue operations
 type CrawlJobRepository interface {
 	// Enqueue adds a job to the queue
@@ -2,15 +2,26 @@ package crawler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
 	"time"
 
 	result "github.com/gerthdala/webcrawler/pkg/utils/result"
 )
 
+// ErrRedirectTargetFetching is returned when a redirect ends at a URL another fetch is processing,
+// which stores the page of that URL
+var ErrRedirectTargetFetching = errors.New("redirect target is being fetched")
+
 type FetcherService interface {
 	// Fetch fetches a URL and returns the page content
 	Fetch(ctx context.Context, url *URL) result.Result[*Page]
+
+	// FetchIfModified fetches a URL conditionally on the validators of its previous fetch.
+	// An unchanged page is returned without body, with a 304 Not Modified status code.
+	FetchIfModified(ctx context.Context, url *URL, validators Validators) result.Result[*Page]
 }
 
 type ParserService interface {
@@ -26,10 +37,24 @@ type ParserService interface {
 }
 
 type URLFilterService interface {
-	ShouldCrawl(ctx context.Context, url string, depth int) result.Result[bool]
+	// ShouldCrawl checks if a URL reached after offDomainHops consecutive links outside the allowed domains should be crawled
+	ShouldCrawl(ctx context.Context, url string, depth, offDomainHops int) result.Result[bool]
 
 	IsAllowedDomain(ctx context.Context, url string) result.Result[bool]
 
+	// OffDomainHops returns how many consecutive links outside the allowed domains lead to a URL
+	// linked from a page reached after parentHops of them
+	OffDomainHops(ctx context.Context, url string, parentHops int) result.Result[int]
+
+	// Explain runs the checks of ShouldCrawl in order and returns their decisions, up to the first denial
+	Explain(ctx context.Context, url string, depth, offDomainHops int) FilterTrace
+
+	// Rules returns the include and exclude rules in use
+	Rules() FilterRules
+
+	// UpdateRules validates and replaces the include and exclude rules at once
+	UpdateRules(rules FilterRules) result.Result[bool]
+
 	IsAllowedContentType(cxt context.Context, contentType string) result.Result[bool]
 }
 
@@ -45,6 +70,28 @@ type RobotsTxtService interface {
 	GetCrawlDelay(ctx context.Context, domain string, userAgent string) result.Result[time.Duration]
 }
 
+// SitemapService discovers and reads sitemaps
+type SitemapService interface {
+	// DiscoverSitemaps returns the sitemaps of a site, declared in its robots.txt or at /sitemap.xml
+	DiscoverSitemaps(ctx context.Context, siteURL string) result.Result[[]string]
+
+	// ReadSitemap streams the entries of a sitemap to fn, following sitemap indexes,
+	// and returns the number of entries read
+	ReadSitemap(ctx context.Context, sitemapURL string, fn func(entry SitemapEntry) error) result.Result[int]
+}
+
+// FeedFetcherService fetches and parses RSS and Atom feeds
+type FeedFetcherService interface {
+	// FetchFeed fetches a feed, conditionally on the validators of its last poll
+	FetchFeed(ctx context.Context, feed *Feed) result.Result[*FeedFetch]
+}
+
+// ChangeNotifier is notified when pages appear, change or disappear
+type ChangeNotifier interface {
+	// NotifyChange handles a change of a page
+	NotifyChange(ctx context.Context, event PageChangeEvent) result.Result[int]
+}
+
 // CrawlService orchestrates the crawling process
 type CrawlService struct {
 	urlRepo         URLRepository
@@ -54,10 +101,16 @@ type CrawlService struct {
 	parser          ParserService
 	filter          URLFilterService
 	robotsTxt       RobotsTxtService
+	sitemaps        SitemapService
+	feeds           *FeedService
+	recrawl         *RecrawlService
+	history         *HistoryService
+	fetchErrors     FetchErrorRepository
 	maxDepth        int
 	concurrency     int
 	politenessDelay time.Duration
 	userAgent       string
+	followDuplicateLinks bool
 }
 
 // CrawlServiceConfig configuration for the crawl service
@@ -66,6 +119,8 @@ type CrawlServiceConfig struct {
 	Concurrency    int
 	PolitenessDelay time.Duration
 	UserAgent      string
+	// FollowDuplicateLinks follows the links of pages mirroring an already crawled canonical page
+	FollowDuplicateLinks bool
 }
 
 // NewCrawlService creates a new CrawlService
@@ -77,6 +132,11 @@ func NewCrawlService(
 	parser ParserService,
 	filter URLFilterService,
 	robotsTxt RobotsTxtService,
+	sitemaps SitemapService,
+	feeds *FeedService,
+	recrawl *RecrawlService,
+	history *HistoryService,
+	fetchErrors FetchErrorRepository,
 	config CrawlServiceConfig,
 ) *CrawlService {
 	return &CrawlService{
@@ -87,10 +147,16 @@ func NewCrawlService(
 		parser:         parser,
 		filter:         filter,
 		robotsTxt:      robotsTxt,
+		sitemaps:       sitemaps,
+		feeds:          feeds,
+		recrawl:        recrawl,
+		history:        history,
+		fetchErrors:    fetchErrors,
 		maxDepth:       config.MaxDepth,
 		concurrency:    config.Concurrency,
 		politenessDelay: config.PolitenessDelay,
 		userAgent:      config.UserAgent,
+		followDuplicateLinks: config.FollowDuplicateLinks,
 	}
 }
 
@@ -124,58 +190,435 @@ func (s *CrawlService) AddSeed(ctx context.Context, rawURL string) result.Result
 	return saveURL
 }
 
-// ProcessedURL processes a single url
-func (s *CrawlService) ProcessedURL(ctx context.Context, url *URL) result.Result[*Page] {
-	// Check if the url should crawled
-	shouldCrawl := s.filter.ShouldCrawl(ctx, url.URL, url.Depth)
-	if shouldCrawl.IsErr() || !shouldCrawl.Unwrap() {
-		if shouldCrawl.IsErr() {
-			return result.Err[*Page](shouldCrawl.Error())
+// AddSitemapSeeds adds the URLs listed in the sitemaps of a site as seeds,
+// prioritized and scheduled for recrawl from their sitemap metadata
+func (s *CrawlService) AddSitemapSeeds(ctx context.Context, siteURL string) result.Result[int] {
+	sitemapsResult := s.sitemaps.DiscoverSitemaps(ctx, siteURL)
+	if sitemapsResult.IsErr() {
+		return result.Err[int](fmt.Errorf("failed to discover sitemaps: %w", sitemapsResult.Error()))
+	}
+
+	added := 0
+	for _, sitemapURL := range sitemapsResult.Unwrap() {
+		readResult := s.sitemaps.ReadSitemap(ctx, sitemapURL, func(entry SitemapEntry) error {
+			if s.addSitemapEntry(ctx, entry) {
+				added++
+			}
+			return ctx.Err()
+		})
+		if readResult.IsErr() {
+			log.Printf("Failed to read sitemap %s: %v", sitemapURL, readResult.Error())
 		}
+	}
+
+	return result.Ok(added)
+}
 
-		return result.ErrMsg[*Page]("URL should not be crawled")
+// addSitemapEntry saves and enqueues the URL of a sitemap entry, reporting whether it was new.
+// A known URL is updated with the entry metadata, which schedules its recrawl when modified since its last fetch.
+func (s *CrawlService) addSitemapEntry(ctx context.Context, entry SitemapEntry) bool {
+	urlResult := NewURL(entry.Loc, 0, entry.Sitemap)
+	if urlResult.IsErr() {
+		log.Printf("Skipping malformed sitemap URL: %s (error: %v)", entry.Loc, urlResult.Error())
+		return false
 	}
+	url := urlResult.Unwrap()
 
-	// Check robots.txt
-	isAllowedResult := s.robotsTxt.IsAllowed(ctx, url.URL, s.userAgent)
-	if isAllowedResult.IsErr() || !isAllowedResult.Unwrap() {
-		if isAllowedResult.IsErr() {
-			return result.Err[*Page](isAllowedResult.Error())
+	if shouldCrawl := s.filter.ShouldCrawl(ctx, url.URL, 0, 0); shouldCrawl.IsErr() || !shouldCrawl.Unwrap() {
+		return false
+	}
+	if existing := s.urlRepo.FindByNormalizedURL(ctx, url.NormalizedURL); existing.IsOk() {
+		known := existing.Unwrap()
+		known.ApplySitemapEntry(entry)
+		if updateResult := s.urlRepo.Update(ctx, known); updateResult.IsErr() {
+			log.Printf("Failed to update sitemap metadata of %s: %v", known.URL, updateResult.Error())
 		}
-		return result.ErrMsg[*Page]("URL not allowed by robots.txt")
+		return false
 	}
 
-	s.urlRepo.UpdateStatus(ctx, url.ID, StatusFetching)
+	url.ApplySitemapEntry(entry)
+	if saveResult := s.urlRepo.Save(ctx, url); saveResult.IsErr() {
+		return false
+	}
+
+	s.crawlJobRepo.Enqueue(ctx, NewCrawlJob(url, entry.JobPriority()))
+	return true
+}
+
+// ProcessedURL processes a single url
+func (s *CrawlService) ProcessedURL(ctx context.Context, url *URL) result.Result[*Page] {
+	// Check if the url should crawled, recording why it should not
+	admitResult := s.admit(ctx, url)
+	if admitResult.IsErr() {
+		return result.Err[*Page](admitResult.Error())
+	}
+	if denial, denied := admitResult.Unwrap().Denial(); denied {
+		s.setStatus(ctx, url, denial.Status(), string(denial.Check), denial.Reason)
+		return result.Err[*Page](fmt.Errorf("%w: %s", ErrURLNotAllowed, denial))
+	}
+
+	// Claiming the URL fails when another worker claimed it first
+	if err := s.updateStatus(ctx, url, StatusFetching, "", ""); err != nil {
+		return result.Err[*Page](fmt.Errorf("failed to claim %s: %w", url.URL, err))
+	}
 	s.urlRepo.IncrementAttemptCount(ctx, url.ID)
 
 
+	// Revisits are fetched conditionally on the validators of the stored page
+	var previous *Page
+	if previousResult := s.pageRepo.FindByURL(ctx, url.URL); previousResult.IsOk() {
+		previous = previousResult.Unwrap()
+	}
+
 	// Fetch URL status
-	fetchResult := s.fetcher.Fetch(ctx, url)
+	var fetchResult result.Result[*Page]
+	if previous != nil && !previous.Validators().IsZero() {
+		fetchResult = s.fetcher.FetchIfModified(ctx, url, previous.Validators())
+	} else {
+		fetchResult = s.fetcher.Fetch(ctx, url)
+	}
 	if fetchResult.IsErr() {
-		s.urlRepo.UpdateStatus(ctx, url.ID, StatusFailed)
+		var fetchErr *FetchError
+		if !errors.As(fetchResult.Error(), &fetchErr) {
+			fetchErr = NewFetchError(FetchErrorUnknown, url.URL, fetchResult.Error())
+		}
+		// A content type the filter does not allow is not a failure, the URL is simply not HTML
+		if fetchErr.Kind == FetchErrorContentType {
+			s.setStatus(ctx, url, StatusNonHTML, ReasonContentType, fetchErr.Err.Error())
+			return fetchResult
+		}
+		s.setStatus(ctx, url, StatusFailed, string(fetchErr.Kind), fetchErr.Err.Error())
+		s.recordFailure(ctx, url, fetchErr)
 		return fetchResult
 	}
 
 	page := fetchResult.Unwrap()
 
+	// An unchanged page keeps its stored body and links
+	if page.IsNotModified() && previous != nil {
+		unchangedResult := s.pageRepo.MarkNotModified(ctx, previous.ID, page.FetchedAt)
+		if unchangedResult.IsErr() {
+			return result.Err[*Page](unchangedResult.Error())
+		}
+		reason := ReasonLastModified
+		if previous.Validators().ETag != "" {
+			reason = ReasonETag
+		}
+		s.setStatus(ctx, url, StatusNotModified, reason, "")
+		s.scheduleRecrawl(ctx, url, unchangedResult.Unwrap())
+		return unchangedResult
+	}
+
+	page.FeedItem = url.FeedItem
+
+	// A redirected fetch stores the page for the URL the redirects ended at, unless another
+	// fetch is processing that URL, leaving the requested URL marked redirected
+	if page.IsRedirected() {
+		targetResult := s.redirectTarget(ctx, url, page)
+		if targetResult.IsErr() {
+			return result.Err[*Page](targetResult.Error())
+		}
+		url = targetResult.Unwrap()
+	}
+
+	// Extract links, text and metadata from HTML pages
+	if page.IsHTML() {
+		if parseResult := s.parser.Parse(ctx, page); parseResult.IsErr() {
+			log.Printf("Failed to parse %s: %v", page.URL, parseResult.Error())
+		}
+	}
+	s.resolveCanonical(ctx, url, page)
+
 	if saveResult := s.pageRepo.Save(ctx, page); saveResult.IsErr() {
 		return result.Err[*Page](saveResult.Error())
 	}
 
 	//Update URL status
-	s.urlRepo.UpdateStatus(ctx, url.ID, StatusFetched)
+	status, reason, detail := fetchedStatus(page)
+	s.setStatus(ctx, url, status, reason, detail)
+	if fetchErr := page.FetchError(); fetchErr != nil {
+		s.recordFailure(ctx, url, fetchErr)
+	} else {
+		s.scheduleRecrawl(ctx, url, page)
+	}
+	if page.Truncated {
+		s.recordTruncation(ctx, url, page)
+	}
+
+	// Keep the previous content of changed pages
+	if s.history != nil {
+		if recordResult := s.history.Record(ctx, page); recordResult.IsErr() {
+			log.Printf("Failed to record version of %s: %v", page.URL, recordResult.Error())
+		}
+	}
+
+	// Register the feeds the page links to
+	if s.feeds != nil && len(page.Feeds) > 0 {
+		if discoverResult := s.feeds.DiscoverFeeds(ctx, page); discoverResult.IsErr() {
+			log.Printf("Failed to register feeds of %s: %v", page.URL, discoverResult.Error())
+		}
+	}
 
 	// Process links if depth is allowed
-	if url.Depth < s.maxDepth {
-		s.processLinks(ctx, page.Links, url.Depth + 1, url.URL)
+	if url.Depth < s.maxDepth && (!page.IsDuplicate() || s.followDuplicateLinks) {
+		s.processLinks(ctx, page.Links, url)
 	}
 
 	return result.Ok(page)
 }
 
-func (s *CrawlService) processLinks(ctx context.Context, links []string, depth int, parentURL string) {
+// redirectTarget marks a URL redirected to the URL its page ended at, and returns the record of that URL
+// admitted and fetching, so that the page is stored for it and it is not fetched again.
+// It fails with ErrRedirectTargetFetching when another fetch is processing the target.
+func (s *CrawlService) redirectTarget(ctx context.Context, url *URL, page *Page) result.Result[*URL] {
+	targetResult := NewURL(page.URL, url.Depth, url.URL)
+	if targetResult.IsErr() {
+		return result.Err[*URL](fmt.Errorf("failed to parse redirect target: %w", targetResult.Error()))
+	}
+	target := targetResult.Unwrap()
+	if target.NormalizedURL == url.NormalizedURL {
+		return result.Ok(url)
+	}
+
+	s.setStatus(ctx, url, StatusRedirected, ReasonRedirect, page.URL)
+
+	if existing := s.urlRepo.FindByNormalizedURL(ctx, target.NormalizedURL); existing.IsOk() {
+		target = existing.Unwrap()
+	} else {
+		if hops := s.filter.OffDomainHops(ctx, target.URL, url.OffDomainHops); hops.IsOk() {
+			target.OffDomainHops = hops.Unwrap()
+		}
+		if saveResult := s.urlRepo.Save(ctx, target); saveResult.IsErr() {
+			return result.Err[*URL](fmt.Errorf("failed to save redirect target: %w", saveResult.Error()))
+		}
+	}
+
+	// A target being fetched already is left to that fetch to store and update
+	if target.Status == StatusFetching {
+		return result.Err[*URL](fmt.Errorf("%w: %s", ErrRedirectTargetFetching, target.URL))
+	}
+	if target.Status != StatusPending {
+		if err := s.updateStatus(ctx, target, StatusPending, ReasonRedirect, url.URL); err != nil {
+			return result.Err[*URL](fmt.Errorf("failed to requeue redirect target: %w", err))
+		}
+	}
+
+	admitResult := s.admit(ctx, target)
+	if admitResult.IsErr() {
+		return result.Err[*URL](admitResult.Error())
+	}
+	if denial, denied := admitResult.Unwrap().Denial(); denied {
+		s.setStatus(ctx, target, denial.Status(), string(denial.Check), denial.Reason)
+		return result.Err[*URL](fmt.Errorf("%w: redirect target %s: %s", ErrURLNotAllowed, target.URL, denial))
+	}
+
+	if err := s.updateStatus(ctx, target, StatusFetching, ReasonRedirect, url.URL); err != nil {
+		return result.Err[*URL](fmt.Errorf("failed to claim redirect target: %w", err))
+	}
+	return result.Ok(target)
+}
+
+// fetchedStatus returns the status of a URL fetched as page, with its reason and detail
+func fetchedStatus(page *Page) (Status, string, string) {
+	switch {
+	case page.StatusCode >= 300 && page.StatusCode < 400:
+		return StatusRedirected, ReasonRedirect, fmt.Sprintf("%d to %s", page.StatusCode, page.Headers["Location"])
+	case page.FetchError() != nil:
+		return StatusFailed, string(page.FetchError().Kind), fmt.Sprintf("%d %s", page.StatusCode, http.StatusText(page.StatusCode))
+	case page.IsDuplicate():
+		return StatusDuplicate, ReasonCanonical, page.DuplicateOf
+	case !page.IsHTML():
+		return StatusNonHTML, ReasonContentType, page.ContentType
+	case page.Truncated:
+		return StatusFetched, ReasonTruncated, fmt.Sprintf("body cut to %d bytes", len(page.HTML))
+	}
+	return StatusFetched, "", ""
+}
+
+// setStatus moves a URL to a new status and stores it, logging the transitions that fail
+func (s *CrawlService) setStatus(ctx context.Context, url *URL, status Status, reason, detail string) {
+	if err := s.updateStatus(ctx, url, status, reason, detail); err != nil {
+		log.Printf("Failed to update status of %s: %v", url.URL, err)
+	}
+}
+
+// updateStatus moves a URL to a new status and stores it, failing with ErrInvalidTransition when its
+// lifecycle does not allow the transition or its stored status changed since it was read
+func (s *CrawlService) updateStatus(ctx context.Context, url *URL, status Status, reason, detail string) error {
+	previous := *url
+	if err := url.Transition(status, reason, detail); err != nil {
+		return err
+	}
+	if updateResult := s.urlRepo.UpdateStatus(ctx, url.ID, previous.Status, status, reason, detail); updateResult.IsErr() {
+		url.Status, url.StatusReason, url.StatusDetail, url.UpdatedAt =
+			previous.Status, previous.StatusReason, previous.StatusDetail, previous.UpdatedAt
+		return updateResult.Error()
+	}
+	return nil
+}
+
+// Stats returns the number of URLs by status and reason
+func (s *CrawlService) Stats(ctx context.Context) result.Result[[]StatusCount] {
+	breakdownResult := s.urlRepo.CountByStatusAndReason(ctx)
+	if breakdownResult.IsErr() {
+		return result.Err[[]StatusCount](fmt.Errorf("failed to count URLs by status: %w", breakdownResult.Error()))
+	}
+	return breakdownResult
+}
+
+// Explain dry-runs the admission pipeline for a URL. The stored URL is checked when known,
+// otherwise a new one at the given depth and off-domain hops, negative values defaulting to a seed's.
+func (s *CrawlService) Explain(ctx context.Context, rawURL string, depth, offDomainHops int) result.Result[*Admission] {
+	urlResult := NewURL(rawURL, max(depth, 0), "")
+	if urlResult.IsErr() {
+		return result.Err[*Admission](fmt.Errorf("failed to parse URL: %w", urlResult.Error()))
+	}
+
+	admission := &Admission{URL: urlResult.Unwrap()}
+	if existing := s.urlRepo.FindByNormalizedURL(ctx, admission.URL.NormalizedURL); existing.IsOk() {
+		admission.URL = existing.Unwrap()
+		admission.Known = true
+	}
+	if depth >= 0 {
+		admission.URL.Depth = depth
+	}
+	if offDomainHops >= 0 {
+		admission.URL.OffDomainHops = offDomainHops
+	}
+
+	traceResult := s.admit(ctx, admission.URL)
+	if traceResult.IsErr() {
+		return result.Err[*Admission](traceResult.Error())
+	}
+	admission.Trace = traceResult.Unwrap()
+
+	return result.Ok(admission)
+}
+
+// admit runs the admission pipeline for a URL: the filter checks, then its depth and robots.txt,
+// up to the first denial
+func (s *CrawlService) admit(ctx context.Context, url *URL) result.Result[FilterTrace] {
+	trace := s.filter.Explain(ctx, url.URL, url.Depth, url.OffDomainHops)
+	if !trace.Allowed() {
+		return result.Ok(trace)
+	}
+
+	if url.Depth > s.maxDepth {
+		return result.Ok(append(trace, Deny(CheckDepth, "depth %d exceeds the maximum depth of %d", url.Depth, s.maxDepth)))
+	}
+	trace = append(trace, Allow(CheckDepth, "depth %d is within the maximum depth of %d", url.Depth, s.maxDepth))
+
+	if s.robotsTxt == nil {
+		return result.Ok(append(trace, Allow(CheckRobotsTxt, "robots.txt is not checked")))
+	}
+	isAllowedResult := s.robotsTxt.IsAllowed(ctx, url.URL, s.userAgent)
+	if isAllowedResult.IsErr() {
+		return result.Err[FilterTrace](fmt.Errorf("failed to check robots.txt: %w", isAllowedResult.Error()))
+	}
+	if !isAllowedResult.Unwrap() {
+		return result.Ok(append(trace, Deny(CheckRobotsTxt, "robots.txt disallows %s for user agent %q", url.URL, s.userAgent)))
+	}
+
+	return result.Ok(append(trace, Allow(CheckRobotsTxt, "robots.txt allows the URL")))
+}
+
+// resolveCanonical marks a page whose canonical URL was already crawled as its duplicate,
+// and enqueues the canonical URL when it is not known yet
+func (s *CrawlService) resolveCanonical(ctx context.Context, url *URL, page *Page) {
+	if page.CanonicalURL == "" {
+		return
+	}
+
+	canonicalResult := NewURL(page.CanonicalURL, url.Depth, url.URL)
+	if canonicalResult.IsErr() {
+		return
+	}
+	canonical := canonicalResult.Unwrap()
+	if canonical.NormalizedURL == url.NormalizedURL {
+		return
+	}
+	hops := s.filter.OffDomainHops(ctx, canonical.URL, url.OffDomainHops)
+	if hops.IsErr() {
+		return
+	}
+	canonical.OffDomainHops = hops.Unwrap()
+
+	if existing := s.urlRepo.FindByNormalizedURL(ctx, canonical.NormalizedURL); existing.IsOk() {
+		if crawled := existing.Unwrap(); crawled.Status.HasContent() || !crawled.LastFetchedAt.IsZero() {
+			page.MarkDuplicateOf(crawled.URL)
+		}
+		return
+	}
+
+	if shouldCrawl := s.filter.ShouldCrawl(ctx, canonical.URL, canonical.Depth, canonical.OffDomainHops); shouldCrawl.IsErr() || !shouldCrawl.Unwrap() {
+		return
+	}
+	if saveResult := s.urlRepo.Save(ctx, canonical); saveResult.IsErr() {
+		return
+	}
+	s.crawlJobRepo.Enqueue(ctx, NewCrawlJob(canonical, canonical.Depth))
+}
+
+// scheduleRecrawl records whether a fetched page changed and schedules the recrawl of its URL
+func (s *CrawlService) scheduleRecrawl(ctx context.Context, url *URL, page *Page) {
+	if s.recrawl == nil {
+		return
+	}
+	if recordResult := s.recrawl.RecordFetch(ctx, url.ID, page); recordResult.IsErr() {
+		log.Printf("Failed to schedule recrawl of %s: %v", url.URL, recordResult.Error())
+	}
+}
+
+// recordFailure counts a fetch error for the host of a URL and schedules the retry of the URL
+func (s *CrawlService) recordFailure(ctx context.Context, url *URL, fetchErr *FetchError) {
+	if s.fetchErrors != nil {
+		if recordResult := s.fetchErrors.Record(ctx, url.Host(), fetchErr, time.Now()); recordResult.IsErr() {
+			log.Printf("Failed to record fetch error of %s: %v", url.URL, recordResult.Error())
+		}
+	}
+
+	if s.recrawl != nil {
+		if retryResult := s.recrawl.RecordFailure(ctx, url.ID, fetchErr); retryResult.IsErr() {
+			log.Printf("Failed to schedule retry of %s: %v", url.URL, retryResult.Error())
+		}
+	}
+}
+
+// recordTruncation counts a body cut to the maximum size for the host of a URL, without retrying the URL
+func (s *CrawlService) recordTruncation(ctx context.Context, url *URL, page *Page) {
+	if s.fetchErrors == nil {
+		return
+	}
+	fetchErr := NewFetchError(FetchErrorBodyTooLarge, url.URL, fmt.Errorf("body cut to %d bytes", len(page.HTML)))
+	fetchErr.StatusCode = page.StatusCode
+	if recordResult := s.fetchErrors.Record(ctx, url.Host(), fetchErr, time.Now()); recordResult.IsErr() {
+		log.Printf("Failed to record truncation of %s: %v", url.URL, recordResult.Error())
+	}
+}
+
+// FetchErrors returns the fetch error counts of a host by kind, or those of the hosts with the most
+// errors when host is empty
+func (s *CrawlService) FetchErrors(ctx context.Context, host string, limit int) result.Result[[]HostFetchErrors] {
+	if s.fetchErrors == nil {
+		return result.Ok([]HostFetchErrors{})
+	}
+
+	var countsResult result.Result[[]HostFetchErrors]
+	if host != "" {
+		countsResult = s.fetchErrors.FindByHost(ctx, host)
+	} else {
+		countsResult = s.fetchErrors.FindTop(ctx, limit)
+	}
+	if countsResult.IsErr() {
+		return result.Err[[]HostFetchErrors](fmt.Errorf("failed to find fetch errors: %w", countsResult.Error()))
+	}
+	return countsResult
+}
+
+func (s *CrawlService) processLinks(ctx context.Context, links []string, parent *URL) {
+	depth := parent.Depth + 1
 	for _, link := range links {
-		urlR := NewURL(link, depth, parentURL)
+		urlR := NewURL(link, depth, parent.URL)
 
 		if urlR.IsErr() {
 			log.Printf("Skipping malformed URL: %s (error: %v)", link, urlR.Error())
@@ -184,6 +627,16 @@ func (s *CrawlService) processLinks(ctx context.Context, links []string, depth i
 
 		url := urlR.Unwrap()
 
+		// Links leaving the allowed domains are only followed up to the off-domain hop limit
+		hopsResult := s.filter.OffDomainHops(ctx, url.URL, parent.OffDomainHops)
+		if hopsResult.IsErr() {
+			continue
+		}
+		url.OffDomainHops = hopsResult.Unwrap()
+		if shouldCrawl := s.filter.ShouldCrawl(ctx, url.URL, depth, url.OffDomainHops); shouldCrawl.IsErr() || !shouldCrawl.Unwrap() {
+			continue
+		}
+
 		// Check if URL already exists
 		existingResult := s.urlRepo.FindByNormalizedURL(ctx, url.NormalizedURL)
 		if existingResult.IsOk() {
@@ -0,0 +1,94 @@
+package crawler
+
+import "errors"
+
+// ErrInvalidTransition is returned when moving a URL to a status its lifecycle does not allow
+var ErrInvalidTransition = errors.New("invalid URL status transition")
+
+// Status represents the status of a URL in the crawling process
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusFetching Status = "fetching"
+	StatusFetched  Status = "fetched"
+	StatusFailed   Status = "failed"
+	// StatusFiltered is a URL denied by the URL filter or the maximum depth
+	StatusFiltered Status = "filtered"
+	// StatusBlocked is a URL disallowed by robots.txt
+	StatusBlocked Status = "blocked"
+	// StatusRedirected is a URL answering with a redirect
+	StatusRedirected Status = "redirected"
+	// StatusNotModified is a URL fetched again unchanged since its previous fetch
+	StatusNotModified Status = "not_modified"
+	// StatusDuplicate is a URL whose page mirrors an already crawled canonical page
+	StatusDuplicate Status = "duplicate"
+	// StatusNonHTML is a URL fetched with content that is not HTML
+	StatusNonHTML Status = "non_html"
+)
+
+// Reason codes of the statuses that are not given by a FilterCheck or a FetchErrorKind
+const (
+	ReasonRedirect     = "redirect"
+	ReasonETag         = "etag"
+	ReasonLastModified = "last_modified"
+	ReasonCanonical    = "canonical"
+	ReasonContentType  = "content_type"
+	ReasonRecrawl      = "recrawl"
+	ReasonTruncated    = "truncated"
+)
+
+// crawledStatuses are the statuses of URLs whose fetch completed, from which they are recrawled
+var crawledStatuses = []Status{StatusFetched, StatusFailed, StatusRedirected, StatusNotModified, StatusDuplicate, StatusNonHTML}
+
+// transitions are the statuses each status can move to
+var transitions = map[Status][]Status{
+	StatusPending:  {StatusFetching, StatusFiltered, StatusBlocked},
+	StatusFetching: append([]Status{StatusPending}, crawledStatuses...),
+	// Crawled URLs are pending again when recrawled, filtered and blocked ones when the rules change
+	StatusFetched:     {StatusPending},
+	StatusFailed:      {StatusPending},
+	StatusRedirected:  {StatusPending},
+	StatusNotModified: {StatusPending},
+	StatusDuplicate:   {StatusPending},
+	StatusNonHTML:     {StatusPending},
+	StatusFiltered:    {StatusPending},
+	StatusBlocked:     {StatusPending},
+}
+
+// CanTransitionTo checks if a URL can move from the status to next
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCrawled checks if the URL was fetched, successfully or not
+func (s Status) IsCrawled() bool {
+	for _, status := range crawledStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// HasContent checks if the URL was fetched with its content
+func (s Status) HasContent() bool {
+	return s == StatusFetched || s == StatusNotModified || s == StatusDuplicate
+}
+
+// CrawledStatuses returns the statuses of URLs whose fetch completed
+func CrawledStatuses() []Status {
+	return append([]Status(nil), crawledStatuses...)
+}
+
+// StatusCount is the number of URLs in a status for a reason
+type StatusCount struct {
+	Status Status
+	Reason string
+	Count  int
+}
@@ -0,0 +1,145 @@
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/gerthdala/webcrawler/internal/domain/analysis"
+	"github.com/gerthdala/webcrawler/internal/domain/content"
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+	"github.com/google/uuid"
+)
+
+// Passage is a chunk of crawled content returned for a query
+type Passage struct {
+	ChunkID     uuid.UUID
+	ContentID   uuid.UUID
+	URL         string
+	Title       string
+	Heading     string
+	Text        string
+	StartOffset int // character offset of the passage in the content text
+	EndOffset   int
+	Score       float64 // cosine similarity between the passage and the query
+}
+
+// RetrievalService retrieves passages of crawled content relevant to a query
+type RetrievalService struct {
+	vectorizer          analysis.TextVectorizer
+	chunkRepo           content.ChunkRepository
+	similarity          analysis.SimilarityCalculator
+	mmrLambda           float64
+	candidateMultiplier int
+}
+
+// RetrievalServiceConfig configuration for the retrieval service
+type RetrievalServiceConfig struct {
+	// MMRLambda trades relevance (1) against diversity (0) when re-ranking passages
+	MMRLambda float64
+	// CandidateMultiplier is the number of candidates fetched per requested passage
+	CandidateMultiplier int
+}
+
+// NewRetrievalService creates a new RetrievalService
+func NewRetrievalService(
+	vectorizer analysis.TextVectorizer,
+	chunkRepo content.ChunkRepository,
+	similarity analysis.SimilarityCalculator,
+	config RetrievalServiceConfig,
+) *RetrievalService {
+	lambda := config.MMRLambda
+	if lambda <= 0 || lambda > 1 {
+		lambda = 0.7
+	}
+	multiplier := config.CandidateMultiplier
+	if multiplier <= 0 {
+		multiplier = 4
+	}
+
+	return &RetrievalService{
+		vectorizer:          vectorizer,
+		chunkRepo:           chunkRepo,
+		similarity:          similarity,
+		mmrLambda:           lambda,
+		candidateMultiplier: multiplier,
+	}
+}
+
+// Retrieve returns the k passages most relevant to the query, re-ranked with
+// maximal marginal relevance so that near-duplicate passages are not all returned
+func (s *RetrievalService) Retrieve(ctx context.Context, query string, k int, filters content.ChunkFilter) result.Result[[]Passage] {
+	if strings.TrimSpace(query) == "" {
+		return result.ErrMsg[[]Passage]("query is empty")
+	}
+	if k <= 0 {
+		return result.Ok([]Passage{})
+	}
+
+	embeddingResult := s.vectorizer.Vectorize(ctx, query)
+	if embeddingResult.IsErr() {
+		return result.Err[[]Passage](fmt.Errorf("failed to vectorize query: %w", embeddingResult.Error()))
+	}
+
+	candidatesResult := s.chunkRepo.FindNearest(ctx, embeddingResult.Unwrap(), filters, k*s.candidateMultiplier)
+	if candidatesResult.IsErr() {
+		return result.Err[[]Passage](fmt.Errorf("failed to find candidate passages: %w", candidatesResult.Error()))
+	}
+
+	selected := s.rerank(ctx, candidatesResult.Unwrap(), k)
+
+	passages := make([]Passage, len(selected))
+	for i, match := range selected {
+		passages[i] = Passage{
+			ChunkID:     match.Chunk.ID,
+			ContentID:   match.Chunk.ContentID,
+			URL:         match.ContentURL,
+			Title:       match.ContentTitle,
+			Heading:     match.Chunk.Heading,
+			Text:        match.Chunk.Text,
+			StartOffset: match.Chunk.StartOffset,
+			EndOffset:   match.Chunk.EndOffset,
+			Score:       1 - match.Distance,
+		}
+	}
+
+	return result.Ok(passages)
+}
+
+// rerank selects k candidates by maximal marginal relevance
+func (s *RetrievalService) rerank(ctx context.Context, candidates []content.ChunkMatch, k int) []content.ChunkMatch {
+	if len(candidates) <= 1 {
+		return candidates
+	}
+
+	selected := make([]content.ChunkMatch, 0, k)
+	remaining := append([]content.ChunkMatch(nil), candidates...)
+	// maxSim[i] is the highest similarity between remaining[i] and any selected candidate
+	maxSim := make([]float64, len(remaining))
+
+	for len(selected) < k && len(remaining) > 0 {
+		best, bestScore := 0, math.Inf(-1)
+		for i, candidate := range remaining {
+			relevance := 1 - candidate.Distance
+			score := s.mmrLambda*relevance - (1-s.mmrLambda)*maxSim[i]
+			if score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+
+		chosen := remaining[best]
+		selected = append(selected, chosen)
+		remaining = append(remaining[:best], remaining[best+1:]...)
+		maxSim = append(maxSim[:best], maxSim[best+1:]...)
+
+		for i, candidate := range remaining {
+			simResult := s.similarity.CalculateSimilarity(ctx, chosen.Chunk.VectorEmbedding, candidate.Chunk.VectorEmbedding)
+			if simResult.IsOk() && simResult.Unwrap() > maxSim[i] {
+				maxSim[i] = simResult.Unwrap()
+			}
+		}
+	}
+
+	return selected
+}
@@ -0,0 +1,124 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+const (
+	highlightOpen  = "<mark>"
+	highlightClose = "</mark>"
+)
+
+// queryTerms splits a query into lowercased terms worth highlighting
+func queryTerms(query string) []string {
+	terms := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, field := range strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		term := strings.ToLower(field)
+		if len([]rune(term)) < 2 || seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+// Snippet returns an HTML-escaped excerpt of about length characters around the first
+// occurrence of a term in text, with every term occurrence wrapped in <mark> tags.
+// Terms are matched case-insensitively at the start of words, so "crawl" highlights "crawler".
+func Snippet(text string, terms []string, length int) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	type match struct{ start, end int }
+	matches := make([]match, 0)
+	for i := 0; i < len(lower); i++ {
+		if i > 0 && isWordRune(lower[i-1]) {
+			continue
+		}
+		for _, term := range terms {
+			t := []rune(term)
+			if hasRunePrefix(lower[i:], t) {
+				end := i + len(t)
+				for end < len(lower) && isWordRune(lower[end]) {
+					end++
+				}
+				matches = append(matches, match{i, end})
+				i = end - 1
+				break
+			}
+		}
+	}
+
+	// Centre the window on the first match, or take the start of the text
+	start := 0
+	if len(matches) > 0 {
+		start = matches[0].start - length/3
+		if start < 0 {
+			start = 0
+		}
+	}
+	end := start + length
+	if end > len(runes) {
+		end = len(runes)
+	}
+	start, end = expandToWords(runes, start, end)
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, m := range matches {
+		if m.end <= start || m.start >= end {
+			continue
+		}
+		mStart, mEnd := max(m.start, start), min(m.end, end)
+		b.WriteString(html.EscapeString(string(runes[pos:mStart])))
+		b.WriteString(highlightOpen)
+		b.WriteString(html.EscapeString(string(runes[mStart:mEnd])))
+		b.WriteString(highlightClose)
+		pos = mEnd
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:end])))
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+// expandToWords moves the window boundaries back so that no word is cut in half
+func expandToWords(runes []rune, start, end int) (int, int) {
+	for start > 0 && isWordRune(runes[start-1]) && isWordRune(runes[start]) {
+		start--
+	}
+	for end < len(runes) && end > start && isWordRune(runes[end-1]) && isWordRune(runes[end]) {
+		end--
+	}
+	return start, end
+}
+
+func hasRunePrefix(s, prefix []rune) bool {
+	if len(prefix) > len(s) {
+		return false
+	}
+	for i, r := range prefix {
+		if s[i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,139 @@
+package search
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/gerthdala/webcrawler/internal/domain/content"
+	"github.com/gerthdala/webcrawler/internal/domain/search/query"
+)
+
+// Predicate compiles a parsed query into a function matching Content in memory,
+// for backends without a query engine. A nil query matches all Content.
+func Predicate(n query.Node) func(c *content.Content) bool {
+	if n == nil {
+		return func(*content.Content) bool { return true }
+	}
+
+	switch n := n.(type) {
+	case query.And:
+		children := predicates(n.Children)
+		return func(c *content.Content) bool {
+			for _, child := range children {
+				if !child(c) {
+					return false
+				}
+			}
+			return true
+		}
+	case query.Or:
+		children := predicates(n.Children)
+		return func(c *content.Content) bool {
+			for _, child := range children {
+				if child(c) {
+					return true
+				}
+			}
+			return false
+		}
+	case query.Not:
+		child := Predicate(n.Child)
+		return func(c *content.Content) bool {
+			return !child(c)
+		}
+	case query.Term:
+		return func(c *content.Content) bool {
+			return containsText(c.Title+" "+c.Text, n.Text, n.Phrase)
+		}
+	case query.FieldTerm:
+		return fieldPredicate(n)
+	default:
+		return func(*content.Content) bool { return false }
+	}
+}
+
+func predicates(nodes []query.Node) []func(c *content.Content) bool {
+	compiled := make([]func(c *content.Content) bool, len(nodes))
+	for i, n := range nodes {
+		compiled[i] = Predicate(n)
+	}
+	return compiled
+}
+
+func fieldPredicate(t query.FieldTerm) func(c *content.Content) bool {
+	value := strings.ToLower(t.Value)
+
+	switch t.Field {
+	case query.FieldTitle:
+		return func(c *content.Content) bool { return containsText(c.Title, t.Value, t.Phrase) }
+	case query.FieldText:
+		return func(c *content.Content) bool { return containsText(c.Text, t.Value, t.Phrase) }
+	case query.FieldDomain:
+		return func(c *content.Content) bool {
+			parsed, err := url.Parse(c.URL)
+			if err != nil {
+				return false
+			}
+			host := strings.ToLower(parsed.Hostname())
+			return host == value || strings.HasSuffix(host, "."+value)
+		}
+	case query.FieldLanguage:
+		return func(c *content.Content) bool { return strings.EqualFold(c.Language, value) }
+	case query.FieldType:
+		return func(c *content.Content) bool { return strings.EqualFold(string(c.Classification), value) }
+	case query.FieldEntity:
+		return func(c *content.Content) bool {
+			for _, entity := range c.NamedEntities {
+				if t.Qualifier != "" && !strings.EqualFold(string(entity.Type), t.Qualifier) {
+					continue
+				}
+				if strings.EqualFold(entity.Text, t.Value) {
+					return true
+				}
+			}
+			return false
+		}
+	case query.FieldTopic:
+		return func(c *content.Content) bool {
+			for _, topic := range c.Topics {
+				if strings.Contains(strings.ToLower(topic.Name), value) {
+					return true
+				}
+			}
+			return false
+		}
+	case query.FieldKeyword:
+		return func(c *content.Content) bool {
+			for _, keyword := range c.Keywords {
+				if strings.EqualFold(keyword, value) {
+					return true
+				}
+			}
+			return false
+		}
+	case query.FieldURL:
+		return func(c *content.Content) bool { return strings.HasPrefix(c.URL, t.Value) }
+	case query.FieldAfter:
+		return func(c *content.Content) bool { return !c.CreatedAt.Before(t.Time) }
+	case query.FieldBefore:
+		return func(c *content.Content) bool { return c.CreatedAt.Before(t.Time) }
+	default:
+		return func(*content.Content) bool { return false }
+	}
+}
+
+// containsText reports whether text contains the phrase, or every word of the term
+func containsText(text, term string, phrase bool) bool {
+	text = strings.ToLower(text)
+	term = strings.ToLower(term)
+	if phrase {
+		return strings.Contains(text, term)
+	}
+
+	for _, word := range strings.Fields(term) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
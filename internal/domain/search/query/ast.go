@@ -0,0 +1,205 @@
+package query
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Field is a field a query term can be restricted to
+type Field string
+
+const (
+	FieldTitle    Field = "title"
+	FieldText     Field = "text"
+	FieldDomain   Field = "domain"
+	FieldLanguage Field = "lang"
+	FieldType     Field = "type"
+	FieldEntity   Field = "entity"
+	FieldTopic    Field = "topic"
+	FieldKeyword  Field = "keyword"
+	FieldURL      Field = "url"
+	FieldAfter    Field = "after"
+	FieldBefore   Field = "before"
+)
+
+// fieldAliases maps accepted field names to their canonical Field
+var fieldAliases = map[string]Field{
+	"title":          FieldTitle,
+	"text":           FieldText,
+	"body":           FieldText,
+	"domain":         FieldDomain,
+	"site":           FieldDomain,
+	"lang":           FieldLanguage,
+	"language":       FieldLanguage,
+	"type":           FieldType,
+	"classification": FieldType,
+	"entity":         FieldEntity,
+	"topic":          FieldTopic,
+	"keyword":        FieldKeyword,
+	"url":            FieldURL,
+	"after":          FieldAfter,
+	"before":         FieldBefore,
+}
+
+// DateLayout is the layout of after: and before: values
+const DateLayout = "2006-01-02"
+
+// Node is a node of a query syntax tree
+type Node interface {
+	// String returns the node in query syntax
+	String() string
+	node()
+}
+
+// And matches when all its children match
+type And struct {
+	Children []Node
+}
+
+// Or matches when any of its children matches
+type Or struct {
+	Children []Node
+}
+
+// Not matches when its child does not match
+type Not struct {
+	Child Node
+}
+
+// Term matches free text in the title or text
+type Term struct {
+	Text   string
+	Phrase bool
+}
+
+// FieldTerm matches a value of a field
+type FieldTerm struct {
+	Field Field
+	// Qualifier narrows the field, e.g. the entity type in entity:person:"Ada Lovelace"
+	Qualifier string
+	Value     string
+	Phrase    bool
+	// Time is the parsed value of after: and before: terms
+	Time time.Time
+}
+
+func (And) node()       {}
+func (Or) node()        {}
+func (Not) node()       {}
+func (Term) node()      {}
+func (FieldTerm) node() {}
+
+func (n And) String() string {
+	return joinNodes(n.Children, " AND ")
+}
+
+func (n Or) String() string {
+	return joinNodes(n.Children, " OR ")
+}
+
+func (n Not) String() string {
+	return "NOT " + groupNode(n.Child)
+}
+
+func (n Term) String() string {
+	return quoteIf(n.Text, n.Phrase)
+}
+
+func (n FieldTerm) String() string {
+	prefix := string(n.Field) + ":"
+	if n.Qualifier != "" {
+		prefix += n.Qualifier + ":"
+	}
+	return prefix + quoteIf(n.Value, n.Phrase)
+}
+
+func joinNodes(nodes []Node, sep string) string {
+	parts := make([]string, len(nodes))
+	for i, n := range nodes {
+		parts[i] = groupNode(n)
+	}
+	return strings.Join(parts, sep)
+}
+
+// groupNode wraps boolean nodes in parentheses
+func groupNode(n Node) string {
+	switch n.(type) {
+	case And, Or:
+		return "(" + n.String() + ")"
+	default:
+		return n.String()
+	}
+}
+
+func quoteIf(s string, quote bool) string {
+	if quote {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// FreeText returns the free text terms of a query that are not negated,
+// e.g. to rank results or highlight matches
+func FreeText(n Node) []Term {
+	terms := make([]Term, 0)
+	var walk func(n Node)
+	walk = func(n Node) {
+		switch n := n.(type) {
+		case And:
+			for _, child := range n.Children {
+				walk(child)
+			}
+		case Or:
+			for _, child := range n.Children {
+				walk(child)
+			}
+		case Term:
+			terms = append(terms, n)
+		}
+	}
+	if n != nil {
+		walk(n)
+	}
+	return terms
+}
+
+// Restrictions returns the part of a query restricting results without matching free text,
+// e.g. to filter a vector search ranking documents by meaning rather than by their words.
+// Free text terms match anything, negated subtrees are kept as exclusions, and nil is
+// returned when nothing is restricted.
+func Restrictions(n Node) Node {
+	switch n := n.(type) {
+	case And:
+		children := make([]Node, 0, len(n.Children))
+		for _, child := range n.Children {
+			if restricted := Restrictions(child); restricted != nil {
+				children = append(children, restricted)
+			}
+		}
+		switch len(children) {
+		case 0:
+			return nil
+		case 1:
+			return children[0]
+		}
+		return And{Children: children}
+	case Or:
+		children := make([]Node, 0, len(n.Children))
+		for _, child := range n.Children {
+			restricted := Restrictions(child)
+			// A child matching anything makes the whole disjunction match anything
+			if restricted == nil {
+				return nil
+			}
+			children = append(children, restricted)
+		}
+		if len(children) == 1 {
+			return children[0]
+		}
+		return Or{Children: children}
+	case Not, FieldTerm:
+		return n
+	}
+	return nil
+}
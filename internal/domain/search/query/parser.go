@@ -0,0 +1,339 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ParseError describes malformed query input
+type ParseError struct {
+	Input string
+	// Pos is the character offset of the error in Input
+	Pos int
+	Msg string
+}
+
+// Error returns the error message with its position
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("query syntax error at position %d: %s", e.Pos+1, e.Msg)
+}
+
+// Detail returns the input with a caret under the error position
+func (e *ParseError) Detail() string {
+	return e.Input + "\n" + strings.Repeat(" ", e.Pos) + "^ " + e.Msg
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenPhrase
+	tokenColon
+	tokenLParen
+	tokenRParen
+	tokenMinus
+	tokenAnd
+	tokenOr
+	tokenNot
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int // character offset
+}
+
+func (t token) describe() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenPhrase:
+		return fmt.Sprintf("%q", t.text)
+	default:
+		return fmt.Sprintf("'%s'", t.text)
+	}
+}
+
+// lex splits input into tokens
+func lex(input string) ([]token, error) {
+	runes := []rune(input)
+	tokens := make([]token, 0)
+	// atTermStart is true where a '-' negates the following term
+	atTermStart := true
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			atTermStart = true
+		case r == '(':
+			tokens = append(tokens, token{tokenLParen, "(", i})
+			i++
+			atTermStart = true
+		case r == ')':
+			tokens = append(tokens, token{tokenRParen, ")", i})
+			i++
+			atTermStart = false
+		case r == ':':
+			tokens = append(tokens, token{tokenColon, ":", i})
+			i++
+			atTermStart = false
+		case r == '-' && atTermStart:
+			tokens = append(tokens, token{tokenMinus, "-", i})
+			i++
+			atTermStart = false
+		case r == '"':
+			start := i
+			var b strings.Builder
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				b.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, &ParseError{Input: input, Pos: start, Msg: "unterminated quoted phrase"}
+			}
+			i++
+			tokens = append(tokens, token{tokenPhrase, b.String(), start})
+			atTermStart = false
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(`():"`, runes[i]) {
+				i++
+			}
+			word := string(runes[start:i])
+			kind := tokenWord
+			switch word {
+			case "AND":
+				kind = tokenAnd
+			case "OR":
+				kind = tokenOr
+			case "NOT":
+				kind = tokenNot
+			}
+			tokens = append(tokens, token{kind, word, start})
+			atTermStart = false
+		}
+	}
+
+	return append(tokens, token{tokenEOF, "", len(runes)}), nil
+}
+
+// Parse parses a search query into a syntax tree.
+//
+// Terms are combined with AND by default; AND, OR and NOT (upper case) combine
+// terms explicitly and parentheses group them. A leading '-' negates a term.
+// Terms are plain words, "quoted phrases" or field terms such as title:"release notes",
+// domain:example.com, lang:fr, type:article, topic:go, keyword:crawler, url:https://example.com/docs,
+// entity:"Ada Lovelace", entity:person:"Ada Lovelace", after:2025-01-01 and before:2025-06-30.
+// Other words containing colons, such as URLs or times, are searched as phrases.
+func Parse(input string) (Node, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{input: input, tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, p.errorAt(p.peek(), "query is empty")
+	}
+
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		if t.kind == tokenRParen {
+			return nil, p.errorAt(t, "unmatched ')'")
+		}
+		return nil, p.errorAt(t, "unexpected "+t.describe())
+	}
+
+	return n, nil
+}
+
+type parser struct {
+	input  string
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) errorAt(t token, msg string) *ParseError {
+	return &ParseError{Input: p.input, Pos: t.pos, Msg: msg}
+}
+
+// parseOr parses and-expressions separated by OR
+func (p *parser) parseOr() (Node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	children := []Node{first}
+	for p.peek().kind == tokenOr {
+		p.next()
+		n, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, n)
+	}
+
+	if len(children) == 1 {
+		return first, nil
+	}
+	return Or{Children: children}, nil
+}
+
+// parseAnd parses unary expressions separated by AND or juxtaposed
+func (p *parser) parseAnd() (Node, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	children := []Node{first}
+	for {
+		t := p.peek()
+		if t.kind == tokenAnd {
+			p.next()
+		} else if t.kind == tokenEOF || t.kind == tokenOr || t.kind == tokenRParen {
+			break
+		}
+
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, n)
+	}
+
+	if len(children) == 1 {
+		return first, nil
+	}
+	return And{Children: children}, nil
+}
+
+// parseUnary parses an optionally negated primary expression
+func (p *parser) parseUnary() (Node, error) {
+	if t := p.peek(); t.kind == tokenNot || t.kind == tokenMinus {
+		p.next()
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Child: child}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses a group or a term
+func (p *parser) parsePrimary() (Node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenLParen:
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.peek(); closing.kind != tokenRParen {
+			return nil, p.errorAt(t, "missing ')' to close this group")
+		}
+		p.next()
+		return n, nil
+	case tokenPhrase:
+		if strings.TrimSpace(t.text) == "" {
+			return nil, p.errorAt(t, "empty phrase")
+		}
+		return Term{Text: t.text, Phrase: true}, nil
+	case tokenWord:
+		if p.peek().kind != tokenColon {
+			return Term{Text: t.text}, nil
+		}
+		if _, ok := fieldAliases[strings.ToLower(t.text)]; ok {
+			return p.parseField(t)
+		}
+		// Other words followed by a colon, such as URLs or times, are searched as a phrase
+		return Term{Text: p.joinAdjacent(t), Phrase: true}, nil
+	case tokenEOF:
+		return nil, p.errorAt(t, "expected a term at end of query")
+	case tokenAnd, tokenOr:
+		return nil, p.errorAt(t, fmt.Sprintf("%s must be between two terms", t.text))
+	default:
+		return nil, p.errorAt(t, "unexpected "+t.describe())
+	}
+}
+
+// parseField parses the value of a field term whose name has been read
+func (p *parser) parseField(name token) (Node, error) {
+	field := fieldAliases[strings.ToLower(name.text)]
+	p.next() // colon
+
+	term := FieldTerm{Field: field}
+
+	// entity:<type>:<value> qualifies the entity with its type
+	if field == FieldEntity && p.peek().kind == tokenWord &&
+		p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].kind == tokenColon {
+		term.Qualifier = strings.ToLower(p.next().text)
+		p.next()
+	}
+
+	value := p.next()
+	switch value.kind {
+	case tokenWord:
+		// Values may contain colons, e.g. url:https://example.com
+		term.Value = p.joinAdjacent(value)
+	case tokenPhrase:
+		term.Value = value.text
+		term.Phrase = true
+	default:
+		return nil, p.errorAt(value, fmt.Sprintf("expected a value after %s:", name.text))
+	}
+
+	if term.Value == "" {
+		return nil, p.errorAt(value, fmt.Sprintf("empty value for %s:", name.text))
+	}
+
+	if field == FieldAfter || field == FieldBefore {
+		parsed, err := time.Parse(DateLayout, term.Value)
+		if err != nil {
+			return nil, p.errorAt(value, fmt.Sprintf("invalid date %q for %s:, expected YYYY-MM-DD", term.Value, name.text))
+		}
+		term.Time = parsed
+	}
+
+	return term, nil
+}
+
+// joinAdjacent returns the text of a word read last and of the colons and words directly following it
+func (p *parser) joinAdjacent(word token) string {
+	text := word.text
+	for p.peek().kind == tokenColon && p.peek().pos == word.pos+utf8.RuneCountInString(text) {
+		p.next()
+		text += ":"
+		if next := p.peek(); next.kind == tokenWord && next.pos == word.pos+utf8.RuneCountInString(text) {
+			text += p.next().text
+		}
+	}
+	return text
+}
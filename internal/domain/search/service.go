@@ -0,0 +1,310 @@
+package search
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/gerthdala/webcrawler/internal/domain/analysis"
+	"github.com/gerthdala/webcrawler/internal/domain/content"
+	"github.com/gerthdala/webcrawler/internal/domain/search/query"
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCursor is returned when a search cursor is malformed or belongs to another query
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// SearchRequest is a hybrid search request
+type SearchRequest struct {
+	Query  string
+	Filter content.SearchFilter
+	Limit  int
+	// Cursor is the NextCursor of the previous page, empty for the first page
+	Cursor string
+}
+
+// SearchHit is a Content found by a search
+type SearchHit struct {
+	Content content.Content
+	// Score is the reciprocal rank fusion score of the hit
+	Score float64
+	// KeywordRank and VectorRank are the 1-based ranks of the hit in each result list, 0 when absent
+	KeywordRank int
+	VectorRank  int
+	// Snippet is an HTML excerpt of the text with the query terms wrapped in <mark> tags
+	Snippet string
+}
+
+// SearchPage is a page of search hits
+type SearchPage struct {
+	Hits []SearchHit
+	// NextCursor fetches the next page, empty on the last page
+	NextCursor string
+}
+
+// SearchService searches Content by fusing keyword and vector rankings
+type SearchService struct {
+	contentRepo   content.ContentRepository
+	vectorizer    analysis.TextVectorizer
+	rrfK          int
+	maxWindow     int
+	snippetLength int
+}
+
+// SearchServiceConfig configuration for the search service
+type SearchServiceConfig struct {
+	// RRFK is the rank constant of reciprocal rank fusion, 60 by default
+	RRFK int
+	// MaxWindow is the number of results fetched from each ranking and fused for every page,
+	// and so bounds the pagination depth, 1000 by default
+	MaxWindow int
+	// SnippetLength is the approximate number of characters of a snippet
+	SnippetLength int
+}
+
+// NewSearchService creates a new SearchService
+func NewSearchService(
+	contentRepo content.ContentRepository,
+	vectorizer analysis.TextVectorizer,
+	config SearchServiceConfig,
+) *SearchService {
+	rrfK := config.RRFK
+	if rrfK <= 0 {
+		rrfK = 60
+	}
+	maxWindow := config.MaxWindow
+	if maxWindow <= 0 {
+		maxWindow = 1000
+	}
+	snippetLength := config.SnippetLength
+	if snippetLength <= 0 {
+		snippetLength = 200
+	}
+
+	return &SearchService{
+		contentRepo:   contentRepo,
+		vectorizer:    vectorizer,
+		rrfK:          rrfK,
+		maxWindow:     maxWindow,
+		snippetLength: snippetLength,
+	}
+}
+
+// cursor is the decoded form of SearchPage.NextCursor
+type cursor struct {
+	Offset int    `json:"o"`
+	Query  string `json:"q"` // hash of the request the cursor belongs to
+}
+
+// Search runs a hybrid keyword and vector search and returns a page of hits.
+// The query is parsed with query.Parse: its free text is ranked by keywords and by vector
+// similarity, both rankings fused with reciprocal rank fusion: score = Σ 1 / (k + rank).
+// Field terms and boolean operators restrict the hits; the vector ranking is only restricted by the
+// field terms, so that it finds documents close in meaning without the words of the query.
+// Malformed queries return a *query.ParseError.
+func (s *SearchService) Search(ctx context.Context, req SearchRequest) result.Result[SearchPage] {
+	parsed, err := query.Parse(req.Query)
+	if err != nil {
+		return result.Err[SearchPage](err)
+	}
+
+	filter := req.Filter
+	filter.Query = parsed
+	vectorFilter := req.Filter
+	vectorFilter.Query = query.Restrictions(parsed)
+
+	freeText := make([]string, 0)
+	for _, term := range query.FreeText(parsed) {
+		freeText = append(freeText, term.Text)
+	}
+	text := strings.Join(freeText, " ")
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+
+	offset := 0
+	fingerprint := requestFingerprint(req)
+	if req.Cursor != "" {
+		c, err := decodeCursor(req.Cursor)
+		if err != nil {
+			return result.Err[SearchPage](err)
+		}
+		if c.Query != fingerprint {
+			return result.Err[SearchPage](fmt.Errorf("%w: cursor does not belong to this query", ErrInvalidCursor))
+		}
+		offset = c.Offset
+	}
+
+	// Both rankings are fetched over the same window for every page, so that the fused ranks,
+	// and so the pages, are consistent across requests. Only the IDs are ranked over the window,
+	// the Content is loaded for the hits of the page.
+	window := s.maxWindow
+	if offset >= window {
+		return result.Ok(SearchPage{Hits: []SearchHit{}})
+	}
+
+	keywordResult := s.contentRepo.KeywordRanking(ctx, text, filter, window)
+	if keywordResult.IsErr() {
+		return result.Err[SearchPage](fmt.Errorf("keyword search failed: %w", keywordResult.Error()))
+	}
+
+	// Vector search is best effort, keyword hits are still returned without it
+	var vectorHits []content.ScoredID
+	if s.vectorizer != nil && text != "" {
+		if embeddingResult := s.vectorizer.Vectorize(ctx, text); embeddingResult.IsOk() {
+			vectorResult := s.contentRepo.VectorRanking(ctx, embeddingResult.Unwrap(), vectorFilter, window)
+			if vectorResult.IsOk() {
+				vectorHits = vectorResult.Unwrap()
+			} else {
+				log.Printf("Vector search failed, using keyword ranking only: %v", vectorResult.Error())
+			}
+		} else {
+			log.Printf("Failed to vectorize query, using keyword ranking only: %v", embeddingResult.Error())
+		}
+	}
+
+	fused := s.fuse(keywordResult.Unwrap(), vectorHits)
+
+	page := SearchPage{Hits: []SearchHit{}}
+	if offset < len(fused) {
+		end := offset + limit
+		if end > len(fused) {
+			end = len(fused)
+		}
+
+		hits, err := s.loadHits(ctx, fused[offset:end])
+		if err != nil {
+			return result.Err[SearchPage](err)
+		}
+		page.Hits = hits
+
+		if end < len(fused) {
+			page.NextCursor = encodeCursor(cursor{Offset: end, Query: fingerprint})
+		}
+	}
+
+	terms := queryTerms(text)
+	for i := range page.Hits {
+		page.Hits[i].Snippet = Snippet(page.Hits[i].Content.Text, terms, s.snippetLength)
+	}
+
+	return result.Ok(page)
+}
+
+// fusedHit is a hit of the fused ranking, before its Content is loaded
+type fusedHit struct {
+	ID          uuid.UUID
+	Score       float64
+	KeywordRank int
+	VectorRank  int
+}
+
+// fuse merges the keyword and vector rankings with reciprocal rank fusion
+func (s *SearchService) fuse(keywordHits, vectorHits []content.ScoredID) []fusedHit {
+	byID := make(map[uuid.UUID]*fusedHit)
+	order := make([]uuid.UUID, 0, len(keywordHits)+len(vectorHits))
+
+	add := func(hits []content.ScoredID, setRank func(hit *fusedHit, rank int)) {
+		for i, scored := range hits {
+			hit, ok := byID[scored.ID]
+			if !ok {
+				hit = &fusedHit{ID: scored.ID}
+				byID[scored.ID] = hit
+				order = append(order, scored.ID)
+			}
+			rank := i + 1
+			setRank(hit, rank)
+			hit.Score += 1 / float64(s.rrfK+rank)
+		}
+	}
+	add(keywordHits, func(hit *fusedHit, rank int) { hit.KeywordRank = rank })
+	add(vectorHits, func(hit *fusedHit, rank int) { hit.VectorRank = rank })
+
+	fused := make([]fusedHit, len(order))
+	for i, id := range order {
+		fused[i] = *byID[id]
+	}
+
+	// Ties are broken by ID so that pages are stable across requests
+	sort.SliceStable(fused, func(i, j int) bool {
+		if fused[i].Score != fused[j].Score {
+			return fused[i].Score > fused[j].Score
+		}
+		return fused[i].ID.String() < fused[j].ID.String()
+	})
+
+	return fused
+}
+
+// loadHits loads the Content of fused hits, in fused order.
+// Content deleted since it was ranked is left out of the page.
+func (s *SearchService) loadHits(ctx context.Context, fused []fusedHit) ([]SearchHit, error) {
+	ids := make([]uuid.UUID, len(fused))
+	for i, hit := range fused {
+		ids[i] = hit.ID
+	}
+
+	contentsResult := s.contentRepo.FindByIDs(ctx, ids)
+	if contentsResult.IsErr() {
+		return nil, fmt.Errorf("failed to load search hits: %w", contentsResult.Error())
+	}
+	byID := make(map[uuid.UUID]content.Content, len(fused))
+	for _, c := range contentsResult.Unwrap() {
+		byID[c.ID] = c
+	}
+
+	hits := make([]SearchHit, 0, len(fused))
+	for _, hit := range fused {
+		c, ok := byID[hit.ID]
+		if !ok {
+			continue
+		}
+		hits = append(hits, SearchHit{
+			Content:     c,
+			Score:       hit.Score,
+			KeywordRank: hit.KeywordRank,
+			VectorRank:  hit.VectorRank,
+		})
+	}
+	return hits, nil
+}
+
+// requestFingerprint identifies the query and filter a cursor was issued for
+func requestFingerprint(req SearchRequest) string {
+	data, _ := json.Marshal(struct {
+		Query  string
+		Filter content.SearchFilter
+	}{req.Query, req.Filter})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(encoded string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return c, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if c.Offset < 0 {
+		return c, fmt.Errorf("%w: negative offset", ErrInvalidCursor)
+	}
+	return c, nil
+}
@@ -0,0 +1,179 @@
+package watch
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
+	"github.com/google/uuid"
+)
+
+// Rule watches pages for changes and delivers them to a webhook
+type Rule struct {
+	ID   uuid.UUID
+	Name string
+	// URLPattern matches the URLs of the watched pages, * matching any sequence of characters
+	URLPattern string
+	// Selector restricts the watched content to the text of the elements matching a CSS selector
+	Selector string
+	// Keyword restricts the notified changes to those adding or removing the keyword, case-insensitively
+	Keyword string
+	// MinChange is the minimum number of characters added or removed for a change to be notified
+	MinChange int
+	// Events are the kinds of changes notified, all kinds when empty
+	Events     []crawler.ChangeKind
+	WebhookURL string
+	// Secret signs the webhook payloads with HMAC-SHA256
+	Secret    string
+	Enabled   bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewRule creates a new enabled Rule
+func NewRule(name, urlPattern, webhookURL, secret string) *Rule {
+	now := time.Now()
+	return &Rule{
+		ID:         uuid.New(),
+		Name:       name,
+		URLPattern: urlPattern,
+		WebhookURL: webhookURL,
+		Secret:     secret,
+		Enabled:    true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// MatchesURL reports whether the rule watches the page at url
+func (r *Rule) MatchesURL(url string) bool {
+	pattern := "^" + strings.ReplaceAll(regexp.QuoteMeta(r.URLPattern), `\*`, ".*") + "$"
+	matched, err := regexp.MatchString(pattern, url)
+	return err == nil && matched
+}
+
+// WatchesEvent reports whether the rule notifies changes of the given kind
+func (r *Rule) WatchesEvent(kind crawler.ChangeKind) bool {
+	if len(r.Events) == 0 {
+		return true
+	}
+	for _, event := range r.Events {
+		if event == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryStatus is the status of a webhook delivery
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// Delivery is a webhook delivery of a change matching a rule, logging its attempts
+type Delivery struct {
+	ID     uuid.UUID
+	RuleID uuid.UUID
+	Event  crawler.ChangeKind
+	URL    string
+	// Payload is the signed JSON body of the webhook
+	Payload       []byte
+	Status        DeliveryStatus
+	Attempts      int
+	LastAttemptAt time.Time
+	// LastStatusCode and LastError record the outcome of the last attempt
+	LastStatusCode int
+	LastError      string
+	NextAttemptAt  time.Time
+	DeliveredAt    time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// NewDelivery creates a new Delivery, due immediately
+func NewDelivery(id, ruleID uuid.UUID, event crawler.ChangeKind, url string, payload []byte) *Delivery {
+	now := time.Now()
+	return &Delivery{
+		ID:            id,
+		RuleID:        ruleID,
+		Event:         event,
+		URL:           url,
+		Payload:       payload,
+		Status:        DeliveryPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// RecordAttempt records an attempt to deliver the webhook and returns its entry in the delivery log.
+// A failed attempt is retried after retryAfter, unless retryAfter is zero and the delivery is given up.
+func (d *Delivery) RecordAttempt(statusCode int, err error, retryAfter time.Duration) *DeliveryAttempt {
+	now := time.Now()
+	d.Attempts++
+	d.LastAttemptAt = now
+	d.LastStatusCode = statusCode
+	d.UpdatedAt = now
+
+	switch {
+	case err == nil:
+		d.Status = DeliveryDelivered
+		d.LastError = ""
+		d.DeliveredAt = now
+	case retryAfter > 0:
+		d.LastError = err.Error()
+		d.NextAttemptAt = now.Add(retryAfter)
+	default:
+		d.Status = DeliveryFailed
+		d.LastError = err.Error()
+	}
+
+	return &DeliveryAttempt{
+		ID:         uuid.New(),
+		DeliveryID: d.ID,
+		Attempt:    d.Attempts,
+		StatusCode: statusCode,
+		Error:      d.LastError,
+		At:         now,
+	}
+}
+
+// DeliveryAttempt is an attempt to deliver a webhook, as logged for the delivery
+type DeliveryAttempt struct {
+	ID         uuid.UUID
+	DeliveryID uuid.UUID
+	// Attempt is the number of the attempt, starting at 1
+	Attempt int
+	// StatusCode is the response status code, 0 when the webhook could not be reached
+	StatusCode int
+	Error      string
+	At         time.Time
+}
+
+// Payload is the JSON body of a change webhook
+type Payload struct {
+	DeliveryID string             `json:"delivery_id"`
+	RuleID     string             `json:"rule_id"`
+	Rule       string             `json:"rule"`
+	Event      crawler.ChangeKind `json:"event"`
+	URL        string             `json:"url"`
+	Title      string             `json:"title,omitempty"`
+	// PreviousVersion is 0 when the page appeared for the first time
+	PreviousVersion int       `json:"previous_version,omitempty"`
+	CurrentVersion  int       `json:"current_version"`
+	StatusCode      int       `json:"status_code"`
+	ChangedChars    int       `json:"changed_chars"`
+	Edits           []Edit    `json:"edits,omitempty"`
+	OccurredAt      time.Time `json:"occurred_at"`
+}
+
+// Edit is text inserted into or deleted from the watched content
+type Edit struct {
+	Op   crawler.DiffOp `json:"op"`
+	Text string         `json:"text"`
+}
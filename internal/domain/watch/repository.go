@@ -0,0 +1,48 @@
+package watch
+
+import (
+	"context"
+	"time"
+
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+	"github.com/google/uuid"
+)
+
+// RuleRepository handles Rule storage and retrieval
+type RuleRepository interface {
+	// Save stores a Rule
+	Save(ctx context.Context, rule *Rule) result.Result[*Rule]
+
+	// FindByID finds a Rule by its ID
+	FindByID(ctx context.Context, id uuid.UUID) result.Result[*Rule]
+
+	// FindAll finds all Rules, oldest first
+	FindAll(ctx context.Context) result.Result[[]Rule]
+
+	// FindEnabled finds the enabled Rules
+	FindEnabled(ctx context.Context) result.Result[[]Rule]
+
+	// Delete deletes a Rule and its deliveries
+	Delete(ctx context.Context, id uuid.UUID) result.Result[bool]
+}
+
+// DeliveryRepository handles Delivery storage and retrieval
+type DeliveryRepository interface {
+	// Save stores a Delivery
+	Save(ctx context.Context, delivery *Delivery) result.Result[*Delivery]
+
+	// Update updates a stored Delivery
+	Update(ctx context.Context, delivery *Delivery) result.Result[*Delivery]
+
+	// RecordAttempt updates a stored Delivery and adds an attempt to its log
+	RecordAttempt(ctx context.Context, delivery *Delivery, attempt *DeliveryAttempt) result.Result[*Delivery]
+
+	// FindAttempts finds the attempts of a Delivery, first attempt first
+	FindAttempts(ctx context.Context, deliveryID uuid.UUID) result.Result[[]DeliveryAttempt]
+
+	// FindDue finds pending Deliveries due to be attempted at the given time, most overdue first
+	FindDue(ctx context.Context, now time.Time, limit int) result.Result[[]Delivery]
+
+	// FindByRule finds the Deliveries of a Rule, latest first
+	FindByRule(ctx context.Context, ruleID uuid.UUID, limit int) result.Result[[]Delivery]
+}
@@ -0,0 +1,361 @@
+package watch
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidRule is returned when a rule has no URL pattern, an invalid webhook URL, selector or event
+var ErrInvalidRule = errors.New("invalid watch rule")
+
+// WebhookSender posts signed webhooks
+type WebhookSender interface {
+	// Send posts the payload of a delivery to a webhook URL, signed with secret,
+	// and returns the response status code
+	Send(ctx context.Context, webhookURL, secret string, delivery *Delivery) result.Result[int]
+}
+
+// SelectorService extracts the content of HTML elements
+type SelectorService interface {
+	// SelectText returns the text of the elements of html matching a CSS selector, one per line
+	SelectText(html, selector string) result.Result[string]
+}
+
+// WatchService matches page changes against watch rules and delivers them as webhooks
+type WatchService struct {
+	ruleRepo       RuleRepository
+	deliveryRepo   DeliveryRepository
+	sender         WebhookSender
+	selector       SelectorService
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// WatchServiceConfig configuration for the watch service
+type WatchServiceConfig struct {
+	// MaxAttempts is the number of attempts to deliver a webhook before giving up, 8 by default
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, 30 seconds by default, doubling after
+	// each failed attempt up to MaxBackoff, an hour by default
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// NewWatchService creates a new WatchService
+func NewWatchService(
+	ruleRepo RuleRepository,
+	deliveryRepo DeliveryRepository,
+	sender WebhookSender,
+	selector SelectorService,
+	config WatchServiceConfig,
+) *WatchService {
+	maxAttempts := config.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 8
+	}
+	initialBackoff := config.InitialBackoff
+	if initialBackoff <= 0 {
+		initialBackoff = 30 * time.Second
+	}
+	maxBackoff := config.MaxBackoff
+	if maxBackoff < initialBackoff {
+		maxBackoff = max(time.Hour, initialBackoff)
+	}
+
+	return &WatchService{
+		ruleRepo:       ruleRepo,
+		deliveryRepo:   deliveryRepo,
+		sender:         sender,
+		selector:       selector,
+		maxAttempts:    maxAttempts,
+		initialBackoff: initialBackoff,
+		maxBackoff:     maxBackoff,
+	}
+}
+
+// CreateRule validates and stores a rule, generating its secret when it has none
+func (s *WatchService) CreateRule(ctx context.Context, rule *Rule) result.Result[*Rule] {
+	if err := s.validate(rule); err != nil {
+		return result.Err[*Rule](err)
+	}
+
+	if rule.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return result.Err[*Rule](fmt.Errorf("failed to generate secret: %w", err))
+		}
+		rule.Secret = hex.EncodeToString(secret)
+	}
+
+	return s.ruleRepo.Save(ctx, rule)
+}
+
+// Rules returns all rules
+func (s *WatchService) Rules(ctx context.Context) result.Result[[]Rule] {
+	return s.ruleRepo.FindAll(ctx)
+}
+
+// DeleteRule deletes a rule and its deliveries
+func (s *WatchService) DeleteRule(ctx context.Context, id uuid.UUID) result.Result[bool] {
+	return s.ruleRepo.Delete(ctx, id)
+}
+
+// Deliveries returns the delivery log of a rule, latest first
+func (s *WatchService) Deliveries(ctx context.Context, ruleID uuid.UUID, limit int) result.Result[[]Delivery] {
+	return s.deliveryRepo.FindByRule(ctx, ruleID, limit)
+}
+
+// Attempts returns the log of the attempts of a delivery, first attempt first
+func (s *WatchService) Attempts(ctx context.Context, deliveryID uuid.UUID) result.Result[[]DeliveryAttempt] {
+	return s.deliveryRepo.FindAttempts(ctx, deliveryID)
+}
+
+// NotifyChange queues a delivery for each enabled rule matching the change and returns the number queued
+func (s *WatchService) NotifyChange(ctx context.Context, event crawler.PageChangeEvent) result.Result[int] {
+	rulesResult := s.ruleRepo.FindEnabled(ctx)
+	if rulesResult.IsErr() {
+		return result.Err[int](fmt.Errorf("failed to find watch rules: %w", rulesResult.Error()))
+	}
+
+	queued := 0
+	for _, rule := range rulesResult.Unwrap() {
+		if !rule.MatchesURL(event.URL) || !rule.WatchesEvent(event.Kind) {
+			continue
+		}
+
+		id := uuid.New()
+		payload, ok, err := s.match(&rule, event, id)
+		if err != nil {
+			log.Printf("Failed to match watch rule %s against %s: %v", rule.Name, event.URL, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return result.Err[int](fmt.Errorf("failed to encode webhook payload: %w", err))
+		}
+		if saveResult := s.deliveryRepo.Save(ctx, NewDelivery(id, rule.ID, event.Kind, event.URL, body)); saveResult.IsErr() {
+			return result.Err[int](fmt.Errorf("failed to save delivery: %w", saveResult.Error()))
+		}
+		queued++
+	}
+
+	return result.Ok(queued)
+}
+
+// Run delivers due webhooks every tick until the context is done
+func (s *WatchService) Run(ctx context.Context, tick time.Duration, batchSize int) error {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		if delivered := s.DeliverDue(ctx, batchSize); delivered.IsErr() {
+			log.Printf("Failed to deliver webhooks: %v", delivered.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue attempts up to limit due deliveries and returns the number delivered
+func (s *WatchService) DeliverDue(ctx context.Context, limit int) result.Result[int] {
+	deliveriesResult := s.deliveryRepo.FindDue(ctx, time.Now(), limit)
+	if deliveriesResult.IsErr() {
+		return result.Err[int](fmt.Errorf("failed to find due deliveries: %w", deliveriesResult.Error()))
+	}
+
+	delivered := 0
+	for _, delivery := range deliveriesResult.Unwrap() {
+		if err := ctx.Err(); err != nil {
+			return result.Err[int](err)
+		}
+
+		deliverResult := s.Deliver(ctx, &delivery)
+		if deliverResult.IsErr() {
+			log.Printf("Failed to deliver webhook %s: %v", delivery.ID, deliverResult.Error())
+			continue
+		}
+		if deliverResult.Unwrap().Status == DeliveryDelivered {
+			delivered++
+		}
+	}
+
+	return result.Ok(delivered)
+}
+
+// Deliver attempts a delivery and records the outcome in the delivery log, scheduling a retry
+// with exponential backoff when the webhook could not be reached or answered with a server error
+func (s *WatchService) Deliver(ctx context.Context, delivery *Delivery) result.Result[*Delivery] {
+	ruleResult := s.ruleRepo.FindByID(ctx, delivery.RuleID)
+	if ruleResult.IsErr() {
+		return result.Err[*Delivery](fmt.Errorf("failed to find rule of delivery: %w", ruleResult.Error()))
+	}
+	rule := ruleResult.Unwrap()
+
+	statusCode := 0
+	var err error
+	sendResult := s.sender.Send(ctx, rule.WebhookURL, rule.Secret, delivery)
+	if sendResult.IsErr() {
+		err = sendResult.Error()
+	} else if statusCode = sendResult.Unwrap(); statusCode < 200 || statusCode >= 300 {
+		err = fmt.Errorf("webhook responded with status %d", statusCode)
+	}
+
+	retryAfter := time.Duration(0)
+	if err != nil && delivery.Attempts+1 < s.maxAttempts && retryable(statusCode) {
+		retryAfter = min(s.initialBackoff<<delivery.Attempts, s.maxBackoff)
+		// Large shifts overflow to negative durations
+		if retryAfter <= 0 {
+			retryAfter = s.maxBackoff
+		}
+	}
+	attempt := delivery.RecordAttempt(statusCode, err, retryAfter)
+
+	if recordResult := s.deliveryRepo.RecordAttempt(ctx, delivery, attempt); recordResult.IsErr() {
+		return result.Err[*Delivery](fmt.Errorf("failed to record delivery attempt: %w", recordResult.Error()))
+	}
+
+	return result.Ok(delivery)
+}
+
+// retryable reports whether a failed attempt with the given status code is retried,
+// 0 meaning the webhook could not be reached
+func retryable(statusCode int) bool {
+	return statusCode == 0 ||
+		statusCode >= 500 ||
+		statusCode == http.StatusRequestTimeout ||
+		statusCode == http.StatusTooManyRequests
+}
+
+// match builds the webhook payload of a change matching the selector, keyword and minimum change
+// size of a rule, reporting false when the change does not match
+func (s *WatchService) match(rule *Rule, event crawler.PageChangeEvent, deliveryID uuid.UUID) (*Payload, bool, error) {
+	// Pages that appear or disappear are compared against empty content
+	previousText, currentText := "", ""
+	if event.Kind != crawler.ChangeAppeared && event.Previous != nil {
+		text, err := s.watchedText(rule, event.Previous)
+		if err != nil {
+			return nil, false, err
+		}
+		previousText = text
+	}
+	if event.Kind != crawler.ChangeDisappeared {
+		text, err := s.watchedText(rule, event.Current)
+		if err != nil {
+			return nil, false, err
+		}
+		currentText = text
+	}
+	if previousText == currentText {
+		return nil, false, nil
+	}
+
+	keyword := strings.ToLower(rule.Keyword)
+	keywordFound := keyword == ""
+	changedChars := 0
+	edits := make([]Edit, 0)
+	for _, edit := range crawler.Diff(previousText, currentText, crawler.DiffWords) {
+		if edit.Op == crawler.DiffEqual {
+			continue
+		}
+		changedChars += utf8.RuneCountInString(edit.Text)
+		edits = append(edits, Edit{Op: edit.Op, Text: edit.Text})
+		if !keywordFound && strings.Contains(strings.ToLower(edit.Text), keyword) {
+			keywordFound = true
+		}
+	}
+	if !keywordFound || changedChars < rule.MinChange {
+		return nil, false, nil
+	}
+
+	payload := &Payload{
+		DeliveryID:     deliveryID.String(),
+		RuleID:         rule.ID.String(),
+		Rule:           rule.Name,
+		Event:          event.Kind,
+		URL:            event.URL,
+		Title:          event.Current.Title,
+		CurrentVersion: event.Current.Version,
+		StatusCode:     event.Current.StatusCode,
+		ChangedChars:   changedChars,
+		OccurredAt:     event.Current.FetchedAt,
+	}
+	if event.Previous != nil {
+		payload.PreviousVersion = event.Previous.Version
+		if event.Kind == crawler.ChangeDisappeared {
+			payload.Title = event.Previous.Title
+		}
+	}
+	// The whole content of pages that appear or disappear is not repeated in the payload
+	if event.Kind == crawler.ChangeChanged {
+		payload.Edits = edits
+	}
+
+	return payload, true, nil
+}
+
+// watchedText returns the content of a version watched by a rule, the text of the elements
+// matching its selector or the plain text of the page
+func (s *WatchService) watchedText(rule *Rule, version *crawler.PageVersion) (string, error) {
+	if rule.Selector == "" {
+		return version.PlainText, nil
+	}
+	textResult := s.selector.SelectText(version.HTML, rule.Selector)
+	if textResult.IsErr() {
+		return "", textResult.Error()
+	}
+	return textResult.Unwrap(), nil
+}
+
+func (s *WatchService) validate(rule *Rule) error {
+	if strings.TrimSpace(rule.URLPattern) == "" {
+		return fmt.Errorf("%w: URL pattern is required", ErrInvalidRule)
+	}
+
+	webhook, err := url.Parse(rule.WebhookURL)
+	if err != nil || (webhook.Scheme != "http" && webhook.Scheme != "https") || webhook.Host == "" {
+		return fmt.Errorf("%w: webhook URL must be an absolute http or https URL", ErrInvalidRule)
+	}
+
+	if rule.Selector != "" {
+		if selectResult := s.selector.SelectText("", rule.Selector); selectResult.IsErr() {
+			return fmt.Errorf("%w: %v", ErrInvalidRule, selectResult.Error())
+		}
+	}
+
+	if rule.MinChange < 0 {
+		return fmt.Errorf("%w: minimum change must not be negative", ErrInvalidRule)
+	}
+
+	for _, event := range rule.Events {
+		switch event {
+		case crawler.ChangeAppeared, crawler.ChangeChanged, crawler.ChangeDisappeared:
+		default:
+			return fmt.Errorf("%w: unknown event %q", ErrInvalidRule, event)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,344 @@
+package watch_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
+	"github.com/gerthdala/webcrawler/internal/domain/watch"
+	"github.com/gerthdala/webcrawler/internal/infrastructure/webhook"
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+	"github.com/google/uuid"
+)
+
+type memoryRules struct {
+	rules []watch.Rule
+}
+
+func (m *memoryRules) Save(ctx context.Context, rule *watch.Rule) result.Result[*watch.Rule] {
+	m.rules = append(m.rules, *rule)
+	return result.Ok(rule)
+}
+
+func (m *memoryRules) FindByID(ctx context.Context, id uuid.UUID) result.Result[*watch.Rule] {
+	for i := range m.rules {
+		if m.rules[i].ID == id {
+			return result.Ok(&m.rules[i])
+		}
+	}
+	return result.Err[*watch.Rule](errors.New("rule not found"))
+}
+
+func (m *memoryRules) FindAll(ctx context.Context) result.Result[[]watch.Rule] {
+	return result.Ok(m.rules)
+}
+
+func (m *memoryRules) FindEnabled(ctx context.Context) result.Result[[]watch.Rule] {
+	enabled := make([]watch.Rule, 0)
+	for _, rule := range m.rules {
+		if rule.Enabled {
+			enabled = append(enabled, rule)
+		}
+	}
+	return result.Ok(enabled)
+}
+
+func (m *memoryRules) Delete(ctx context.Context, id uuid.UUID) result.Result[bool] {
+	return result.Ok(false)
+}
+
+type memoryDeliveries struct {
+	deliveries []watch.Delivery
+	attempts   []watch.DeliveryAttempt
+}
+
+func (m *memoryDeliveries) Save(ctx context.Context, delivery *watch.Delivery) result.Result[*watch.Delivery] {
+	m.deliveries = append(m.deliveries, *delivery)
+	return result.Ok(delivery)
+}
+
+func (m *memoryDeliveries) Update(ctx context.Context, delivery *watch.Delivery) result.Result[*watch.Delivery] {
+	return result.Ok(delivery)
+}
+
+func (m *memoryDeliveries) RecordAttempt(ctx context.Context, delivery *watch.Delivery, attempt *watch.DeliveryAttempt) result.Result[*watch.Delivery] {
+	m.attempts = append(m.attempts, *attempt)
+	return result.Ok(delivery)
+}
+
+func (m *memoryDeliveries) FindAttempts(ctx context.Context, deliveryID uuid.UUID) result.Result[[]watch.DeliveryAttempt] {
+	attempts := make([]watch.DeliveryAttempt, 0)
+	for _, attempt := range m.attempts {
+		if attempt.DeliveryID == deliveryID {
+			attempts = append(attempts, attempt)
+		}
+	}
+	return result.Ok(attempts)
+}
+
+func (m *memoryDeliveries) FindDue(ctx context.Context, now time.Time, limit int) result.Result[[]watch.Delivery] {
+	return result.Ok(m.deliveries)
+}
+
+func (m *memoryDeliveries) FindByRule(ctx context.Context, ruleID uuid.UUID, limit int) result.Result[[]watch.Delivery] {
+	return result.Ok(m.deliveries)
+}
+
+// receiver is a webhook endpoint answering with the status codes it is given in turn, then 200
+type receiver struct {
+	server   *httptest.Server
+	statuses []int
+	calls    atomic.Int32
+	secret   string
+	verified atomic.Int32
+}
+
+func newReceiver(t *testing.T, secret string, statuses ...int) *receiver {
+	r := &receiver{statuses: statuses, secret: secret}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		call := int(r.calls.Add(1)) - 1
+
+		body, _ := io.ReadAll(req.Body)
+		timestamp, err := strconv.ParseInt(req.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		if err == nil && webhook.Verify(r.secret, req.Header.Get(webhook.HeaderSignature), timestamp, body, time.Minute) {
+			r.verified.Add(1)
+		}
+
+		if call < len(r.statuses) {
+			w.WriteHeader(r.statuses[call])
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func newService(rules *memoryRules, deliveries *memoryDeliveries, config watch.WatchServiceConfig) *watch.WatchService {
+	return watch.NewWatchService(rules, deliveries, webhook.NewSender(webhook.SenderConfig{}), nil, config)
+}
+
+func newDelivery(t *testing.T, rules *memoryRules, webhookURL, secret string) *watch.Delivery {
+	rule := watch.NewRule("test", "https://example.com/*", webhookURL, secret)
+	rules.Save(context.Background(), rule)
+	return watch.NewDelivery(uuid.New(), rule.ID, crawler.ChangeChanged, "https://example.com/a", []byte(`{"event":"changed"}`))
+}
+
+func TestDeliverSigned(t *testing.T) {
+	rules, deliveries := &memoryRules{}, &memoryDeliveries{}
+	recv := newReceiver(t, "secret")
+	delivery := newDelivery(t, rules, recv.server.URL, "secret")
+
+	deliverResult := newService(rules, deliveries, watch.WatchServiceConfig{}).Deliver(context.Background(), delivery)
+	if deliverResult.IsErr() {
+		t.Fatalf("Deliver() error = %v", deliverResult.Error())
+	}
+
+	if delivery.Status != watch.DeliveryDelivered {
+		t.Errorf("status = %s, want %s", delivery.Status, watch.DeliveryDelivered)
+	}
+	if recv.verified.Load() != 1 {
+		t.Error("the receiver could not verify the signature of the webhook")
+	}
+	if len(deliveries.attempts) != 1 || deliveries.attempts[0].StatusCode != http.StatusOK || deliveries.attempts[0].Attempt != 1 {
+		t.Errorf("attempts = %+v, want one successful attempt", deliveries.attempts)
+	}
+}
+
+func TestDeliverRetriesServerErrors(t *testing.T) {
+	rules, deliveries := &memoryRules{}, &memoryDeliveries{}
+	recv := newReceiver(t, "secret", http.StatusInternalServerError, http.StatusBadGateway)
+	delivery := newDelivery(t, rules, recv.server.URL, "secret")
+	service := newService(rules, deliveries, watch.WatchServiceConfig{InitialBackoff: time.Second})
+
+	for i, want := range []watch.DeliveryStatus{watch.DeliveryPending, watch.DeliveryPending, watch.DeliveryDelivered} {
+		if deliverResult := service.Deliver(context.Background(), delivery); deliverResult.IsErr() {
+			t.Fatalf("Deliver() error = %v", deliverResult.Error())
+		}
+		if delivery.Status != want {
+			t.Fatalf("status after attempt %d = %s, want %s", i+1, delivery.Status, want)
+		}
+	}
+
+	if recv.calls.Load() != 3 {
+		t.Errorf("webhook called %d times, want 3", recv.calls.Load())
+	}
+	wantCodes := []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK}
+	if len(deliveries.attempts) != len(wantCodes) {
+		t.Fatalf("%d attempts logged, want %d", len(deliveries.attempts), len(wantCodes))
+	}
+	for i, attempt := range deliveries.attempts {
+		if attempt.Attempt != i+1 || attempt.StatusCode != wantCodes[i] {
+			t.Errorf("attempt %d = %+v, want status %d", i+1, attempt, wantCodes[i])
+		}
+		if (attempt.Error == "") != (wantCodes[i] == http.StatusOK) {
+			t.Errorf("attempt %d error = %q", i+1, attempt.Error)
+		}
+	}
+}
+
+func TestDeliverRetryableStatuses(t *testing.T) {
+	tests := []struct {
+		status int
+		want   watch.DeliveryStatus
+	}{
+		{http.StatusInternalServerError, watch.DeliveryPending},
+		{http.StatusServiceUnavailable, watch.DeliveryPending},
+		{http.StatusRequestTimeout, watch.DeliveryPending},
+		{http.StatusTooManyRequests, watch.DeliveryPending},
+		{http.StatusBadRequest, watch.DeliveryFailed},
+		{http.StatusUnauthorized, watch.DeliveryFailed},
+		{http.StatusNotFound, watch.DeliveryFailed},
+		{http.StatusGone, watch.DeliveryFailed},
+		{http.StatusMovedPermanently, watch.DeliveryFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			rules, deliveries := &memoryRules{}, &memoryDeliveries{}
+			recv := newReceiver(t, "secret", tt.status)
+			delivery := newDelivery(t, rules, recv.server.URL, "secret")
+
+			newService(rules, deliveries, watch.WatchServiceConfig{}).Deliver(context.Background(), delivery)
+			if delivery.Status != tt.want {
+				t.Errorf("status after %d = %s, want %s", tt.status, delivery.Status, tt.want)
+			}
+			if delivery.LastStatusCode != tt.status {
+				t.Errorf("last status code = %d, want %d", delivery.LastStatusCode, tt.status)
+			}
+		})
+	}
+}
+
+func TestDeliverRetriesUnreachableWebhooks(t *testing.T) {
+	rules, deliveries := &memoryRules{}, &memoryDeliveries{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+	delivery := newDelivery(t, rules, server.URL, "secret")
+
+	newService(rules, deliveries, watch.WatchServiceConfig{}).Deliver(context.Background(), delivery)
+	if delivery.Status != watch.DeliveryPending {
+		t.Errorf("status = %s, want %s", delivery.Status, watch.DeliveryPending)
+	}
+	if len(deliveries.attempts) != 1 || deliveries.attempts[0].StatusCode != 0 || deliveries.attempts[0].Error == "" {
+		t.Errorf("attempts = %+v, want one attempt without status and with an error", deliveries.attempts)
+	}
+}
+
+func TestDeliverBackoff(t *testing.T) {
+	rules, deliveries := &memoryRules{}, &memoryDeliveries{}
+	statuses := make([]int, 10)
+	for i := range statuses {
+		statuses[i] = http.StatusServiceUnavailable
+	}
+	recv := newReceiver(t, "secret", statuses...)
+	delivery := newDelivery(t, rules, recv.server.URL, "secret")
+	service := newService(rules, deliveries, watch.WatchServiceConfig{
+		MaxAttempts:    6,
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Second,
+	})
+
+	// The backoff doubles from the initial backoff up to the maximum, and the last attempt is not retried
+	wantBackoffs := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, want := range wantBackoffs {
+		service.Deliver(context.Background(), delivery)
+		if delivery.Status != watch.DeliveryPending {
+			t.Fatalf("status after attempt %d = %s, want %s", i+1, delivery.Status, watch.DeliveryPending)
+		}
+		if got := delivery.NextAttemptAt.Sub(delivery.LastAttemptAt); got != want {
+			t.Errorf("backoff after attempt %d = %v, want %v", i+1, got, want)
+		}
+	}
+
+	service.Deliver(context.Background(), delivery)
+	if delivery.Status != watch.DeliveryFailed {
+		t.Errorf("status after %d attempts = %s, want %s", delivery.Attempts, delivery.Status, watch.DeliveryFailed)
+	}
+	if len(deliveries.attempts) != 6 {
+		t.Errorf("%d attempts logged, want 6", len(deliveries.attempts))
+	}
+}
+
+func TestNotifyChangeMatching(t *testing.T) {
+	previous := &crawler.PageVersion{Version: 1, StatusCode: 200, PlainText: "Price: 10 euros. In stock."}
+	current := &crawler.PageVersion{Version: 2, StatusCode: 200, PlainText: "Price: 12 euros. In stock."}
+
+	tests := []struct {
+		name      string
+		keyword   string
+		minChange int
+		events    []crawler.ChangeKind
+		event     crawler.PageChangeEvent
+		want      int
+	}{
+		{"any change", "", 0, nil, crawler.PageChangeEvent{Kind: crawler.ChangeChanged, URL: "https://example.com/p", Previous: previous, Current: current}, 1},
+		{"other URL", "", 0, nil, crawler.PageChangeEvent{Kind: crawler.ChangeChanged, URL: "https://other.com/p", Previous: previous, Current: current}, 0},
+		{"unchanged text", "", 0, nil, crawler.PageChangeEvent{Kind: crawler.ChangeChanged, URL: "https://example.com/p", Previous: previous, Current: previous}, 0},
+		{"keyword in edit", "12", 0, nil, crawler.PageChangeEvent{Kind: crawler.ChangeChanged, URL: "https://example.com/p", Previous: previous, Current: current}, 1},
+		{"keyword case-insensitive", "EUROS", 0, nil, crawler.PageChangeEvent{Kind: crawler.ChangeAppeared, URL: "https://example.com/p", Current: current}, 1},
+		{"keyword outside edits", "stock", 0, nil, crawler.PageChangeEvent{Kind: crawler.ChangeChanged, URL: "https://example.com/p", Previous: previous, Current: current}, 0},
+		{"change below minimum", "", 7, nil, crawler.PageChangeEvent{Kind: crawler.ChangeChanged, URL: "https://example.com/p", Previous: previous, Current: current}, 0},
+		{"change at minimum", "", 6, nil, crawler.PageChangeEvent{Kind: crawler.ChangeChanged, URL: "https://example.com/p", Previous: previous, Current: current}, 1},
+		{"event not watched", "", 0, []crawler.ChangeKind{crawler.ChangeDisappeared}, crawler.PageChangeEvent{Kind: crawler.ChangeChanged, URL: "https://example.com/p", Previous: previous, Current: current}, 0},
+		{"event watched", "", 0, []crawler.ChangeKind{crawler.ChangeDisappeared}, crawler.PageChangeEvent{Kind: crawler.ChangeDisappeared, URL: "https://example.com/p", Previous: previous, Current: &crawler.PageVersion{Version: 2, StatusCode: 404}}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, deliveries := &memoryRules{}, &memoryDeliveries{}
+			rule := watch.NewRule("test", "https://example.com/*", "https://hooks.example.org/", "secret")
+			rule.Keyword = tt.keyword
+			rule.MinChange = tt.minChange
+			rule.Events = tt.events
+			rules.Save(context.Background(), rule)
+
+			notifyResult := newService(rules, deliveries, watch.WatchServiceConfig{}).NotifyChange(context.Background(), tt.event)
+			if notifyResult.IsErr() {
+				t.Fatalf("NotifyChange() error = %v", notifyResult.Error())
+			}
+			if got := notifyResult.Unwrap(); got != tt.want {
+				t.Errorf("NotifyChange() queued %d deliveries, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifyChangePayload(t *testing.T) {
+	rules, deliveries := &memoryRules{}, &memoryDeliveries{}
+	rule := watch.NewRule("pricing", "https://example.com/*", "https://hooks.example.org/", "secret")
+	rules.Save(context.Background(), rule)
+
+	event := crawler.PageChangeEvent{
+		Kind:     crawler.ChangeChanged,
+		URL:      "https://example.com/p",
+		Previous: &crawler.PageVersion{Version: 1, StatusCode: 200, PlainText: "Price: 10 euros"},
+		Current:  &crawler.PageVersion{Version: 2, StatusCode: 200, Title: "Pricing", PlainText: "Price: 12 euros"},
+	}
+	newService(rules, deliveries, watch.WatchServiceConfig{}).NotifyChange(context.Background(), event)
+
+	if len(deliveries.deliveries) != 1 {
+		t.Fatalf("%d deliveries queued, want 1", len(deliveries.deliveries))
+	}
+	var payload watch.Payload
+	if err := json.Unmarshal(deliveries.deliveries[0].Payload, &payload); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	if payload.DeliveryID != deliveries.deliveries[0].ID.String() || payload.Rule != "pricing" || payload.Title != "Pricing" {
+		t.Errorf("payload = %+v", payload)
+	}
+	if payload.PreviousVersion != 1 || payload.CurrentVersion != 2 || payload.ChangedChars != 6 {
+		t.Errorf("payload versions and changed chars = %d, %d, %d, want 1, 2, 6", payload.PreviousVersion, payload.CurrentVersion, payload.ChangedChars)
+	}
+	if len(payload.Edits) != 2 {
+		t.Errorf("payload edits = %+v, want a deletion and an insertion", payload.Edits)
+	}
+}
@@ -0,0 +1,131 @@
+package crawler
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// fallbackCharset is the charset of HTML documents that declare none and are not UTF-8
+const fallbackCharset = "windows-1252"
+
+// sniffSampleSize is the number of bytes of a body the charset is sniffed from
+const sniffSampleSize = 64 << 10
+
+// charsetCandidate is a multibyte charset recognized by sniffing, from the characters most frequent in its language
+type charsetCandidate struct {
+	name     string
+	encoding encoding.Encoding
+	frequent func(r rune) bool
+}
+
+// sniffCandidates are the charsets sniffed from undeclared bodies, ties going to the first
+var sniffCandidates = []charsetCandidate{
+	{name: "shift_jis", encoding: japanese.ShiftJIS, frequent: isKana},
+	{name: "euc-jp", encoding: japanese.EUCJP, frequent: isKana},
+	{name: "gbk", encoding: simplifiedchinese.GBK, frequent: runeIn("的一是不了在人有我他这个们中来上大为和国地到以说时要就出会也你对生能而子那得于着下自之年过发后作里")},
+	{name: "big5", encoding: traditionalchinese.Big5, frequent: runeIn("的一是不了在人有我他這個們中來上大為和國地到以說時要就出會也你對生能而子那得於著下自之年過發後作裡")},
+	{name: "euc-kr", encoding: korean.EUCKR, frequent: runeIn("이다는의에하고을가지기한로서도를사은으리자대인정수시아나어라해있것게")},
+}
+
+// minSniffScore is the share of frequent characters a sniffed charset must decode a body to
+const minSniffScore = 0.1
+
+// isTextContentType checks if a content type is text, and has a charset
+func isTextContentType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	return mediaType == "" || strings.HasPrefix(mediaType, "text/") ||
+		strings.Contains(mediaType, "html") || strings.Contains(mediaType, "xml")
+}
+
+// decodeBody transcodes a text body to UTF-8 and returns it with the name of its charset
+func decodeBody(body []byte, contentType string) (string, string) {
+	enc, name := detectCharset(body, contentType)
+	if name == "utf-8" {
+		return strings.TrimPrefix(string(body), "\uFEFF"), name
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return string(body), name
+	}
+	return string(decoded), name
+}
+
+// detectCharset returns the charset of a body, from its BOM, the Content-Type header or its <meta> tags,
+// and sniffed from its bytes when none of them declares one
+func detectCharset(body []byte, contentType string) (encoding.Encoding, string) {
+	// The BOM and the header are authoritative
+	if enc, name, certain := charset.DetermineEncoding(body, contentType); certain {
+		return enc, name
+	}
+
+	// Then the <meta charset> or http-equiv of the first 1024 bytes. Without one, UTF-8 is
+	// assumed for a valid prefix and windows-1252 otherwise, which the whole body may contradict.
+	enc, name, _ := charset.DetermineEncoding(body, "text/html")
+	if name != fallbackCharset && (name != "utf-8" || utf8.Valid(body)) {
+		return enc, name
+	}
+
+	if candidate, ok := sniffCharset(body); ok {
+		return candidate.encoding, candidate.name
+	}
+	return enc, name
+}
+
+// sniffCharset returns the multibyte charset decoding a body with the largest share of characters
+// frequent in its language, if any decodes it without errors to enough of them
+func sniffCharset(body []byte) (charsetCandidate, bool) {
+	if len(body) > sniffSampleSize {
+		body = body[:sniffSampleSize]
+	}
+
+	var best charsetCandidate
+	bestScore := 0.0
+	for _, candidate := range sniffCandidates {
+		decoded, err := candidate.encoding.NewDecoder().Bytes(body)
+		if err != nil {
+			continue
+		}
+
+		multibyte, invalid, frequent := 0, 0, 0
+		for _, r := range string(decoded) {
+			switch {
+			case r < utf8.RuneSelf:
+				continue
+			case r == utf8.RuneError:
+				invalid++
+			case candidate.frequent(r):
+				frequent++
+			}
+			multibyte++
+		}
+		// A sample cut in the middle of a character ends with an error, others are not this charset
+		if multibyte == 0 || invalid > 1+multibyte/100 {
+			continue
+		}
+
+		if score := float64(frequent) / float64(multibyte); score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+	return best, bestScore >= minSniffScore
+}
+
+// isKana checks if a rune is hiragana or katakana
+func isKana(r rune) bool {
+	return r >= 0x3041 && r <= 0x30FF
+}
+
+// runeIn returns a function checking if a rune is one of chars
+func runeIn(chars string) func(r rune) bool {
+	return func(r rune) bool {
+		return strings.ContainsRune(chars, r)
+	}
+}
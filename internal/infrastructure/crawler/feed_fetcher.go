@@ -0,0 +1,248 @@
+package crawler
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+)
+
+// feedDateLayouts are the date formats of RSS (RFC 822) and Atom (RFC 3339) feeds
+var feedDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC3339,
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// FeedFetcher implements the FeedFetcherService interface for RSS 2.0, RSS 1.0 and Atom feeds
+type FeedFetcher struct {
+	client    *http.Client
+	userAgent string
+	maxBytes  int64
+}
+
+// FeedFetcherConfig configuration for the feed fetcher
+type FeedFetcherConfig struct {
+	UserAgent string
+	Timeout   time.Duration
+	// MaxBytes bounds the size of a feed, 10 MB by default
+	MaxBytes int64
+}
+
+// NewFeedFetcher creates a new FeedFetcher
+func NewFeedFetcher(config FeedFetcherConfig) *FeedFetcher {
+	maxBytes := config.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = 10 << 20
+	}
+
+	return &FeedFetcher{
+		client:    &http.Client{Timeout: config.Timeout},
+		userAgent: config.UserAgent,
+		maxBytes:  maxBytes,
+	}
+}
+
+// FetchFeed fetches a feed with If-None-Match and If-Modified-Since set from the validators
+// of its last poll, and parses its items when it has changed
+func (f *FeedFetcher) FetchFeed(ctx context.Context, feed *crawler.Feed) result.Result[*crawler.FeedFetch] {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feed.URL, nil)
+	if err != nil {
+		return result.Err[*crawler.FeedFetch](fmt.Errorf("error creating request: %w", err))
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/rdf+xml;q=0.9, application/xml;q=0.8, text/xml;q=0.8")
+	if feed.ETag != "" {
+		req.Header.Set("If-None-Match", feed.ETag)
+	}
+	if feed.LastModified != "" {
+		req.Header.Set("If-Modified-Since", feed.LastModified)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return result.Err[*crawler.FeedFetch](fmt.Errorf("error fetching feed %s: %w", feed.URL, err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return result.Ok(&crawler.FeedFetch{NotModified: true})
+	}
+	if resp.StatusCode != http.StatusOK {
+		return result.Err[*crawler.FeedFetch](fmt.Errorf("unexpected status %d fetching feed %s", resp.StatusCode, feed.URL))
+	}
+
+	base, err := url.Parse(feed.URL)
+	if err != nil {
+		return result.Err[*crawler.FeedFetch](fmt.Errorf("invalid feed URL %s: %w", feed.URL, err))
+	}
+
+	fetch, err := parseFeed(io.LimitReader(resp.Body, f.maxBytes), base)
+	if err != nil {
+		return result.Err[*crawler.FeedFetch](fmt.Errorf("error parsing feed %s: %w", feed.URL, err))
+	}
+	fetch.ETag = resp.Header.Get("ETag")
+	fetch.LastModified = resp.Header.Get("Last-Modified")
+
+	return result.Ok(fetch)
+}
+
+// feedEntryElement is an RSS <item> or an Atom <entry>
+type feedEntryElement struct {
+	Title string `xml:"title"`
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+		Text string `xml:",chardata"`
+	} `xml:"link"`
+	GUID      string `xml:"guid"`
+	ID        string `xml:"id"`
+	About     string `xml:"about,attr"`
+	PubDate   string `xml:"pubDate"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Date      string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Authors   []struct {
+		Name string `xml:"name"`
+		Text string `xml:",chardata"`
+	} `xml:"author"`
+	Creator    string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Categories []struct {
+		Term string `xml:"term,attr"`
+		Text string `xml:",chardata"`
+	} `xml:"category"`
+}
+
+// parseFeed streams the items of an RSS or Atom feed, resolving links against base
+func parseFeed(body io.Reader, base *url.URL) (*crawler.FeedFetch, error) {
+	decoder := xml.NewDecoder(body)
+	fetch := &crawler.FeedFetch{Items: make([]crawler.FeedItem, 0)}
+	parents := make([]string, 0)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			parent := ""
+			if len(parents) > 0 {
+				parent = parents[len(parents)-1]
+			}
+
+			switch {
+			case t.Name.Local == "item" || t.Name.Local == "entry":
+				var entry feedEntryElement
+				if err := decoder.DecodeElement(&entry, &t); err != nil {
+					return nil, err
+				}
+				if item, ok := feedItem(entry, base); ok {
+					fetch.Items = append(fetch.Items, item)
+				}
+			case t.Name.Local == "title" && (parent == "channel" || parent == "feed"):
+				var title string
+				if err := decoder.DecodeElement(&title, &t); err != nil {
+					return nil, err
+				}
+				fetch.Title = strings.TrimSpace(title)
+			default:
+				parents = append(parents, t.Name.Local)
+			}
+		case xml.EndElement:
+			if len(parents) > 0 {
+				parents = parents[:len(parents)-1]
+			}
+		}
+	}
+
+	if len(parents) > 0 || (fetch.Title == "" && len(fetch.Items) == 0) {
+		return nil, fmt.Errorf("not an RSS or Atom feed")
+	}
+
+	return fetch, nil
+}
+
+// feedItem converts an item or entry, reporting false when it has no link
+func feedItem(entry feedEntryElement, base *url.URL) (crawler.FeedItem, bool) {
+	item := crawler.FeedItem{
+		FeedURL: base.String(),
+		Title:   strings.TrimSpace(entry.Title),
+	}
+
+	// Atom entries link with href attributes, preferring rel="alternate"; RSS items with text
+	for _, link := range entry.Links {
+		href := strings.TrimSpace(link.Href)
+		if href == "" {
+			href = strings.TrimSpace(link.Text)
+		}
+		if href == "" || (link.Rel != "" && link.Rel != "alternate") {
+			continue
+		}
+		if u, err := url.Parse(href); err == nil {
+			item.Link = base.ResolveReference(u).String()
+			break
+		}
+	}
+	if item.Link == "" {
+		return item, false
+	}
+
+	item.GUID = firstNonEmpty(entry.GUID, entry.ID, entry.About, item.Link)
+	item.Published = parseFeedDate(firstNonEmpty(entry.PubDate, entry.Published, entry.Date, entry.Updated))
+
+	for _, author := range entry.Authors {
+		if name := firstNonEmpty(author.Name, author.Text); name != "" {
+			item.Author = name
+			break
+		}
+	}
+	if item.Author == "" {
+		item.Author = strings.TrimSpace(entry.Creator)
+	}
+
+	for _, category := range entry.Categories {
+		if name := firstNonEmpty(category.Term, category.Text); name != "" {
+			item.Categories = append(item.Categories, name)
+		}
+	}
+
+	return item, true
+}
+
+// parseFeedDate parses an RSS or Atom date, returning the zero time when it is malformed
+func parseFeedDate(value string) time.Time {
+	for _, layout := range feedDateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed
+		}
+	}
+	return time.Time{}
+}
+
+// firstNonEmpty returns the first of values that is not blank, trimmed
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
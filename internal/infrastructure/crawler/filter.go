@@ -2,99 +2,218 @@ package crawler
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"regexp"
 	"strings"
 	"sync"
 
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
 	result "github.com/gerthdala/webcrawler/pkg/utils/result"
 )
 
+// DomainScope is how an allowed domain matches hostnames
+type DomainScope string
+
+const (
+	// ScopeHost matches the exact host only
+	ScopeHost DomainScope = "host"
+	// ScopeSubdomains matches the host and its subdomains
+	ScopeSubdomains DomainScope = "subdomains"
+	// ScopeRegistrableDomain matches every host sharing its registrable domain (eTLD+1)
+	ScopeRegistrableDomain DomainScope = "registrable"
+)
+
 // URLFilter implements the URLFilterService interface
 type URLFilter struct {
 	allowedDomains    []string
+	domainScope       DomainScope
+	maxOffDomainHops  int
 	allowedExtensions []string
-	disallowedPaths   []string
+	// disallowed are the configured disallowed paths, which rule updates do not replace
+	disallowed        []compiledRule
+	rules             crawler.FilterRules
+	include           []compiledRule
+	exclude           []compiledRule
 	allowedContentTypes []string
 	maxURLLength      int
 	mu                sync.RWMutex
 }
 
+// compiledRule is a FilterRule with its compiled pattern
+type compiledRule struct {
+	field   crawler.RuleField
+	pattern *regexp.Regexp
+}
+
 // URLFilterConfig configuration for the URL filter
 type URLFilterConfig struct {
 	AllowedDomains    []string
+	// DomainScope is how AllowedDomains match hostnames, ScopeSubdomains by default
+	DomainScope DomainScope
+	// MaxOffDomainHops is how many consecutive links outside AllowedDomains are followed
+	MaxOffDomainHops int
 	AllowedExtensions []string
+	// DisallowedPaths are path patterns always excluded, in addition to the exclude rules of Rules
+	// and whatever they are updated to
 	DisallowedPaths   []string
+	Rules             crawler.FilterRules
 	AllowedContentTypes []string
 	MaxURLLength      int
 }
 
-// NewURLFilter creates a new URLFilter
-func NewURLFilter(config URLFilterConfig) *URLFilter {
-	return &URLFilter{
-		allowedDomains:    config.AllowedDomains,
+// NewURLFilter creates a new URLFilter, failing on invalid filter rules
+func NewURLFilter(config URLFilterConfig) result.Result[*URLFilter] {
+	scope := config.DomainScope
+	if scope == "" {
+		scope = ScopeSubdomains
+	}
+
+	allowedDomains := make([]string, 0, len(config.AllowedDomains))
+	for _, domain := range config.AllowedDomains {
+		if domain = normalizeHost(strings.TrimPrefix(strings.TrimPrefix(domain, "*"), ".")); domain == "" {
+			continue
+		}
+		if scope == ScopeRegistrableDomain {
+			domain = registrableDomain(domain)
+		}
+		allowedDomains = append(allowedDomains, domain)
+	}
+
+	disallowedRules := make([]crawler.FilterRule, len(config.DisallowedPaths))
+	for i, pathPattern := range config.DisallowedPaths {
+		disallowedRules[i] = crawler.FilterRule{Field: crawler.FieldPath, Pattern: pathPattern}
+	}
+	disallowed, err := compileRuleSet("disallowed path", disallowedRules)
+	if err != nil {
+		return result.Err[*URLFilter](err)
+	}
+
+	rules := config.Rules
+	include, exclude, err := compileRules(rules)
+	if err != nil {
+		return result.Err[*URLFilter](err)
+	}
+
+	return result.Ok(&URLFilter{
+		allowedDomains:    allowedDomains,
+		domainScope:       scope,
+		maxOffDomainHops:  max(config.MaxOffDomainHops, 0),
 		allowedExtensions: config.AllowedExtensions,
-		disallowedPaths:   config.DisallowedPaths,
+		disallowed:        disallowed,
+		rules:             rules,
+		include:           include,
+		exclude:           exclude,
 		allowedContentTypes: config.AllowedContentTypes,
 		maxURLLength:      config.MaxURLLength,
+	})
+}
+
+// Rules returns the include and exclude rules in use
+func (f *URLFilter) Rules() crawler.FilterRules {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.rules
+}
+
+// UpdateRules validates and replaces the include and exclude rules at once, keeping
+// the rules in use when any of the new ones is invalid. The configured disallowed paths
+// are not part of the rules and stay excluded.
+func (f *URLFilter) UpdateRules(rules crawler.FilterRules) result.Result[bool] {
+	include, exclude, err := compileRules(rules)
+	if err != nil {
+		return result.Err[bool](err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.rules = rules
+	f.include = include
+	f.exclude = exclude
+	return result.Ok(true)
+}
+
+// ShouldCrawl checks if a URL reached after offDomainHops consecutive links outside the allowed domains should be crawled
+func (f *URLFilter) ShouldCrawl(ctx context.Context, urlStr string, depth, offDomainHops int) result.Result[bool] {
+	if _, err := url.Parse(urlStr); err != nil {
+		return result.Err[bool](err)
 	}
+	return result.Ok(f.Explain(ctx, urlStr, depth, offDomainHops).Allowed())
 }
 
-func (f *URLFilter) ShouldCrawl(ctx context.Context, urlStr string, depth int) result.Result[bool] {
+// Explain runs the checks of ShouldCrawl in order and returns their decisions, up to the first denial
+func (f *URLFilter) Explain(ctx context.Context, urlStr string, depth, offDomainHops int) crawler.FilterTrace {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
 
+	trace := make(crawler.FilterTrace, 0, 5)
+
 	parsedURL, err := url.Parse(urlStr)
 	if err != nil {
-		return result.Err[bool](err)
+		return append(trace, crawler.Deny(crawler.CheckURL, "malformed URL: %v", err))
 	}
-	
+
 	if f.maxURLLength > 0 && len(urlStr) > f.maxURLLength {
-		return result.Ok[bool](false)
+		return append(trace, crawler.Deny(crawler.CheckLength, "URL length %d exceeds the maximum of %d", len(urlStr), f.maxURLLength))
 	}
+	trace = append(trace, crawler.Allow(crawler.CheckLength, "URL length %d is within the maximum", len(urlStr)))
 
-	if len(f.allowedDomains) > 0 {
-		domainAllowed := false
-		for _, domain := range f.allowedDomains {
-			if strings.HasSuffix(parsedURL.Hostname(), domain) {
-				domainAllowed = true
+	host := parsedURL.Hostname()
+	switch {
+	case len(f.allowedDomains) == 0:
+		trace = append(trace, crawler.Allow(crawler.CheckDomain, "no allowed domains are set"))
+	case f.inScope(host):
+		trace = append(trace, crawler.Allow(crawler.CheckDomain, "host %s matches the allowed domains (%s scope)", host, f.domainScope))
+	case offDomainHops <= 0:
+		return append(trace, crawler.Deny(crawler.CheckDomain, "host %s is outside the allowed domains (%s scope)", host, f.domainScope))
+	// Off-domain URLs are only crawled when linked from within the hop limit
+	case offDomainHops > f.maxOffDomainHops:
+		return append(trace, crawler.Deny(crawler.CheckDomain, "host %s is %d off-domain hops away, over the limit of %d", host, offDomainHops, f.maxOffDomainHops))
+	default:
+		trace = append(trace, crawler.Allow(crawler.CheckDomain, "host %s is %d off-domain hops away, within the limit of %d", host, offDomainHops, f.maxOffDomainHops))
+	}
+
+	ext := getExtension(parsedURL.Path)
+	if len(f.allowedExtensions) > 0 && ext != "" {
+		extAllowed := false
+		for _, allowedExt := range f.allowedExtensions {
+			if strings.EqualFold(ext, allowedExt) {
+				extAllowed = true
 				break
 			}
 		}
-		if !domainAllowed {
-			return result.Ok[bool](false)
+		if !extAllowed {
+			return append(trace, crawler.Deny(crawler.CheckExtension, "extension %q is not allowed", ext))
 		}
+		trace = append(trace, crawler.Allow(crawler.CheckExtension, "extension %q is allowed", ext))
+	} else {
+		trace = append(trace, crawler.Allow(crawler.CheckExtension, "no extension restriction applies"))
 	}
 
-	if len(f.allowedExtensions) > 0 {
-		ext := getExtension(parsedURL.Path)
-		if ext != "" {
-			extAllowed := false
-			for _, allowedExt := range f.allowedExtensions {
-				if strings.EqualFold(ext, allowedExt) {
-					extAllowed = true
-					break
-				}
-			}
-			if !extAllowed {
-				return result.Ok[bool](false)
-			}
-		}
+	if rule, matched := firstMatch(f.disallowed, urlStr, parsedURL); matched {
+		return append(trace, crawler.Deny(crawler.CheckExcludeRules, "path matches disallowed path %q", rule.pattern))
+	}
+	if rule, matched := firstMatch(f.exclude, urlStr, parsedURL); matched {
+		return append(trace, crawler.Deny(crawler.CheckExcludeRules, "%s matches exclude rule %q", rule.field, rule.pattern))
 	}
+	trace = append(trace, crawler.Allow(crawler.CheckExcludeRules, "no exclude rule matches"))
 
-	for _, pathPattern := range f.disallowedPaths {
-		matched, err := regexp.MatchString(pathPattern, parsedURL.Path)
-		if err != nil {
-			continue
-		}
-		if matched {
-			return result.Ok[bool](false)
+	if len(f.include) > 0 {
+		rule, matched := firstMatch(f.include, urlStr, parsedURL)
+		if !matched {
+			return append(trace, crawler.Deny(crawler.CheckIncludeRules, "no include rule matches"))
 		}
+		trace = append(trace, crawler.Allow(crawler.CheckIncludeRules, "%s matches include rule %q", rule.field, rule.pattern))
 	}
 
-	return result.Ok[bool](true)
+	return trace
 }
 
+// IsAllowedDomain checks if the host of a URL is within the allowed domains
 func (f *URLFilter) IsAllowedDomain(ctx context.Context, urlStr string ) result.Result[bool] {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
@@ -104,17 +223,110 @@ func (f *URLFilter) IsAllowedDomain(ctx context.Context, urlStr string ) result.
 		return result.Err[bool](err)
 	}
 
+	return result.Ok(f.inScope(parsedURL.Hostname()))
+}
+
+// OffDomainHops returns how many consecutive links outside the allowed domains lead to a URL
+// linked from a page reached after parentHops of them
+func (f *URLFilter) OffDomainHops(ctx context.Context, urlStr string, parentHops int) result.Result[int] {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	parsedURL, err := url.Parse(urlStr)
+	if err != nil {
+		return result.Err[int](err)
+	}
+
+	if f.inScope(parsedURL.Hostname()) {
+		return result.Ok(0)
+	}
+	return result.Ok(parentHops + 1)
+}
+
+// inScope checks if a hostname matches an allowed domain, all hostnames matching when none is set
+func (f *URLFilter) inScope(hostname string) bool {
 	if len(f.allowedDomains) == 0 {
-		return result.Ok[bool](true) // No restrictions on domains
+		return true // No restrictions on domains
+	}
+
+	host := normalizeHost(hostname)
+	if f.domainScope == ScopeRegistrableDomain {
+		host = registrableDomain(host)
 	}
 
 	for _, domain := range f.allowedDomains {
-		if strings.HasSuffix(parsedURL.Hostname(), domain) {
-			return result.Ok[bool](true)
+		switch f.domainScope {
+		case ScopeHost, ScopeRegistrableDomain:
+			if host == domain {
+				return true
+			}
+		default:
+			// Subdomains match on a label boundary, so example.com does not admit badexample.com
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return true
+			}
 		}
 	}
+	return false
+}
 
-	return result.Ok[bool](false)
+// compileRules compiles the include and exclude rules, failing on the first invalid one
+func compileRules(rules crawler.FilterRules) ([]compiledRule, []compiledRule, error) {
+	include, err := compileRuleSet("include", rules.Include)
+	if err != nil {
+		return nil, nil, err
+	}
+	exclude, err := compileRuleSet("exclude", rules.Exclude)
+	if err != nil {
+		return nil, nil, err
+	}
+	return include, exclude, nil
+}
+
+// compileRuleSet compiles a set of rules, named in errors
+func compileRuleSet(set string, rules []crawler.FilterRule) ([]compiledRule, error) {
+	compiled := make([]compiledRule, len(rules))
+	for i, rule := range rules {
+		field := rule.Field
+		if field == "" {
+			field = crawler.FieldURL
+		}
+		if !field.IsValid() {
+			return nil, fmt.Errorf("%w: %s rule %d has unknown field %q", crawler.ErrInvalidFilterRule, set, i, rule.Field)
+		}
+
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s rule %d: %v", crawler.ErrInvalidFilterRule, set, i, err)
+		}
+		compiled[i] = compiledRule{field: field, pattern: pattern}
+	}
+	return compiled, nil
+}
+
+// firstMatch returns the first of the rules matching a URL
+func firstMatch(rules []compiledRule, rawURL string, u *url.URL) (compiledRule, bool) {
+	for _, rule := range rules {
+		if rule.pattern.MatchString(rule.field.Value(rawURL, u)) {
+			return rule, true
+		}
+	}
+	return compiledRule{}, false
+}
+
+// normalizeHost lowercases a hostname and removes its trailing dot
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+// registrableDomain returns the registrable domain (eTLD+1) of a host, or the host itself
+// when it has none, as for IP addresses, public suffixes and single-label hosts
+func registrableDomain(host string) string {
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return domain
 }
 
 // IsAllowedContentType checks if a content type is allowed
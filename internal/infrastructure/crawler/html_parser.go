@@ -37,6 +37,21 @@ func (p *HTMLParser) Parse(ctx context.Context, page *crawler.Page) result.Resul
 		page.SetPlainText(textResult.Unwrap())
 	}
 
+	// Extract declared language
+	if language := extractLanguage(doc, page.Headers); language != "" {
+		page.SetLanguage(language)
+	}
+
+	// Extract declared canonical URL
+	if canonical := extractCanonical(doc, page.URL, page.Headers); canonical != "" {
+		page.SetCanonicalURL(canonical)
+	}
+
+	// Extract advertised feeds
+	if feeds := extractFeeds(doc, page.URL); len(feeds) > 0 {
+		page.SetFeeds(feeds)
+	}
+
 	// Extract links
 	linksResult := extractLinks(doc, page.URL)
 	if linksResult.IsOk() {
@@ -115,6 +130,131 @@ func extractLinks(doc *goquery.Document, baseURL string) result.Result[[]string]
 	return result.Ok(links)
 }
 
+// feedTypes are the media types of the feeds advertised with <link rel="alternate">
+var feedTypes = map[string]bool{
+	"application/rss+xml":  true,
+	"application/atom+xml": true,
+	"application/rdf+xml":  true,
+}
+
+// extractFeeds extracts the RSS and Atom feeds advertised by <link rel="alternate" type="..."> elements
+func extractFeeds(doc *goquery.Document, baseURL string) []string {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil
+	}
+
+	feeds := make([]string, 0)
+	seen := make(map[string]bool)
+	doc.Find("link[rel][type][href]").Each(func(_ int, s *goquery.Selection) {
+		rel, _ := s.Attr("rel")
+		mediaType, _ := s.Attr("type")
+		href, _ := s.Attr("href")
+
+		isAlternate := false
+		for _, value := range strings.Fields(strings.ToLower(rel)) {
+			isAlternate = isAlternate || value == "alternate"
+		}
+		if !isAlternate || !feedTypes[strings.ToLower(strings.TrimSpace(mediaType))] {
+			return
+		}
+
+		u, err := url.Parse(strings.TrimSpace(href))
+		if err != nil {
+			return
+		}
+		resolved := base.ResolveReference(u)
+		if (resolved.Scheme == "http" || resolved.Scheme == "https") && !seen[resolved.String()] {
+			seen[resolved.String()] = true
+			feeds = append(feeds, resolved.String())
+		}
+	})
+	return feeds
+}
+
+// extractLanguage extracts the declared language from the html lang attribute or the Content-Language header
+func extractLanguage(doc *goquery.Document, headers map[string]string) string {
+	language, _ := doc.Find("html").First().Attr("lang")
+	if language == "" {
+		language, _ = doc.Find(`meta[http-equiv="content-language" i]`).First().Attr("content")
+	}
+	if language == "" {
+		language = headers["Content-Language"]
+	}
+
+	// Keep the primary language when several are declared
+	if idx := strings.IndexAny(language, ",;"); idx >= 0 {
+		language = language[:idx]
+	}
+	return strings.ToLower(strings.TrimSpace(language))
+}
+
+// extractCanonical extracts the canonical URL declared by a <link rel="canonical"> element,
+// or by a Link header with rel="canonical", resolved against the page URL
+func extractCanonical(doc *goquery.Document, pageURL string, headers map[string]string) string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+
+	href := ""
+	doc.Find("link[rel][href]").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		rel, _ := s.Attr("rel")
+		for _, token := range strings.Fields(rel) {
+			if strings.EqualFold(token, "canonical") {
+				href, _ = s.Attr("href")
+				return false
+			}
+		}
+		return true
+	})
+	if strings.TrimSpace(href) == "" {
+		href = canonicalFromLinkHeader(headers["Link"])
+	}
+
+	href = strings.TrimSpace(href)
+	if href == "" {
+		return ""
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	resolved := base.ResolveReference(u)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return ""
+	}
+	return resolved.String()
+}
+
+// canonicalFromLinkHeader returns the target of the rel="canonical" link of a Link header,
+// such as <https://example.com/page>; rel="canonical"
+func canonicalFromLinkHeader(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		target, params, ok := strings.Cut(link, ";")
+		if !ok {
+			continue
+		}
+		target = strings.TrimSpace(target)
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+
+		for _, param := range strings.Split(params, ";") {
+			name, value, _ := strings.Cut(param, "=")
+			if !strings.EqualFold(strings.TrimSpace(name), "rel") {
+				continue
+			}
+			for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+				if strings.EqualFold(rel, "canonical") {
+					return target[1 : len(target)-1]
+				}
+			}
+		}
+	}
+	return ""
+}
+
 // ExtractTitle extracts the title from HTML content
 func extractTitle(doc *goquery.Document) result.Result[string] {
 	title := doc.Find("title").First().Text()
@@ -1,21 +1,42 @@
 package crawler
 
 import (
+	"bufio"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"slices"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gerthdala/webcrawler/internal/domain/crawler"
 	result "github.com/gerthdala/webcrawler/pkg/utils/result"
 )
 
+var (
+	// errTooManyRedirects is returned by the redirect policy when a fetch exceeds the maximum redirects
+	errTooManyRedirects = errors.New("too many redirects")
+	// errRedirectLoop is returned by the redirect policy when a redirect leads back to a URL of the chain
+	errRedirectLoop = errors.New("redirect loop")
+)
+
+// sniffLen is the number of bytes http.DetectContentType considers
+const sniffLen = 512
+
 // HTTPFetcher implements the FetcherService interface
 type HTTPFetcher struct {
-	client    *http.Client
-	userAgent string
-	timeout   time.Duration
+	client      *http.Client
+	filter      crawler.URLFilterService
+	userAgent   string
+	timeout     time.Duration
+	maxBodySize int64
+	headProbe   bool
 }
 
 type HTTPFetcherConfig struct {
@@ -23,21 +44,27 @@ type HTTPFetcherConfig struct {
 	Timout          time.Duration
 	MaxRedirects    int
 	FollowRedirects bool
+	// MaxBodySize caps the bytes read from a response body, the rest being truncated, 10 MiB by default
+	MaxBodySize int64
+	// HeadProbe checks the content type with a HEAD request before fetching a URL
+	HeadProbe bool
 }
 
-func NewHTTPFetcher(config HTTPFetcherConfig) *HTTPFetcher {
-	// Create rederct policy
+// NewHTTPFetcher creates a new HTTPFetcher, fetching only the content types the filter allows
+func NewHTTPFetcher(filter crawler.URLFilterService, config HTTPFetcherConfig) *HTTPFetcher {
+	// Create redirect policy, the client forwarding the request headers to redirects itself
 	redirectPolicy := func(req *http.Request, via []*http.Request) error {
-		if config.FollowRedirects {
+		if !config.FollowRedirects {
 			return http.ErrUseLastResponse
 		}
 
 		if len(via) >= config.MaxRedirects {
-			return fmt.Errorf("exceeded max redirects: %d", config.MaxRedirects)
+			return fmt.Errorf("%w: exceeded max redirects: %d", errTooManyRedirects, config.MaxRedirects)
 		}
-		// copy the original headers to the redirect
-		for key, val := range via[0].Header {
-			req.Header[key] = val
+		for _, previous := range via {
+			if previous.URL.String() == req.URL.String() {
+				return fmt.Errorf("%w: %s", errRedirectLoop, req.URL)
+			}
 		}
 
 		return nil
@@ -48,42 +75,60 @@ func NewHTTPFetcher(config HTTPFetcherConfig) *HTTPFetcher {
 		CheckRedirect: redirectPolicy,
 	}
 
+	maxBodySize := config.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = 10 << 20
+	}
+
 	return &HTTPFetcher{
-		client:    client,
-		userAgent: config.UserAgent,
-		timeout:   config.Timout,
+		client:      client,
+		filter:      filter,
+		userAgent:   config.UserAgent,
+		timeout:     config.Timout,
+		maxBodySize: maxBodySize,
+		headProbe:   config.HeadProbe,
 	}
 }
 
 // Fetch performs an HTTP GET request to the specified URL
 func (f *HTTPFetcher) Fetch(ctx context.Context, url *crawler.URL) result.Result[*crawler.Page] {
+	return f.fetch(ctx, url, crawler.Validators{})
+}
+
+// FetchIfModified performs an HTTP GET request with If-None-Match and If-Modified-Since
+// set from the validators. A 304 Not Modified response is returned as a page without body.
+func (f *HTTPFetcher) FetchIfModified(ctx context.Context, url *crawler.URL, validators crawler.Validators) result.Result[*crawler.Page] {
+	return f.fetch(ctx, url, validators)
+}
+
+func (f *HTTPFetcher) fetch(ctx context.Context, url *crawler.URL, validators crawler.Validators) result.Result[*crawler.Page] {
+	// Probe the content type without downloading the body
+	if f.headProbe {
+		if contentType, ok := f.probeContentType(ctx, url.URL); ok && !f.allowsContentType(ctx, contentType) {
+			return result.Err[*crawler.Page](contentTypeError(url.URL, contentType))
+		}
+	}
+
 	// Create a request
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url.URL, nil)
+	req, err := f.newRequest(ctx, http.MethodGet, url.URL)
 	if err != nil {
-		return result.Err[*crawler.Page](fmt.Errorf("error creating request: %w", err))
+		return result.Err[*crawler.Page](crawler.NewFetchError(crawler.FetchErrorInvalidRequest, url.URL, err))
+	}
+	if validators.ETag != "" {
+		req.Header.Set("If-None-Match", validators.ETag)
+	}
+	if validators.LastModified != "" {
+		req.Header.Set("If-Modified-Since", validators.LastModified)
 	}
-
-	// Set headers
-	req.Header.Set("User-Agent", f.userAgent)
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
-	req.Header.Set("Connection", "keep-alive")
-	req.Header.Set("Upgrade-Insecure-Requests", "1")
-	req.Header.Set("Cache-Control", "max-age=0")
 
 	// Execute request
 	resp, err := f.client.Do(req)
 	if err != nil {
-		return result.Err[*crawler.Page](fmt.Errorf("error fetching URL %s: %v", url.URL, err))
+		return result.Err[*crawler.Page](classifyFetchError(url.URL, err))
 	}
 
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return result.Err[*crawler.Page](fmt.Errorf("error reading response body for URL %s: %v", url.URL, err))
-	}
-
 	//Extracts headers
 	headers := make(map[string]string)
 	for name, values := range resp.Header {
@@ -92,9 +137,173 @@ func (f *HTTPFetcher) Fetch(ctx context.Context, url *crawler.URL) result.Result
 		}
 	}
 
+	// The page is stored for the URL the redirects ended at
+	finalURL := resp.Request.URL.String()
+	redirects := redirectChain(resp)
+
+	// An unchanged page has no body to read
+	if resp.StatusCode == http.StatusNotModified {
+		page := crawler.NewPage(finalURL, resp.StatusCode, "", headers)
+		page.SetRedirects(url.URL, redirects)
+		return result.Ok(page)
+	}
+
+	// The content type is checked on the first bytes of the body, sniffed when the header is missing or wrong
+	reader := bufio.NewReaderSize(io.LimitReader(resp.Body, f.maxBodySize+1), sniffLen)
+	head, err := reader.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		fetchErr := classifyFetchError(url.URL, err)
+		fetchErr.StatusCode = resp.StatusCode
+		return result.Err[*crawler.Page](fetchErr)
+	}
+	contentType := effectiveContentType(resp.Header.Get("Content-Type"), head)
+	if !f.allowsContentType(ctx, contentType) {
+		fetchErr := contentTypeError(url.URL, contentType)
+		fetchErr.StatusCode = resp.StatusCode
+		return result.Err[*crawler.Page](fetchErr)
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		fetchErr := classifyFetchError(url.URL, err)
+		fetchErr.StatusCode = resp.StatusCode
+		return result.Err[*crawler.Page](fetchErr)
+	}
+	truncated := int64(len(body)) > f.maxBodySize
+	if truncated {
+		body = body[:f.maxBodySize]
+	}
+
+	// Text is stored as UTF-8, whatever charset it was served in
+	content, charsetName := string(body), ""
+	if isTextContentType(contentType) {
+		content, charsetName = decodeBody(body, contentType)
+	}
+
 	// Create a Page
-	page := crawler.NewPage(url.URL, resp.StatusCode, string(body), headers)
-	page.ContentType = resp.Header.Get("Content-Type")
+	page := crawler.NewPage(finalURL, resp.StatusCode, content, headers)
+	page.ContentType = contentType
+	page.Charset = charsetName
+	page.Truncated = truncated
+	page.SetRedirects(url.URL, redirects)
 	
 	return result.Ok(page)
-}
\ No newline at end of file
+}
+
+// newRequest creates a request with the crawler headers
+func (f *HTTPFetcher) newRequest(ctx context.Context, method, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set headers
+	req.Header.Set("User-Agent", f.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
+	req.Header.Set("Connection", "keep-alive")
+	req.Header.Set("Upgrade-Insecure-Requests", "1")
+	req.Header.Set("Cache-Control", "max-age=0")
+	return req, nil
+}
+
+// probeContentType returns the content type a HEAD request declares for a URL, if it succeeds and declares one
+func (f *HTTPFetcher) probeContentType(ctx context.Context, url string) (string, bool) {
+	req, err := f.newRequest(ctx, http.MethodHead, url)
+	if err != nil {
+		return "", false
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", false
+	}
+	resp.Body.Close()
+
+	contentType := resp.Header.Get("Content-Type")
+	if resp.StatusCode >= 300 || contentType == "" {
+		return "", false
+	}
+	return contentType, true
+}
+
+// allowsContentType checks the content type with the filter, all content types being allowed without one
+func (f *HTTPFetcher) allowsContentType(ctx context.Context, contentType string) bool {
+	if f.filter == nil || contentType == "" {
+		return true
+	}
+	allowed := f.filter.IsAllowedContentType(ctx, contentType)
+	return allowed.IsErr() || allowed.Unwrap()
+}
+
+// effectiveContentType returns the declared content type of a body, or the type sniffed from its
+// first bytes when none is declared, a generic one is, or the body is binary while text is declared
+func effectiveContentType(declared string, head []byte) string {
+	if len(head) == 0 {
+		return declared
+	}
+	sniffed := http.DetectContentType(head)
+
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(declared, ";")[0]))
+	switch {
+	case mediaType == "", mediaType == "application/octet-stream", mediaType == "binary/octet-stream":
+		return sniffed
+	case strings.HasPrefix(mediaType, "text/") && !strings.HasPrefix(sniffed, "text/"):
+		return sniffed
+	}
+	return declared
+}
+
+// contentTypeError returns the FetchError of a response with a content type the filter does not allow
+func contentTypeError(url, contentType string) *crawler.FetchError {
+	return crawler.NewFetchError(crawler.FetchErrorContentType, url, fmt.Errorf("content type %q is not allowed", contentType))
+}
+
+// redirectChain returns the redirects followed to get a response, in order
+func redirectChain(resp *http.Response) []crawler.Redirect {
+	var redirects []crawler.Redirect
+	for req := resp.Request; req.Response != nil; req = req.Response.Request {
+		redirects = append(redirects, crawler.Redirect{
+			URL:        req.Response.Request.URL.String(),
+			StatusCode: req.Response.StatusCode,
+			Location:   req.Response.Header.Get("Location"),
+		})
+	}
+	slices.Reverse(redirects)
+	return redirects
+}
+
+// classifyFetchError classifies an error of the HTTP client by the failure causing it
+func classifyFetchError(url string, err error) *crawler.FetchError {
+	var (
+		dnsErr      *net.DNSError
+		netErr      net.Error
+		certErr     *tls.CertificateVerificationError
+		headerErr   tls.RecordHeaderError
+		unknownCA   x509.UnknownAuthorityError
+		hostnameErr x509.HostnameError
+		invalidErr  x509.CertificateInvalidError
+	)
+
+	switch {
+	case errors.Is(err, errTooManyRedirects):
+		return crawler.NewFetchError(crawler.FetchErrorTooManyRedirects, url, err)
+	case errors.Is(err, errRedirectLoop):
+		return crawler.NewFetchError(crawler.FetchErrorRedirectLoop, url, err)
+	case errors.As(err, &dnsErr):
+		fetchErr := crawler.NewFetchError(crawler.FetchErrorDNS, url, err)
+		// A host that does not exist will not resolve on retry
+		fetchErr.Retryable = !dnsErr.IsNotFound
+		return fetchErr
+	case errors.As(err, &certErr), errors.As(err, &headerErr), errors.As(err, &unknownCA),
+		errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return crawler.NewFetchError(crawler.FetchErrorTLS, url, err)
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return crawler.NewFetchError(crawler.FetchErrorTimeout, url, err)
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return crawler.NewFetchError(crawler.FetchErrorConnectionRefused, url, err)
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return crawler.NewFetchError(crawler.FetchErrorConnection, url, err)
+	}
+	return crawler.NewFetchError(crawler.FetchErrorUnknown, url, err)
+}
@@ -0,0 +1,88 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+)
+
+// RulesFileWatcher reloads the URL filter rules from a JSON file whenever it changes
+type RulesFileWatcher struct {
+	filter   crawler.URLFilterService
+	path     string
+	interval time.Duration
+	modTime  time.Time
+}
+
+// RulesFileWatcherConfig configuration for the rules file watcher
+type RulesFileWatcherConfig struct {
+	// Path is the JSON file holding the crawler.FilterRules
+	Path string
+	// Interval is how often the file is checked for changes
+	Interval time.Duration
+}
+
+// NewRulesFileWatcher creates a new RulesFileWatcher
+func NewRulesFileWatcher(filter crawler.URLFilterService, config RulesFileWatcherConfig) *RulesFileWatcher {
+	interval := config.Interval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	return &RulesFileWatcher{
+		filter:   filter,
+		path:     config.Path,
+		interval: interval,
+	}
+}
+
+// Run reloads the rules whenever the file changes, until ctx is done
+func (w *RulesFileWatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if reloaded := w.Reload(); reloaded.IsErr() {
+			log.Printf("Failed to reload filter rules from %s: %v", w.path, reloaded.Error())
+		} else if reloaded.Unwrap() {
+			log.Printf("Reloaded filter rules from %s", w.path)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reload updates the filter rules when the file changed since the last reload, reporting whether it did.
+// An invalid file is reported once and leaves the rules in use until it changes again.
+func (w *RulesFileWatcher) Reload() result.Result[bool] {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return result.Err[bool](fmt.Errorf("failed to stat rules file: %w", err))
+	}
+	if info.ModTime().Equal(w.modTime) {
+		return result.Ok(false)
+	}
+	w.modTime = info.ModTime()
+
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return result.Err[bool](fmt.Errorf("failed to read rules file: %w", err))
+	}
+
+	var rules crawler.FilterRules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return result.Err[bool](fmt.Errorf("failed to parse rules file: %w", err))
+	}
+
+	return w.filter.UpdateRules(rules)
+}
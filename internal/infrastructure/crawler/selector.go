@@ -0,0 +1,41 @@
+package crawler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+)
+
+// CSSSelector implements the watch.SelectorService interface
+type CSSSelector struct{}
+
+// NewCSSSelector creates a new CSSSelector
+func NewCSSSelector() *CSSSelector {
+	return &CSSSelector{}
+}
+
+// SelectText returns the text of the elements of html matching a CSS selector, one per line
+func (s *CSSSelector) SelectText(html, selector string) result.Result[string] {
+	// goquery panics on invalid selectors, compile them first to report the error
+	matcher, err := cascadia.Compile(selector)
+	if err != nil {
+		return result.Err[string](fmt.Errorf("invalid selector %q: %w", selector, err))
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return result.Err[string](fmt.Errorf("error creating document: %w", err))
+	}
+
+	texts := make([]string, 0)
+	doc.FindMatcher(matcher).Each(func(_ int, sel *goquery.Selection) {
+		if text := strings.Join(strings.Fields(sel.Text()), " "); text != "" {
+			texts = append(texts, text)
+		}
+	})
+
+	return result.Ok(strings.Join(texts, "\n"))
+}
@@ -0,0 +1,311 @@
+package crawler
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+)
+
+// lastModLayouts are the W3C datetime formats of sitemap lastmod values
+var lastModLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// SitemapReader implements the SitemapService interface
+type SitemapReader struct {
+	client    *http.Client
+	userAgent string
+	maxDepth  int
+	maxBytes  int64
+}
+
+// SitemapReaderConfig configuration for the sitemap reader
+type SitemapReaderConfig struct {
+	UserAgent string
+	Timeout   time.Duration
+	// MaxDepth bounds the nesting of sitemap indexes, 3 by default
+	MaxDepth int
+	// MaxBytes bounds the uncompressed size of a sitemap, 50 MB by default as in the sitemap protocol
+	MaxBytes int64
+}
+
+// NewSitemapReader creates a new SitemapReader
+func NewSitemapReader(config SitemapReaderConfig) *SitemapReader {
+	maxDepth := config.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = 3
+	}
+	maxBytes := config.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = 50 << 20
+	}
+
+	return &SitemapReader{
+		client:    &http.Client{Timeout: config.Timeout},
+		userAgent: config.UserAgent,
+		maxDepth:  maxDepth,
+		maxBytes:  maxBytes,
+	}
+}
+
+// DiscoverSitemaps returns the sitemaps declared by Sitemap: lines of the site's robots.txt,
+// or /sitemap.xml when robots.txt declares none and it exists
+func (r *SitemapReader) DiscoverSitemaps(ctx context.Context, siteURL string) result.Result[[]string] {
+	parsed, err := url.Parse(siteURL)
+	if err != nil || parsed.Host == "" {
+		return result.Err[[]string](fmt.Errorf("invalid site URL %q", siteURL))
+	}
+	if parsed.Scheme == "" {
+		parsed.Scheme = "https"
+	}
+	root := parsed.Scheme + "://" + parsed.Host
+
+	sitemaps := make([]string, 0)
+	seen := make(map[string]bool)
+	if body, err := r.get(ctx, root+"/robots.txt"); err == nil {
+		scanner := bufio.NewScanner(body)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			name, value, ok := strings.Cut(line, ":")
+			if !ok || !strings.EqualFold(strings.TrimSpace(name), "sitemap") {
+				continue
+			}
+			// Sitemap values are absolute URLs, resolve them anyway for lenient sites
+			loc, err := parsed.Parse(strings.TrimSpace(value))
+			if err == nil && !seen[loc.String()] {
+				seen[loc.String()] = true
+				sitemaps = append(sitemaps, loc.String())
+			}
+		}
+		body.Close()
+	}
+
+	if len(sitemaps) == 0 {
+		fallback := root + "/sitemap.xml"
+		body, err := r.get(ctx, fallback)
+		if err != nil {
+			return result.Ok(sitemaps)
+		}
+		body.Close()
+		sitemaps = append(sitemaps, fallback)
+	}
+
+	return result.Ok(sitemaps)
+}
+
+// ReadSitemap streams the entries of a sitemap to fn, following sitemap indexes,
+// and returns the number of entries read. Gzip-compressed sitemaps are decompressed.
+// An error returned by fn stops the read, sitemaps of an index that fail to read are skipped.
+func (r *SitemapReader) ReadSitemap(ctx context.Context, sitemapURL string, fn func(entry crawler.SitemapEntry) error) result.Result[int] {
+	count, failed := 0, 0
+	visited := make(map[string]bool)
+	err := r.read(ctx, sitemapURL, 0, visited, fn, &count, &failed)
+	if failed > 0 {
+		log.Printf("Skipped %d sitemaps indexed by %s that failed to read", failed, sitemapURL)
+	}
+	if err != nil {
+		var stopped *stopError
+		if errors.As(err, &stopped) {
+			err = stopped.err
+		}
+		return result.Err[int](err)
+	}
+	return result.Ok(count)
+}
+
+// urlElement is a <url> element of a urlset
+type urlElement struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod"`
+	ChangeFreq string `xml:"changefreq"`
+	Priority   string `xml:"priority"`
+}
+
+// sitemapElement is a <sitemap> element of a sitemap index
+type sitemapElement struct {
+	Loc string `xml:"loc"`
+}
+
+// stopError wraps an error returned by the entry callback, which stops the whole read
+type stopError struct {
+	err error
+}
+
+func (e *stopError) Error() string {
+	return e.err.Error()
+}
+
+func (e *stopError) Unwrap() error {
+	return e.err
+}
+
+// read streams the entries of a sitemap, then of the sitemaps it indexes.
+// Indexed sitemaps that fail to read are logged and counted in failed, only a cancelled
+// context or an error returned by fn stops the read.
+func (r *SitemapReader) read(ctx context.Context, sitemapURL string, depth int, visited map[string]bool, fn func(entry crawler.SitemapEntry) error, count, failed *int) error {
+	if visited[sitemapURL] {
+		return nil
+	}
+	visited[sitemapURL] = true
+
+	// The sitemap is closed before its children are read, so that nested indexes never hold several bodies open
+	children, err := r.readEntries(ctx, sitemapURL, fn, count)
+	if err != nil {
+		return err
+	}
+
+	if len(children) > 0 && depth >= r.maxDepth {
+		return fmt.Errorf("sitemap index %s nested deeper than %d levels", sitemapURL, r.maxDepth)
+	}
+	for _, child := range children {
+		err := r.read(ctx, child, depth+1, visited, fn, count, failed)
+		if err == nil {
+			continue
+		}
+		var stopped *stopError
+		if errors.As(err, &stopped) {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		*failed++
+		log.Printf("Failed to read sitemap %s indexed by %s: %v", child, sitemapURL, err)
+	}
+
+	return nil
+}
+
+// readEntries streams the entries of a single sitemap to fn and returns the sitemaps it indexes
+func (r *SitemapReader) readEntries(ctx context.Context, sitemapURL string, fn func(entry crawler.SitemapEntry) error, count *int) ([]string, error) {
+	body, err := r.get(ctx, sitemapURL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	reader, err := decompress(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress sitemap %s: %w", sitemapURL, err)
+	}
+
+	// Entries are decoded one at a time so that large sitemaps are never fully loaded
+	decoder := xml.NewDecoder(io.LimitReader(reader, r.maxBytes))
+	children := make([]string, 0)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse sitemap %s: %w", sitemapURL, err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "url":
+			var u urlElement
+			if err := decoder.DecodeElement(&u, &start); err != nil {
+				return nil, fmt.Errorf("failed to parse sitemap %s: %w", sitemapURL, err)
+			}
+			entry, ok := sitemapEntry(u, sitemapURL)
+			if !ok {
+				continue
+			}
+			*count++
+			if err := fn(entry); err != nil {
+				return nil, &stopError{err: err}
+			}
+		case "sitemap":
+			var ref sitemapElement
+			if err := decoder.DecodeElement(&ref, &start); err != nil {
+				return nil, fmt.Errorf("failed to parse sitemap index %s: %w", sitemapURL, err)
+			}
+			if loc := strings.TrimSpace(ref.Loc); loc != "" {
+				children = append(children, loc)
+			}
+		}
+	}
+
+	return children, nil
+}
+
+// sitemapEntry converts a <url> element, reporting false when it has no location
+func sitemapEntry(u urlElement, sitemap string) (crawler.SitemapEntry, bool) {
+	entry := crawler.SitemapEntry{
+		Loc:             strings.TrimSpace(u.Loc),
+		ChangeFrequency: crawler.ChangeFrequency(strings.ToLower(strings.TrimSpace(u.ChangeFreq))),
+		Priority:        crawler.DefaultSitemapPriority,
+		Sitemap:         sitemap,
+	}
+	if entry.Loc == "" {
+		return entry, false
+	}
+
+	if lastMod := strings.TrimSpace(u.LastMod); lastMod != "" {
+		for _, layout := range lastModLayouts {
+			if parsed, err := time.Parse(layout, lastMod); err == nil {
+				entry.LastModified = parsed
+				break
+			}
+		}
+	}
+
+	if priority, err := strconv.ParseFloat(strings.TrimSpace(u.Priority), 64); err == nil && priority >= 0 && priority <= 1 {
+		entry.Priority = priority
+	}
+
+	return entry, true
+}
+
+// decompress returns a reader of the body, decompressed when it starts with the gzip magic number
+func decompress(body io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(body)
+	magic, err := buffered.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(buffered)
+	}
+	return buffered, nil
+}
+
+// get performs a GET request and returns the body of a successful response
+func (r *SitemapReader) get(ctx context.Context, rawURL string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", r.userAgent)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching %s: %w", rawURL, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %d fetching %s", resp.StatusCode, rawURL)
+	}
+
+	return resp.Body, nil
+}
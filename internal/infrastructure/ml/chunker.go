@@ -0,0 +1,297 @@
+package ml
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gerthdala/webcrawler/internal/domain/content"
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+)
+
+var (
+	paragraphBreak = regexp.MustCompile(`\n[ \t\r]*\n\s*`)
+	sentenceEnd    = regexp.MustCompile(`[.!?…]+["'”’)\]]*\s+`)
+	wordPattern    = regexp.MustCompile(`\S+`)
+)
+
+// TextChunker implements analysis.TextChunker.
+// It splits text along heading, paragraph and sentence boundaries into chunks
+// that fit a token budget, carrying trailing sentences over as overlap.
+// Tokens are approximated by whitespace-separated words.
+type TextChunker struct {
+	maxTokens     int
+	overlapTokens int
+}
+
+// TextChunkerConfig for building a TextChunker.
+type TextChunkerConfig struct {
+	// Maximum number of tokens per chunk. Defaults to 256.
+	MaxTokens int
+	// Number of tokens from the end of a chunk repeated at the start of the next one.
+	OverlapTokens int
+}
+
+// NewTextChunker constructs a TextChunker.
+func NewTextChunker(cfg TextChunkerConfig) *TextChunker {
+	maxTokens := cfg.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = 256
+	}
+	overlapTokens := cfg.OverlapTokens
+	if overlapTokens < 0 || overlapTokens >= maxTokens {
+		overlapTokens = 0
+	}
+
+	return &TextChunker{
+		maxTokens:     maxTokens,
+		overlapTokens: overlapTokens,
+	}
+}
+
+// span is a byte range of the chunked text
+type span struct {
+	start, end int
+	tokens     int
+}
+
+// Chunk splits text into chunks with their character offsets in text
+func (tc *TextChunker) Chunk(ctx context.Context, text string) result.Result[[]content.Chunk] {
+	b := &chunkBuilder{
+		text:          text,
+		startOffsets:  runeCursor{text: text},
+		endOffsets:    runeCursor{text: text},
+		maxTokens:     tc.maxTokens,
+		overlapTokens: tc.overlapTokens,
+		chunks:        make([]content.Chunk, 0),
+	}
+
+	for _, paragraph := range splitParagraphs(text) {
+		if err := ctx.Err(); err != nil {
+			return result.Err[[]content.Chunk](err)
+		}
+
+		raw := text[paragraph.start:paragraph.end]
+		sentences := tc.splitSentences(text, paragraph)
+
+		if isHeading(raw) {
+			// A heading starts a new section and opens the text of its first chunk,
+			// overlap is not carried across sections
+			b.flush()
+			b.reset()
+			b.heading = strings.TrimSpace(strings.TrimLeft(raw, "#"))
+			for _, sentence := range sentences {
+				b.add(sentence)
+			}
+			b.headingSpans = len(b.current)
+			continue
+		}
+
+		// Keep paragraphs whole when they fit in a chunk of their own, after the heading opening their section
+		if paragraph.tokens <= b.maxTokens && b.tokens+paragraph.tokens > b.maxTokens && len(b.current) > b.headingSpans {
+			b.flush()
+		}
+		for _, sentence := range sentences {
+			b.add(sentence)
+		}
+	}
+	b.flush()
+
+	return result.Ok(b.chunks)
+}
+
+// splitSentences splits a paragraph into sentences, breaking sentences longer than the token budget
+func (tc *TextChunker) splitSentences(text string, paragraph span) []span {
+	sentences := make([]span, 0)
+	start := paragraph.start
+	raw := text[paragraph.start:paragraph.end]
+
+	appendSentence := func(s, e int) {
+		sentences = append(sentences, tc.splitLong(text, s, e)...)
+	}
+
+	for _, loc := range sentenceEnd.FindAllStringIndex(raw, -1) {
+		end := paragraph.start + loc[1]
+		appendSentence(start, trimRight(text, start, end))
+		start = end
+	}
+	if start < paragraph.end {
+		appendSentence(start, paragraph.end)
+	}
+
+	return sentences
+}
+
+// splitLong splits a sentence exceeding the token budget into windows of words
+func (tc *TextChunker) splitLong(text string, start, end int) []span {
+	words := wordPattern.FindAllStringIndex(text[start:end], -1)
+	if len(words) <= tc.maxTokens {
+		return []span{{start: start, end: end, tokens: len(words)}}
+	}
+
+	parts := make([]span, 0, len(words)/tc.maxTokens+1)
+	for i := 0; i < len(words); i += tc.maxTokens {
+		j := i + tc.maxTokens
+		if j > len(words) {
+			j = len(words)
+		}
+		parts = append(parts, span{
+			start:  start + words[i][0],
+			end:    start + words[j-1][1],
+			tokens: j - i,
+		})
+	}
+	return parts
+}
+
+// chunkBuilder packs sentences into chunks
+type chunkBuilder struct {
+	text          string
+	maxTokens     int
+	overlapTokens int
+	heading       string
+	current       []span
+	tokens        int
+	carried       int // number of spans at the start of current carried over from the previous chunk
+	headingSpans  int // number of spans of current holding the heading of the section it opens
+	chunks        []content.Chunk
+	// Chunks start and end in increasing order, their character offsets are counted on from the previous ones
+	startOffsets runeCursor
+	endOffsets   runeCursor
+}
+
+func (b *chunkBuilder) add(s span) {
+	if len(b.current) > b.carried && b.tokens+s.tokens > b.maxTokens {
+		b.flush()
+	}
+	// Drop overlap that would not leave room for the new sentence
+	for b.carried > 0 && b.tokens+s.tokens > b.maxTokens {
+		b.tokens -= b.current[0].tokens
+		b.current = b.current[1:]
+		b.carried--
+	}
+
+	b.current = append(b.current, s)
+	b.tokens += s.tokens
+}
+
+// flush emits the current chunk and carries its trailing sentences over as overlap
+func (b *chunkBuilder) flush() {
+	if len(b.current) == b.carried {
+		return
+	}
+
+	start := b.current[0].start
+	end := b.current[len(b.current)-1].end
+	b.chunks = append(b.chunks, content.NewChunk(
+		len(b.chunks),
+		b.heading,
+		b.text[start:end],
+		b.startOffsets.offset(start),
+		b.endOffsets.offset(end),
+		b.tokens,
+	))
+
+	overlap := 0
+	i := len(b.current)
+	for i > 0 && overlap+b.current[i-1].tokens <= b.overlapTokens {
+		i--
+		overlap += b.current[i].tokens
+	}
+
+	b.current = append([]span(nil), b.current[i:]...)
+	b.tokens = overlap
+	b.carried = len(b.current)
+	b.headingSpans = 0
+}
+
+func (b *chunkBuilder) reset() {
+	b.current = nil
+	b.tokens = 0
+	b.carried = 0
+	b.headingSpans = 0
+}
+
+// runeCursor converts byte offsets of a text to character offsets, counting the characters
+// from the previous offset converted so that increasing offsets are counted in linear time
+type runeCursor struct {
+	text  string
+	bytes int
+	runes int
+}
+
+// offset returns the character offset of a byte offset of the text
+func (c *runeCursor) offset(byteOffset int) int {
+	if byteOffset < c.bytes {
+		c.bytes, c.runes = 0, 0
+	}
+	c.runes += utf8.RuneCountInString(c.text[c.bytes:byteOffset])
+	c.bytes = byteOffset
+	return c.runes
+}
+
+// splitParagraphs splits text at blank lines, trimming surrounding whitespace
+func splitParagraphs(text string) []span {
+	paragraphs := make([]span, 0)
+	start := 0
+
+	appendParagraph := func(s, e int) {
+		s, e = trimLeft(text, s, e), trimRight(text, s, e)
+		if s < e {
+			paragraphs = append(paragraphs, span{
+				start:  s,
+				end:    e,
+				tokens: len(strings.Fields(text[s:e])),
+			})
+		}
+	}
+
+	for _, loc := range paragraphBreak.FindAllStringIndex(text, -1) {
+		appendParagraph(start, loc[0])
+		start = loc[1]
+	}
+	appendParagraph(start, len(text))
+
+	return paragraphs
+}
+
+// isHeading reports whether a paragraph looks like a heading: a markdown heading
+// or a short single line starting with a capital letter and without closing punctuation.
+// Its text is chunked as any paragraph, so a short paragraph taken for a heading is not lost.
+func isHeading(paragraph string) bool {
+	paragraph = strings.TrimSpace(paragraph)
+	if strings.HasPrefix(paragraph, "#") {
+		return true
+	}
+	if paragraph == "" || strings.Contains(paragraph, "\n") || len(strings.Fields(paragraph)) > 12 {
+		return false
+	}
+
+	first, _ := utf8.DecodeRuneInString(paragraph)
+	last, _ := utf8.DecodeLastRuneInString(paragraph)
+	return (unicode.IsUpper(first) || unicode.IsDigit(first)) && !strings.ContainsRune(".!?,;:…", last)
+}
+
+func trimLeft(text string, start, end int) int {
+	for start < end {
+		r, size := utf8.DecodeRuneInString(text[start:end])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		start += size
+	}
+	return start
+}
+
+func trimRight(text string, start, end int) int {
+	for end > start {
+		r, size := utf8.DecodeLastRuneInString(text[start:end])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		end -= size
+	}
+	return end
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"sort"
 	"strings"
-	"unicode/utf8"
 
 	"github.com/gerthdala/webcrawler/internal/domain/content"
 	result "github.com/gerthdala/webcrawler/pkg/utils/result"
@@ -39,7 +39,7 @@ func (sc *SimilarityCalculator) CalculateSimilarity(ctx context.Context, a, b []
 func (sc *SimilarityCalculator) FindMostSimilar(ctx context.Context, embedding []float32, embeddings [][]float32, limit int) result.Result[[]int] {
 	n := len(embeddings)
 	if n == 0 || limit <= 0 {
-		return result.Ok([]int)
+		return result.Ok([]int{})
 	}
 
 	// Build a slice if (index, similarity)
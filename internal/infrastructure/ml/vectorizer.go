@@ -1,46 +1,73 @@
 package ml
 
 import (
-	"sync"
+	"context"
+	"fmt"
+	"math"
+	"strings"
 
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
 	"github.com/james-bowman/nlp"
 )
 
-//TextVectorizer implements content.TextVectorizer interface
-// It uses TF-IDF model under the hood and normalizes output to unut length
+// DefaultDimensions matches the size of the stored embedding columns
+const DefaultDimensions = 384
 
+// TextVectorizer implements analysis.TextVectorizer.
+// It hashes the words of a text into a fixed number of features, so it needs no
+// vocabulary fitted on a corpus, and normalizes the output to unit length.
 type TextVectorizer struct {
-	vectorizer  *nlp.TfidfVectorizer
-	initOnce    sync.Once
-	mu          sync.RWMutex
-	dimensions  int
-	minDocFreq  int
-	maxFeatures int
+	vectorizer *nlp.HashingVectoriser
+	dimensions int
 }
 
-// Config for building a TextVectorizer.
+// TextVectorizerConfig configures a TextVectorizer.
 type TextVectorizerConfig struct {
-	// If zero, automatically use vocabulary size.
+	// Dimensions of the embeddings, DefaultDimensions if zero
 	Dimensions int
-	// Minimum number of documents a term must appear in.
-	MinDocFreq int
-	// Maximum number of terms (by highest TF-IDF) to keep.
-	MaxFeatures int
+	// StopWords are left out of the embeddings
+	StopWords []string
 }
 
-// NewTextVectorizer constructs a configurable TF-IDF vectorizer.
+// NewTextVectorizer constructs a TextVectorizer.
 func NewTextVectorizer(cfg TextVectorizerConfig) *TextVectorizer {
-	// Pass options into the vectorizer for filtering and feature‐selection.
-	opts := []nlp.Option{
-		nlp.WithMinDocCount(cfg.MinDocFreq),
-	}
-	if cfg.MaxFeatures > 0 {
-		opts = append(opts, nlp.WithMaxFeatures(cfg.MaxFeatures))
+	if cfg.Dimensions <= 0 {
+		cfg.Dimensions = DefaultDimensions
 	}
+
 	return &TextVectorizer{
-		vectorizer:  nlp.NewTfidfVectorizer(opts...),
-		dimensions:  cfg.Dimensions,
-		minDocFreq:  cfg.MinDocFreq,
-		maxFeatures: cfg.MaxFeatures,
+		vectorizer: nlp.NewHashingVectoriser(cfg.Dimensions, cfg.StopWords...),
+		dimensions: cfg.Dimensions,
+	}
+}
+
+// Vectorize returns the embedding of text, with sublinear term frequencies
+func (tv *TextVectorizer) Vectorize(ctx context.Context, text string) result.Result[[]float32] {
+	if err := ctx.Err(); err != nil {
+		return result.Err[[]float32](err)
+	}
+
+	matrix, err := tv.vectorizer.Transform(strings.ToLower(text))
+	if err != nil {
+		return result.Err[[]float32](fmt.Errorf("failed to vectorize text: %w", err))
+	}
+
+	embedding := make([]float32, tv.dimensions)
+	var norm float64
+	for i := range embedding {
+		if count := matrix.At(i, 0); count > 0 {
+			weight := 1 + math.Log(count)
+			embedding[i] = float32(weight)
+			norm += weight * weight
+		}
 	}
+
+	if norm > 0 {
+		norm = math.Sqrt(norm)
+		for i := range embedding {
+			embedding[i] = float32(float64(embedding[i]) / norm)
+		}
+	}
+
+	return result.Ok(embedding)
 }
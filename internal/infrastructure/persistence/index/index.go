@@ -0,0 +1,637 @@
+package index
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+)
+
+// manifestFile lists the segments of an index directory
+const manifestFile = "manifest.json"
+
+// Document is a unit of text added to the Index
+type Document struct {
+	ID    string
+	Title string
+	Text  string
+}
+
+// Hit is a Document matching a search
+type Hit struct {
+	ID string
+	// Score is the BM25 score of the Document for the query
+	Score float64
+}
+
+// Index is an inverted index over the title and text of documents, scored with BM25.
+// Documents are buffered in memory and flushed as immutable segments, which are persisted
+// to disk and merged in the background.
+type Index struct {
+	mu       sync.RWMutex
+	mergeMu  sync.Mutex
+	config   IndexConfig
+	segments []*segment // flushed segments, oldest first
+	buffer   *segment
+	live     map[string]liveDoc
+	// totalLengths sums the field lengths of live documents
+	totalLengths [numFields]int
+	nextSeq      uint64
+	nextSegment  int
+	stop         chan struct{}
+	done         chan struct{}
+	closeOnce    sync.Once
+}
+
+// liveDoc is the current version of a Document
+type liveDoc struct {
+	seq     uint64
+	lengths [numFields]int
+}
+
+// IndexConfig configuration for the index
+type IndexConfig struct {
+	// Dir stores the segments, the index is kept in memory only when empty
+	Dir string
+	// FlushThreshold is the number of buffered documents flushed as a segment, 1000 by default
+	FlushThreshold int
+	// MergeFactor is the number of segments merged together, 8 by default
+	MergeFactor int
+	// MergeInterval is how often segments are checked for merging, 30 seconds by default
+	MergeInterval time.Duration
+	// K1 and B are the BM25 term frequency saturation and length normalization, 1.2 and 0.75 by default
+	K1 float64
+	B  float64
+	// TitleWeight weighs title terms against text terms, 2 by default
+	TitleWeight float64
+	// MaxExpansions bounds the number of terms a prefix matches, 64 by default
+	MaxExpansions int
+}
+
+// manifest is the persisted state of an index directory
+type manifest struct {
+	Segments    []string `json:"segments"`
+	NextSeq     uint64   `json:"next_seq"`
+	NextSegment int      `json:"next_segment"`
+}
+
+// NewIndex creates a new Index, loading the segments of config.Dir, and starts background merges
+func NewIndex(config IndexConfig) result.Result[*Index] {
+	if config.FlushThreshold <= 0 {
+		config.FlushThreshold = 1000
+	}
+	if config.MergeFactor < 2 {
+		config.MergeFactor = 8
+	}
+	if config.MergeInterval <= 0 {
+		config.MergeInterval = 30 * time.Second
+	}
+	if config.K1 <= 0 {
+		config.K1 = 1.2
+	}
+	if config.B <= 0 || config.B > 1 {
+		config.B = 0.75
+	}
+	if config.TitleWeight <= 0 {
+		config.TitleWeight = 2
+	}
+	if config.MaxExpansions <= 0 {
+		config.MaxExpansions = 64
+	}
+
+	ix := &Index{
+		config:   config,
+		segments: make([]*segment, 0),
+		buffer:   newSegment(),
+		live:     make(map[string]liveDoc),
+		nextSeq:  1,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	if config.Dir != "" {
+		if err := ix.load(); err != nil {
+			return result.Err[*Index](fmt.Errorf("failed to load index: %w", err))
+		}
+	}
+
+	go ix.mergeLoop()
+
+	return result.Ok(ix)
+}
+
+// load reads the manifest and segments of the index directory
+func (ix *Index) load() error {
+	if err := os.MkdirAll(ix.config.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create index directory: %w", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(ix.config.Dir, manifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("failed to decode manifest: %w", err)
+	}
+
+	for _, name := range m.Segments {
+		s, err := readSegment(ix.config.Dir, name)
+		if err != nil {
+			return err
+		}
+		ix.segments = append(ix.segments, s)
+	}
+	ix.nextSeq = m.NextSeq
+	ix.nextSegment = m.NextSegment
+
+	// A Document is live when its latest version is more recent than its latest deletion
+	deleted := make(map[string]uint64)
+	for _, s := range ix.segments {
+		for _, doc := range s.Docs {
+			if current, ok := ix.live[doc.ID]; !ok || doc.Seq > current.seq {
+				ix.live[doc.ID] = liveDoc{seq: doc.Seq, lengths: doc.Lengths}
+			}
+		}
+		for id, seq := range s.Deletes {
+			deleted[id] = max(deleted[id], seq)
+		}
+	}
+	for id, seq := range deleted {
+		if current, ok := ix.live[id]; ok && current.seq < seq {
+			delete(ix.live, id)
+		}
+	}
+	for _, doc := range ix.live {
+		for f, length := range doc.lengths {
+			ix.totalLengths[f] += length
+		}
+	}
+
+	return nil
+}
+
+// Add indexes a Document, replacing any previous version with the same ID
+func (ix *Index) Add(doc Document) result.Result[bool] {
+	fields := [numFields][]string{
+		fieldTitle: tokenize(doc.Title),
+		fieldText:  tokenize(doc.Text),
+	}
+
+	ix.mu.Lock()
+	ix.removeLocked(doc.ID)
+	seq := ix.nextSeq
+	ix.nextSeq++
+	ix.buffer.add(doc.ID, seq, fields)
+
+	current := liveDoc{seq: seq}
+	for f, terms := range fields {
+		current.lengths[f] = len(terms)
+		ix.totalLengths[f] += len(terms)
+	}
+	ix.live[doc.ID] = current
+	full := len(ix.buffer.Docs) >= ix.config.FlushThreshold
+	ix.mu.Unlock()
+
+	if full {
+		return ix.Flush()
+	}
+	return result.Ok(true)
+}
+
+// Delete removes a Document from the index
+func (ix *Index) Delete(id string) result.Result[bool] {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if !ix.removeLocked(id) {
+		return result.Ok(false)
+	}
+	ix.buffer.Deletes[id] = ix.nextSeq
+	ix.nextSeq++
+	return result.Ok(true)
+}
+
+// removeLocked removes the live version of a Document, reporting whether there was one
+func (ix *Index) removeLocked(id string) bool {
+	current, ok := ix.live[id]
+	if !ok {
+		return false
+	}
+	for f, length := range current.lengths {
+		ix.totalLengths[f] -= length
+	}
+	delete(ix.live, id)
+	return true
+}
+
+// Count returns the number of indexed documents
+func (ix *Index) Count() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.live)
+}
+
+// Flush writes the buffered documents as a new segment
+func (ix *Index) Flush() result.Result[bool] {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if ix.buffer.empty() {
+		return result.Ok(false)
+	}
+
+	s := ix.buffer
+	s.seal()
+	if ix.config.Dir != "" {
+		name := fmt.Sprintf("segment-%06d.gob", ix.nextSegment)
+		if err := writeSegment(ix.config.Dir, name, s); err != nil {
+			s.dictionary = nil
+			return result.Err[bool](fmt.Errorf("failed to flush index: %w", err))
+		}
+		ix.nextSegment++
+	}
+
+	ix.segments = append(ix.segments, s)
+	ix.buffer = newSegment()
+
+	if err := ix.writeManifestLocked(); err != nil {
+		return result.Err[bool](fmt.Errorf("failed to flush index: %w", err))
+	}
+
+	return result.Ok(true)
+}
+
+// writeManifestLocked persists the list of segments
+func (ix *Index) writeManifestLocked() error {
+	if ix.config.Dir == "" {
+		return nil
+	}
+
+	m := manifest{Segments: make([]string, len(ix.segments)), NextSeq: ix.nextSeq, NextSegment: ix.nextSegment}
+	for i, s := range ix.segments {
+		m.Segments[i] = s.name
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	tmp := filepath.Join(ix.config.Dir, manifestFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(ix.config.Dir, manifestFile)); err != nil {
+		return fmt.Errorf("failed to replace manifest: %w", err)
+	}
+	return nil
+}
+
+// mergeLoop merges segments periodically until the index is closed
+func (ix *Index) mergeLoop() {
+	defer close(ix.done)
+
+	ticker := time.NewTicker(ix.config.MergeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ix.stop:
+			return
+		case <-ticker.C:
+			if merged := ix.Merge(); merged.IsErr() {
+				log.Printf("Failed to merge index segments: %v", merged.Error())
+			}
+		}
+	}
+}
+
+// Merge merges the run of MergeFactor adjacent segments with the fewest documents into one,
+// dropping superseded and deleted documents, while there are at least MergeFactor segments
+func (ix *Index) Merge() result.Result[bool] {
+	ix.mergeMu.Lock()
+	defer ix.mergeMu.Unlock()
+
+	mergedAny := false
+	for {
+		ix.mu.RLock()
+		start, end := ix.mergeRangeLocked()
+		var run []*segment
+		if start >= 0 {
+			run = append(run, ix.segments[start:end]...)
+		}
+		ix.mu.RUnlock()
+
+		if run == nil {
+			return result.Ok(mergedAny)
+		}
+
+		merged := ix.mergeSegments(run, start == 0)
+		if ix.config.Dir != "" {
+			ix.mu.Lock()
+			name := fmt.Sprintf("segment-%06d.gob", ix.nextSegment)
+			ix.nextSegment++
+			ix.mu.Unlock()
+
+			if err := writeSegment(ix.config.Dir, name, merged); err != nil {
+				return result.Err[bool](fmt.Errorf("failed to merge segments: %w", err))
+			}
+		}
+
+		// Flushes only append segments, so the merged run is still at start
+		ix.mu.Lock()
+		segments := make([]*segment, 0, len(ix.segments)-len(run)+1)
+		segments = append(segments, ix.segments[:start]...)
+		segments = append(segments, merged)
+		segments = append(segments, ix.segments[end:]...)
+		ix.segments = segments
+		err := ix.writeManifestLocked()
+		ix.mu.Unlock()
+		if err != nil {
+			return result.Err[bool](fmt.Errorf("failed to merge segments: %w", err))
+		}
+
+		for _, s := range run {
+			if s.name != "" {
+				if err := os.Remove(filepath.Join(ix.config.Dir, s.name)); err != nil {
+					log.Printf("Failed to remove merged segment %s: %v", s.name, err)
+				}
+			}
+		}
+		mergedAny = true
+	}
+}
+
+// mergeRangeLocked returns the run of segments to merge, or -1 when there are too few segments
+func (ix *Index) mergeRangeLocked() (int, int) {
+	factor := ix.config.MergeFactor
+	if len(ix.segments) < factor {
+		return -1, -1
+	}
+
+	best, bestSize := 0, math.MaxInt
+	for start := 0; start+factor <= len(ix.segments); start++ {
+		size := 0
+		for _, s := range ix.segments[start : start+factor] {
+			size += len(s.Docs)
+		}
+		if size < bestSize {
+			best, bestSize = start, size
+		}
+	}
+	return best, best + factor
+}
+
+// mergeSegments merges a run of segments into a new sealed segment. Deletions are kept,
+// as they may supersede documents of older segments, unless the run starts at the oldest one.
+func (ix *Index) mergeSegments(run []*segment, oldest bool) *segment {
+	merged := newSegment()
+
+	for _, s := range run {
+		// remap holds the document number in merged of each live document of s
+		remap := make([]int, len(s.Docs))
+		for i, doc := range s.Docs {
+			remap[i] = -1
+			if ix.isLive(doc.ID, doc.Seq) {
+				remap[i] = len(merged.Docs)
+				merged.Docs = append(merged.Docs, doc)
+			}
+		}
+
+		for term, postings := range s.Terms {
+			for _, p := range postings {
+				if remap[p.Doc] >= 0 {
+					p.Doc = remap[p.Doc]
+					merged.Terms[term] = append(merged.Terms[term], p)
+				}
+			}
+		}
+
+		if !oldest {
+			for id, seq := range s.Deletes {
+				merged.Deletes[id] = max(merged.Deletes[id], seq)
+			}
+		}
+	}
+
+	merged.seal()
+	return merged
+}
+
+func (ix *Index) isLive(id string, seq uint64) bool {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	current, ok := ix.live[id]
+	return ok && current.seq == seq
+}
+
+// Close stops background merges and flushes the buffered documents
+func (ix *Index) Close() result.Result[bool] {
+	ix.closeOnce.Do(func() {
+		close(ix.stop)
+	})
+	<-ix.done
+	return ix.Flush()
+}
+
+// Search returns the documents matching every clause of the query, by decreasing BM25 score.
+// The query is made of words, "quoted phrases" and prefixes ending with '*', like "craw*".
+// A limit of 0 returns every hit.
+func (ix *Index) Search(query string, limit int) result.Result[[]Hit] {
+	return ix.search(query, limit, true)
+}
+
+// SearchAny returns the documents matching any clause of the query, by decreasing BM25 score
+// summed over the clauses they match. A limit of 0 returns every hit.
+func (ix *Index) SearchAny(query string, limit int) result.Result[[]Hit] {
+	return ix.search(query, limit, false)
+}
+
+func (ix *Index) search(query string, limit int, matchAll bool) result.Result[[]Hit] {
+	clauses := parseQuery(query)
+	if len(clauses) == 0 {
+		return result.ErrMsg[[]Hit]("query is empty")
+	}
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	var scores map[string]float64
+	for i, c := range clauses {
+		matched := ix.matchClause(c)
+		if i == 0 {
+			scores = matched
+			continue
+		}
+		if !matchAll {
+			for id, s := range matched {
+				scores[id] += s
+			}
+			continue
+		}
+		for id, score := range scores {
+			if s, ok := matched[id]; ok {
+				scores[id] = score + s
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return result.Ok(hits)
+}
+
+// fieldPositions are the positions of a term in each field of a document
+type fieldPositions = [numFields][]int
+
+// postingsLocked returns the positions of a term in each live document containing it
+func (ix *Index) postingsLocked(term string) map[string]fieldPositions {
+	byID := make(map[string]fieldPositions)
+	for _, s := range ix.allSegmentsLocked() {
+		for _, p := range s.Terms[term] {
+			doc := s.Docs[p.Doc]
+			if current, ok := ix.live[doc.ID]; ok && current.seq == doc.Seq {
+				byID[doc.ID] = p.Positions
+			}
+		}
+	}
+	return byID
+}
+
+func (ix *Index) allSegmentsLocked() []*segment {
+	return append(append(make([]*segment, 0, len(ix.segments)+1), ix.segments...), ix.buffer)
+}
+
+// matchClause returns the score of each document matching the clause
+func (ix *Index) matchClause(c clause) map[string]float64 {
+	scores := make(map[string]float64)
+
+	switch c.kind {
+	case clauseTerm:
+		postings := ix.postingsLocked(c.terms[0])
+		for id, positions := range postings {
+			scores[id] = ix.bm25(len(postings), id, positions)
+		}
+
+	case clausePrefix:
+		expansions := make(map[string]bool)
+		for _, s := range ix.allSegmentsLocked() {
+			s.withPrefix(c.terms[0], expansions)
+		}
+		terms := make([]string, 0, len(expansions))
+		for term := range expansions {
+			terms = append(terms, term)
+		}
+		// Shorter terms are closer to the prefix and kept first
+		sort.Slice(terms, func(i, j int) bool {
+			if len(terms[i]) != len(terms[j]) {
+				return len(terms[i]) < len(terms[j])
+			}
+			return terms[i] < terms[j]
+		})
+		if len(terms) > ix.config.MaxExpansions {
+			terms = terms[:ix.config.MaxExpansions]
+		}
+
+		for _, term := range terms {
+			postings := ix.postingsLocked(term)
+			for id, positions := range postings {
+				scores[id] += ix.bm25(len(postings), id, positions)
+			}
+		}
+
+	case clausePhrase:
+		postings := make([]map[string]fieldPositions, len(c.terms))
+		for i, term := range c.terms {
+			postings[i] = ix.postingsLocked(term)
+		}
+		for id := range postings[0] {
+			if !containsPhrase(postings, id) {
+				continue
+			}
+			score := 0.0
+			for _, p := range postings {
+				score += ix.bm25(len(p), id, p[id])
+			}
+			scores[id] = score
+		}
+	}
+
+	return scores
+}
+
+// containsPhrase reports whether the terms of postings appear consecutively in a field of the document
+func containsPhrase(postings []map[string]fieldPositions, id string) bool {
+	positions := make([]fieldPositions, len(postings))
+	for i, p := range postings {
+		pos, ok := p[id]
+		if !ok {
+			return false
+		}
+		positions[i] = pos
+	}
+
+	for f := field(0); f < numFields; f++ {
+		for _, start := range positions[0][f] {
+			found := true
+			for i := 1; i < len(positions) && found; i++ {
+				next := positions[i][f]
+				j := sort.SearchInts(next, start+i)
+				found = j < len(next) && next[j] == start+i
+			}
+			if found {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bm25 scores a term found in df documents for a document, combining fields as in BM25F:
+// field frequencies are length-normalized and weighted before saturation
+func (ix *Index) bm25(df int, id string, positions fieldPositions) float64 {
+	n := float64(len(ix.live))
+	idf := math.Log(1 + (n-float64(df)+0.5)/(float64(df)+0.5))
+
+	weights := [numFields]float64{fieldTitle: ix.config.TitleWeight, fieldText: 1}
+	lengths := ix.live[id].lengths
+	k1, b := ix.config.K1, ix.config.B
+
+	tf := 0.0
+	for f := field(0); f < numFields; f++ {
+		if len(positions[f]) == 0 {
+			continue
+		}
+		avg := float64(ix.totalLengths[f]) / n
+		norm := 1 - b + b*float64(lengths[f])/avg
+		tf += weights[f] * float64(len(positions[f])) / norm
+	}
+
+	return idf * tf * (k1 + 1) / (tf + k1)
+}
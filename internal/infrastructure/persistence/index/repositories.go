@@ -0,0 +1,339 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/gerthdala/webcrawler/internal/domain/content"
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
+	"github.com/gerthdala/webcrawler/internal/domain/search"
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+	"github.com/google/uuid"
+)
+
+// maxHitBatch bounds the number of hits whose records are loaded at once
+const maxHitBatch = 500
+
+// PageRepository decorates a crawler.PageRepository, indexing saved Pages
+// and answering Search from the Index
+type PageRepository struct {
+	crawler.PageRepository
+	index *Index
+}
+
+// NewPageRepository creates a new PageRepository indexing the Pages of repo
+func NewPageRepository(repo crawler.PageRepository, index *Index) *PageRepository {
+	return &PageRepository{
+		PageRepository: repo,
+		index:          index,
+	}
+}
+
+// Save stores and indexes a Page, unless it duplicates an already crawled canonical Page
+func (r *PageRepository) Save(ctx context.Context, page *crawler.Page) result.Result[*crawler.Page] {
+	saved := r.PageRepository.Save(ctx, page)
+	if saved.IsErr() {
+		return saved
+	}
+
+	p := saved.Unwrap()
+	if p.IsDuplicate() {
+		if deleted := r.index.Delete(p.ID.String()); deleted.IsErr() {
+			return result.Err[*crawler.Page](fmt.Errorf("failed to remove duplicate Page from index: %w", deleted.Error()))
+		}
+		return saved
+	}
+	if indexed := r.index.Add(Document{ID: p.ID.String(), Title: p.Title, Text: p.PlainText}); indexed.IsErr() {
+		return result.Err[*crawler.Page](fmt.Errorf("failed to index Page: %w", indexed.Error()))
+	}
+
+	return saved
+}
+
+// Search searches pages with the Index, by decreasing BM25 score
+func (r *PageRepository) Search(ctx context.Context, query string, limit int) result.Result[[]crawler.Page] {
+	hitsResult := r.index.Search(query, 0)
+	if hitsResult.IsErr() {
+		return result.Err[[]crawler.Page](fmt.Errorf("failed to search Page index: %w", hitsResult.Error()))
+	}
+
+	pages := make([]crawler.Page, 0)
+	err := loadHits(ctx, hitsResult.Unwrap(), limit, r.PageRepository.FindByIDs,
+		func(p *crawler.Page) uuid.UUID { return p.ID },
+		func(p *crawler.Page, hit Hit) bool {
+			pages = append(pages, *p)
+			return true
+		})
+	if err != nil {
+		return result.Err[[]crawler.Page](fmt.Errorf("failed to load Page hits: %w", err))
+	}
+
+	return result.Ok(pages)
+}
+
+// DeleteOlderThan deletes pages older than the given duration and removes them from the Index
+func (r *PageRepository) DeleteOlderThan(ctx context.Context, days int) result.Result[int] {
+	idsResult := r.PageRepository.FindIDsOlderThan(ctx, days)
+	if idsResult.IsErr() {
+		return result.Err[int](idsResult.Error())
+	}
+
+	deleted := r.PageRepository.DeleteOlderThan(ctx, days)
+	if deleted.IsErr() {
+		return deleted
+	}
+	if err := unindexDeleted(ctx, r.index, idsResult.Unwrap(), r.PageRepository.FindByIDs,
+		func(p *crawler.Page) uuid.UUID { return p.ID }); err != nil {
+		return result.Err[int](fmt.Errorf("failed to remove deleted Pages from index: %w", err))
+	}
+
+	return deleted
+}
+
+// ContentRepository decorates a content.ContentRepository, indexing saved Content
+// and answering Search, KeywordSearch and KeywordRanking from the Index
+type ContentRepository struct {
+	content.ContentRepository
+	index *Index
+}
+
+// NewContentRepository creates a new ContentRepository indexing the Content of repo
+func NewContentRepository(repo content.ContentRepository, index *Index) *ContentRepository {
+	return &ContentRepository{
+		ContentRepository: repo,
+		index:             index,
+	}
+}
+
+// Save stores and indexes a Content
+func (r *ContentRepository) Save(ctx context.Context, c *content.Content) result.Result[*content.Content] {
+	return r.indexed(r.ContentRepository.Save(ctx, c))
+}
+
+// Update updates and re-indexes a stored Content
+func (r *ContentRepository) Update(ctx context.Context, c *content.Content) result.Result[*content.Content] {
+	return r.indexed(r.ContentRepository.Update(ctx, c))
+}
+
+func (r *ContentRepository) indexed(saved result.Result[*content.Content]) result.Result[*content.Content] {
+	if saved.IsErr() {
+		return saved
+	}
+
+	c := saved.Unwrap()
+	if indexed := r.index.Add(Document{ID: c.ID.String(), Title: c.Title, Text: c.Text}); indexed.IsErr() {
+		return result.Err[*content.Content](fmt.Errorf("failed to index Content: %w", indexed.Error()))
+	}
+
+	return saved
+}
+
+// Search searches Content with the Index, by decreasing BM25 score
+func (r *ContentRepository) Search(ctx context.Context, query string, limit int) result.Result[[]content.Content] {
+	scoredResult := r.KeywordSearch(ctx, query, content.SearchFilter{}, limit)
+	if scoredResult.IsErr() {
+		return result.Err[[]content.Content](scoredResult.Error())
+	}
+
+	contents := make([]content.Content, len(scoredResult.Unwrap()))
+	for i, scored := range scoredResult.Unwrap() {
+		contents[i] = scored.Content
+	}
+	return result.Ok(contents)
+}
+
+// KeywordSearch searches Content matching the filter with the Index, by decreasing BM25 score.
+// With a filter query, the Content matching any of the query text is matched against the
+// boolean query, so that "a OR b" finds Content containing either. An empty query is answered
+// by the decorated repository.
+func (r *ContentRepository) KeywordSearch(ctx context.Context, query string, filter content.SearchFilter, limit int) result.Result[[]content.ScoredContent] {
+	if strings.TrimSpace(query) == "" {
+		return r.ContentRepository.KeywordSearch(ctx, query, filter, limit)
+	}
+
+	var hitsResult result.Result[[]Hit]
+	if filter.Query != nil {
+		hitsResult = r.index.SearchAny(query, 0)
+	} else {
+		hitsResult = r.index.Search(query, 0)
+	}
+	if hitsResult.IsErr() {
+		return result.Err[[]content.ScoredContent](fmt.Errorf("failed to search Content index: %w", hitsResult.Error()))
+	}
+
+	matches := filterPredicate(filter)
+	scored := make([]content.ScoredContent, 0)
+	err := loadHits(ctx, hitsResult.Unwrap(), limit, r.ContentRepository.FindByIDs,
+		func(c *content.Content) uuid.UUID { return c.ID },
+		func(c *content.Content, hit Hit) bool {
+			if !matches(c) {
+				return false
+			}
+			scored = append(scored, content.ScoredContent{Content: *c, Score: hit.Score})
+			return true
+		})
+	if err != nil {
+		return result.Err[[]content.ScoredContent](fmt.Errorf("failed to load Content hits: %w", err))
+	}
+
+	return result.Ok(scored)
+}
+
+// KeywordRanking ranks Content as KeywordSearch does. The Content of the hits is still loaded
+// to match it against the filter.
+func (r *ContentRepository) KeywordRanking(ctx context.Context, query string, filter content.SearchFilter, limit int) result.Result[[]content.ScoredID] {
+	if strings.TrimSpace(query) == "" {
+		return r.ContentRepository.KeywordRanking(ctx, query, filter, limit)
+	}
+
+	scoredResult := r.KeywordSearch(ctx, query, filter, limit)
+	if scoredResult.IsErr() {
+		return result.Err[[]content.ScoredID](scoredResult.Error())
+	}
+
+	ids := make([]content.ScoredID, len(scoredResult.Unwrap()))
+	for i, scored := range scoredResult.Unwrap() {
+		ids[i] = content.ScoredID{ID: scored.Content.ID, Score: scored.Score}
+	}
+	return result.Ok(ids)
+}
+
+// DeleteOlderThan deletes Content older than the given duration and removes it from the Index
+func (r *ContentRepository) DeleteOlderThan(ctx context.Context, days int) result.Result[int] {
+	idsResult := r.ContentRepository.FindIDsOlderThan(ctx, days)
+	if idsResult.IsErr() {
+		return result.Err[int](idsResult.Error())
+	}
+
+	deleted := r.ContentRepository.DeleteOlderThan(ctx, days)
+	if deleted.IsErr() {
+		return deleted
+	}
+	if err := unindexDeleted(ctx, r.index, idsResult.Unwrap(), r.ContentRepository.FindByIDs,
+		func(c *content.Content) uuid.UUID { return c.ID }); err != nil {
+		return result.Err[int](fmt.Errorf("failed to remove deleted Content from index: %w", err))
+	}
+
+	return deleted
+}
+
+// loadHits loads the stored records of hits in batches, passing them to keep in hit order until it kept
+// limit of them, all when limit is zero. Records deleted from the store since they were indexed are skipped.
+func loadHits[T any](
+	ctx context.Context,
+	hits []Hit,
+	limit int,
+	findByIDs func(ctx context.Context, ids []uuid.UUID) result.Result[[]T],
+	idOf func(record *T) uuid.UUID,
+	keep func(record *T, hit Hit) bool,
+) error {
+	batchSize := maxHitBatch
+	if limit > 0 {
+		batchSize = min(limit, maxHitBatch)
+	}
+
+	kept := 0
+	for start := 0; start < len(hits) && (limit <= 0 || kept < limit); {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		end := min(start+batchSize, len(hits))
+		batch := hits[start:end]
+		ids := make([]uuid.UUID, 0, len(batch))
+		for _, hit := range batch {
+			if id, err := uuid.Parse(hit.ID); err == nil {
+				ids = append(ids, id)
+			}
+		}
+
+		recordsResult := findByIDs(ctx, ids)
+		if recordsResult.IsErr() {
+			return recordsResult.Error()
+		}
+		records := recordsResult.Unwrap()
+		byID := make(map[string]*T, len(records))
+		for i := range records {
+			byID[idOf(&records[i]).String()] = &records[i]
+		}
+
+		for _, hit := range batch {
+			if limit > 0 && kept >= limit {
+				break
+			}
+			if record, ok := byID[hit.ID]; ok && keep(record, hit) {
+				kept++
+			}
+		}
+
+		// Hits filtered out or deleted are made up for with larger batches
+		start = end
+		batchSize = min(batchSize*2, maxHitBatch)
+	}
+
+	return nil
+}
+
+// unindexDeleted removes the documents of the given IDs missing from the store from the index
+func unindexDeleted[T any](
+	ctx context.Context,
+	index *Index,
+	ids []uuid.UUID,
+	findByIDs func(ctx context.Context, ids []uuid.UUID) result.Result[[]T],
+	idOf func(record *T) uuid.UUID,
+) error {
+	for start := 0; start < len(ids); start += maxHitBatch {
+		batch := ids[start:min(start+maxHitBatch, len(ids))]
+
+		// Records updated since their IDs were found are kept
+		remainingResult := findByIDs(ctx, batch)
+		if remainingResult.IsErr() {
+			return remainingResult.Error()
+		}
+		records := remainingResult.Unwrap()
+		remaining := make(map[uuid.UUID]bool, len(records))
+		for i := range records {
+			remaining[idOf(&records[i])] = true
+		}
+
+		for _, id := range batch {
+			if remaining[id] {
+				continue
+			}
+			if deleted := index.Delete(id.String()); deleted.IsErr() {
+				return deleted.Error()
+			}
+		}
+	}
+
+	return nil
+}
+
+// filterPredicate matches Content against a search filter in memory
+func filterPredicate(filter content.SearchFilter) func(c *content.Content) bool {
+	matchesQuery := search.Predicate(filter.Query)
+
+	return func(c *content.Content) bool {
+		if filter.Domain != "" {
+			parsed, err := url.Parse(c.URL)
+			if err != nil || !strings.EqualFold(parsed.Hostname(), filter.Domain) {
+				return false
+			}
+		}
+		if filter.Language != "" && c.Language != filter.Language {
+			return false
+		}
+		if filter.ContentType != "" && c.Classification != filter.ContentType {
+			return false
+		}
+		if !filter.CreatedAfter.IsZero() && c.CreatedAt.Before(filter.CreatedAfter) {
+			return false
+		}
+		if !filter.CreatedBefore.IsZero() && !c.CreatedAt.Before(filter.CreatedBefore) {
+			return false
+		}
+		return matchesQuery(c)
+	}
+}
@@ -0,0 +1,158 @@
+package index
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// field is an indexed field of a Document
+type field int
+
+const (
+	fieldTitle field = iota
+	fieldText
+	numFields
+)
+
+// segmentDoc is a version of a Document stored in a segment
+type segmentDoc struct {
+	ID  string
+	Seq uint64
+	// Lengths is the number of terms of each field
+	Lengths [numFields]int
+}
+
+// posting lists the positions of a term in each field of a segment document
+type posting struct {
+	Doc       int
+	Positions [numFields][]int
+}
+
+// segment is an inverted index over a batch of documents. Segments are immutable once
+// sealed; a Document updated or deleted later is superseded by a higher sequence number.
+type segment struct {
+	Docs  []segmentDoc
+	Terms map[string][]posting
+	// Deletes holds the sequence number of the deletion of each deleted Document ID
+	Deletes map[string]uint64
+
+	name       string   // file name, empty while in memory only
+	dictionary []string // sorted terms, set when sealed
+}
+
+func newSegment() *segment {
+	return &segment{
+		Docs:    make([]segmentDoc, 0),
+		Terms:   make(map[string][]posting),
+		Deletes: make(map[string]uint64),
+	}
+}
+
+// add appends a document version with the terms of each field
+func (s *segment) add(id string, seq uint64, fields [numFields][]string) {
+	doc := len(s.Docs)
+	entry := segmentDoc{ID: id, Seq: seq}
+	postings := make(map[string]*posting)
+
+	for f, terms := range fields {
+		entry.Lengths[f] = len(terms)
+		for pos, term := range terms {
+			p, ok := postings[term]
+			if !ok {
+				p = &posting{Doc: doc}
+				postings[term] = p
+			}
+			p.Positions[f] = append(p.Positions[f], pos)
+		}
+	}
+
+	s.Docs = append(s.Docs, entry)
+	for term, p := range postings {
+		s.Terms[term] = append(s.Terms[term], *p)
+	}
+}
+
+func (s *segment) empty() bool {
+	return len(s.Docs) == 0 && len(s.Deletes) == 0
+}
+
+// seal builds the sorted term dictionary used for prefix matching
+func (s *segment) seal() {
+	s.dictionary = make([]string, 0, len(s.Terms))
+	for term := range s.Terms {
+		s.dictionary = append(s.dictionary, term)
+	}
+	sort.Strings(s.dictionary)
+}
+
+// withPrefix appends the terms of the segment starting with prefix to terms
+func (s *segment) withPrefix(prefix string, terms map[string]bool) {
+	if s.dictionary == nil {
+		// The buffer segment is not sealed and is scanned instead
+		for term := range s.Terms {
+			if strings.HasPrefix(term, prefix) {
+				terms[term] = true
+			}
+		}
+		return
+	}
+
+	for i := sort.SearchStrings(s.dictionary, prefix); i < len(s.dictionary) && strings.HasPrefix(s.dictionary[i], prefix); i++ {
+		terms[s.dictionary[i]] = true
+	}
+}
+
+// writeSegment writes a sealed segment to a new file of dir
+func writeSegment(dir, name string, s *segment) error {
+	tmp, err := os.CreateTemp(dir, name+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create segment file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := gob.NewEncoder(tmp).Encode(s); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to encode segment: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync segment file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close segment file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, name)); err != nil {
+		return fmt.Errorf("failed to rename segment file: %w", err)
+	}
+
+	s.name = name
+	return nil
+}
+
+// readSegment reads a segment file of dir
+func readSegment(dir, name string) (*segment, error) {
+	file, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open segment %s: %w", name, err)
+	}
+	defer file.Close()
+
+	s := newSegment()
+	if err := gob.NewDecoder(file).Decode(s); err != nil {
+		return nil, fmt.Errorf("failed to decode segment %s: %w", name, err)
+	}
+	if s.Terms == nil {
+		s.Terms = make(map[string][]posting)
+	}
+	if s.Deletes == nil {
+		s.Deletes = make(map[string]uint64)
+	}
+
+	s.name = name
+	s.seal()
+	return s, nil
+}
@@ -0,0 +1,73 @@
+package index
+
+import (
+	"strings"
+	"unicode"
+)
+
+// tokenize splits text into lower case terms of letters and digits, in order of position
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// clauseKind is the kind of a query clause
+type clauseKind int
+
+const (
+	clauseTerm clauseKind = iota
+	clausePhrase
+	clausePrefix
+)
+
+// clause is a part of a query every hit must match
+type clause struct {
+	kind  clauseKind
+	terms []string
+}
+
+// parseQuery parses a query of words, "quoted phrases" and prefixes ending with '*'
+func parseQuery(input string) []clause {
+	clauses := make([]clause, 0)
+
+	addWords := func(text string, prefix bool) {
+		terms := tokenize(text)
+		switch {
+		case len(terms) == 0:
+		case prefix:
+			// Only the last term of a prefix like "web-craw*" is incomplete
+			for _, term := range terms[:len(terms)-1] {
+				clauses = append(clauses, clause{kind: clauseTerm, terms: []string{term}})
+			}
+			clauses = append(clauses, clause{kind: clausePrefix, terms: terms[len(terms)-1:]})
+		case len(terms) == 1:
+			clauses = append(clauses, clause{kind: clauseTerm, terms: terms})
+		default:
+			// Words joined by punctuation, like "e-mail", are matched as a phrase
+			clauses = append(clauses, clause{kind: clausePhrase, terms: terms})
+		}
+	}
+
+	for rest := strings.TrimSpace(input); rest != ""; rest = strings.TrimSpace(rest) {
+		if rest[0] == '"' {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			addWords(rest[1:end+1], false)
+			rest = rest[min(end+2, len(rest)):]
+			continue
+		}
+
+		end := strings.IndexFunc(rest, func(r rune) bool { return unicode.IsSpace(r) || r == '"' })
+		if end < 0 {
+			end = len(rest)
+		}
+		word := rest[:end]
+		addWords(strings.TrimSuffix(word, "*"), strings.HasSuffix(word, "*"))
+		rest = rest[end:]
+	}
+
+	return clauses
+}
@@ -0,0 +1,142 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gerthdala/webcrawler/internal/domain/content"
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// ChunkRepository implements content.ChunkRepository using PostgreSQL
+type ChunkRepository struct {
+	db *gorm.DB
+}
+
+// NewChunkRepository creates a new ChunkRepository
+func NewChunkRepository(db *gorm.DB) *ChunkRepository {
+	return &ChunkRepository{
+		db: db,
+	}
+}
+
+// chunkMatchRow is a chunk row joined with its content and distance
+type chunkMatchRow struct {
+	ChunkModel
+	ContentURL   string
+	ContentTitle string
+	Distance     float64
+}
+
+// SaveAll replaces the Chunks of a Content
+func (r *ChunkRepository) SaveAll(ctx context.Context, contentID uuid.UUID, chunks []content.Chunk) result.Result[[]content.Chunk] {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return replaceChunks(tx, contentID, chunks)
+	})
+	if err != nil {
+		return result.Err[[]content.Chunk](err)
+	}
+
+	return result.Ok(chunks)
+}
+
+// FindByContentID finds the Chunks of a Content, ordered by index
+func (r *ChunkRepository) FindByContentID(ctx context.Context, contentID uuid.UUID) result.Result[[]content.Chunk] {
+	tx := r.db.WithContext(ctx)
+	var models []ChunkModel
+
+	if err := tx.Where("content_id = ?", contentID).
+		Order("chunk_index ASC").
+		Find(&models).Error; err != nil {
+		return result.Err[[]content.Chunk](fmt.Errorf("failed to find Chunks by content ID: %w", err))
+	}
+
+	chunks := make([]content.Chunk, len(models))
+	for i, model := range models {
+		chunks[i] = model.ToDomain()
+	}
+
+	return result.Ok(chunks)
+}
+
+// FindNearest finds the Chunks of Content matching the filter nearest to the given vector embedding
+func (r *ChunkRepository) FindNearest(ctx context.Context, embedding []float32, filter content.ChunkFilter, limit int) result.Result[[]content.ChunkMatch] {
+	tx := r.db.WithContext(ctx)
+	var rows []chunkMatchRow
+
+	conditions := []string{"TRUE"}
+	args := []interface{}{pq.Float32Array(embedding)}
+	if filter.Domain != "" {
+		conditions = append(conditions, "lower(substring(c.url from '^[a-zA-Z][a-zA-Z0-9+.-]*://([^/:?#]+)')) = lower(?)")
+		args = append(args, filter.Domain)
+	}
+	if filter.Language != "" {
+		conditions = append(conditions, "c.language = ?")
+		args = append(args, filter.Language)
+	}
+	if filter.ContentType != "" {
+		conditions = append(conditions, "c.classification = ?")
+		args = append(args, string(filter.ContentType))
+	}
+	if filter.URLPrefix != "" {
+		conditions = append(conditions, `c.url LIKE ? ESCAPE '\'`)
+		args = append(args, escapeLike(filter.URLPrefix)+"%")
+	}
+	args = append(args, limit)
+
+	// Cosine distance using the pgvector extension
+	if err := tx.Raw(`
+		SELECT ch.*, c.url AS content_url, c.title AS content_title,
+			ch.vector_embedding <=> ? AS distance
+		FROM content_chunks ch
+		JOIN contents c ON c.id = ch.content_id
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY distance
+		LIMIT ?
+	`, args...).Scan(&rows).Error; err != nil {
+		return result.Err[[]content.ChunkMatch](fmt.Errorf("failed to find nearest Chunks: %w", err))
+	}
+
+	matches := make([]content.ChunkMatch, len(rows))
+	for i, row := range rows {
+		matches[i] = content.ChunkMatch{
+			Chunk:        row.ChunkModel.ToDomain(),
+			ContentURL:   row.ContentURL,
+			ContentTitle: row.ContentTitle,
+			Distance:     row.Distance,
+		}
+	}
+
+	return result.Ok(matches)
+}
+
+// DeleteByContentID deletes the Chunks of a Content
+func (r *ChunkRepository) DeleteByContentID(ctx context.Context, contentID uuid.UUID) result.Result[int] {
+	tx := r.db.WithContext(ctx)
+
+	resultD := tx.Where("content_id = ?", contentID).Delete(&ChunkModel{})
+	if resultD.Error != nil {
+		return result.Err[int](fmt.Errorf("failed to delete Chunks: %w", resultD.Error))
+	}
+
+	return result.Ok(int(resultD.RowsAffected))
+}
+
+// replaceChunks replaces the chunks of a content within a transaction
+func replaceChunks(tx *gorm.DB, contentID uuid.UUID, chunks []content.Chunk) error {
+	if err := tx.Where("content_id = ?", contentID).Delete(&ChunkModel{}).Error; err != nil {
+		return fmt.Errorf("failed to delete Chunks: %w", err)
+	}
+
+	for _, chunk := range chunks {
+		if err := tx.Create(ChunkModelFromDomain(chunk, contentID)).Error; err != nil {
+			return fmt.Errorf("failed to save Chunk: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/gerthdala/webcrawler/internal/domain/content"
+	"github.com/gerthdala/webcrawler/internal/infrastructure/persistence/postgres/textsearch"
 	result "github.com/gerthdala/webcrawler/pkg/utils/result"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
@@ -48,6 +49,49 @@ func (r *ContentRepository) Save(ctx context.Context, contentData *content.Conte
 		}
 	}
 
+	for _, chunk := range contentData.Chunks {
+		chunkModel := ChunkModelFromDomain(chunk, contentData.ID)
+		if err := tx.Create(chunkModel).Error; err != nil {
+			return result.Err[*content.Content](fmt.Errorf("failed to save Chunk: %w", err))
+		}
+	}
+
+	return result.Ok(contentData)
+}
+
+// Update updates a stored Content, replacing its entities, topics and chunks
+func (r *ContentRepository) Update(ctx context.Context, contentData *content.Content) result.Result[*content.Content] {
+	model := ContentModelFromDomain(contentData)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(model).Error; err != nil {
+			return fmt.Errorf("failed to update Content: %w", err)
+		}
+
+		if err := tx.Where("content_id = ?", contentData.ID).Delete(&NamedEntityModel{}).Error; err != nil {
+			return fmt.Errorf("failed to delete NamedEntities: %w", err)
+		}
+		for _, entity := range contentData.NamedEntities {
+			if err := tx.Create(NamedEntityModelFromDomain(entity, contentData.ID)).Error; err != nil {
+				return fmt.Errorf("failed to save NamedEntity: %w", err)
+			}
+		}
+
+		if err := tx.Where("content_id = ?", contentData.ID).Delete(&TopicModel{}).Error; err != nil {
+			return fmt.Errorf("failed to delete Topics: %w", err)
+		}
+		for _, topic := range contentData.Topics {
+			if err := tx.Create(TopicModelFromDomain(topic, contentData.ID)).Error; err != nil {
+				return fmt.Errorf("failed to save Topic: %w", err)
+			}
+		}
+
+		return replaceChunks(tx, contentData.ID, contentData.Chunks)
+	})
+	if err != nil {
+		return result.Err[*content.Content](err)
+	}
+
 	return result.Ok(contentData)
 }
 
@@ -82,9 +126,61 @@ func (r *ContentRepository) FindByID(ctx context.Context, id uuid.UUID) result.R
 		contentObject.Topics = append(contentObject.Topics, topicModel.ToDomain())
 	}
 
+	var chunkModels []ChunkModel
+	if err := tx.Where("content_id = ?", id).Order("chunk_index ASC").Find(&chunkModels).Error; err != nil {
+		return result.Err[*content.Content](fmt.Errorf("failed to load Chunks: %w", err))
+	}
+
+	for _, chunkModel := range chunkModels {
+		contentObject.Chunks = append(contentObject.Chunks, chunkModel.ToDomain())
+	}
+
 	return result.Ok(contentObject)
 }
 
+// FindByIDs finds the stored Content among the given IDs, with their entities and topics
+func (r *ContentRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) result.Result[[]content.Content] {
+	if len(ids) == 0 {
+		return result.Ok([]content.Content{})
+	}
+
+	tx := r.db.WithContext(ctx)
+	var models []ContentModel
+
+	if err := tx.Where("id IN ?", ids).Find(&models).Error; err != nil {
+		return result.Err[[]content.Content](fmt.Errorf("failed to find Content by IDs: %w", err))
+	}
+
+	// Load the associations of every Content at once
+	var entityModels []NamedEntityModel
+	if err := tx.Where("content_id IN ?", ids).Find(&entityModels).Error; err != nil {
+		return result.Err[[]content.Content](fmt.Errorf("failed to load NamedEntities: %w", err))
+	}
+	entities := make(map[uuid.UUID][]content.NamedEntity)
+	for _, entityModel := range entityModels {
+		entities[entityModel.ContentID] = append(entities[entityModel.ContentID], entityModel.ToDomain())
+	}
+
+	var topicModels []TopicModel
+	if err := tx.Where("content_id IN ?", ids).Find(&topicModels).Error; err != nil {
+		return result.Err[[]content.Content](fmt.Errorf("failed to load Topics: %w", err))
+	}
+	topics := make(map[uuid.UUID][]content.Topic)
+	for _, topicModel := range topicModels {
+		topics[topicModel.ContentID] = append(topics[topicModel.ContentID], topicModel.ToDomain())
+	}
+
+	contents := make([]content.Content, len(models))
+	for i, model := range models {
+		contentObject := model.ToDomain()
+		contentObject.NamedEntities = entities[contentObject.ID]
+		contentObject.Topics = topics[contentObject.ID]
+		contents[i] = *contentObject
+	}
+
+	return result.Ok(contents)
+}
+
 // FindByURL finds a Content by its URL
 func (r *ContentRepository) FindByURL(ctx context.Context, url string) result.Result[*content.Content] {
 	tx := r.db.WithContext(ctx)
@@ -261,8 +357,9 @@ func (r *ContentRepository) Search(ctx context.Context, query string, limit int)
 	tx := r.db.WithContext(ctx)
 	var models []ContentModel
 
-	// Using PostgreSQL's full-text search
-	if err := tx.Where("to_tsvector('english', title || ' ' || text) @@ plainto_tsquery('english', ?)", query).
+	// Using PostgreSQL's full-text search on the language-aware search vector
+	condition, args := textsearch.Match("language", "plainto_tsquery", "", query)
+	if err := tx.Where(condition, args...).
 		Order("created_at DESC").
 		Limit(limit).
 		Find(&models).Error; err != nil {
@@ -278,6 +375,131 @@ func (r *ContentRepository) Search(ctx context.Context, query string, limit int)
 	return result.Ok(contents)
 }
 
+// scoredContentRow is a content row with its search score
+type scoredContentRow struct {
+	ContentModel
+	Score float64
+}
+
+// scoredIDRow is a content ID with its search score
+type scoredIDRow struct {
+	ID    uuid.UUID
+	Score float64
+}
+
+// KeywordSearch searches Content matching the filter by text, ordered by text rank.
+// An empty text matches all Content matching the filter, ordered by creation date.
+func (r *ContentRepository) KeywordSearch(ctx context.Context, query string, filter content.SearchFilter, limit int) result.Result[[]content.ScoredContent] {
+	var rows []scoredContentRow
+	if err := r.keywordQuery(ctx, "contents.*", query, filter).Limit(limit).Scan(&rows).Error; err != nil {
+		return result.Err[[]content.ScoredContent](fmt.Errorf("failed to search Content by keywords: %w", err))
+	}
+
+	return result.Ok(scoredContents(rows))
+}
+
+// KeywordRanking ranks Content as KeywordSearch does, selecting only the IDs and scores
+func (r *ContentRepository) KeywordRanking(ctx context.Context, query string, filter content.SearchFilter, limit int) result.Result[[]content.ScoredID] {
+	var rows []scoredIDRow
+	if err := r.keywordQuery(ctx, "contents.id", query, filter).Limit(limit).Scan(&rows).Error; err != nil {
+		return result.Err[[]content.ScoredID](fmt.Errorf("failed to rank Content by keywords: %w", err))
+	}
+
+	return result.Ok(scoredIDs(rows))
+}
+
+// keywordQuery selects the columns of the Content matching the filter, with their text rank as score
+func (r *ContentRepository) keywordQuery(ctx context.Context, columns, query string, filter content.SearchFilter) *gorm.DB {
+	tx := applySearchFilter(r.db.WithContext(ctx).Model(&ContentModel{}), filter)
+
+	switch {
+	case query == "":
+		return tx.Select(columns + ", 0 AS score").Order("created_at DESC, id ASC")
+	case filter.Query != nil:
+		// The filter query selects the Content, its free text terms OR'd together only rank it
+		return tx.Select(columns+", "+textsearch.RankExpr("language", "websearch_to_tsquery")+" AS score", rankingText(filter.Query)).
+			Order("score DESC, id ASC")
+	default:
+		condition, args := textsearch.Match("language", "plainto_tsquery", filter.Language, query)
+		return tx.Select(columns+", "+textsearch.RankExpr("language", "plainto_tsquery")+" AS score", query).
+			Where(condition, args...).
+			Order("score DESC, id ASC")
+	}
+}
+
+// VectorSearch finds Content matching the filter nearest to the given vector embedding, ordered by similarity
+func (r *ContentRepository) VectorSearch(ctx context.Context, embedding []float32, filter content.SearchFilter, limit int) result.Result[[]content.ScoredContent] {
+	var rows []scoredContentRow
+	if err := r.vectorQuery(ctx, "contents.*", embedding, filter).Limit(limit).Scan(&rows).Error; err != nil {
+		return result.Err[[]content.ScoredContent](fmt.Errorf("failed to search Content by vector: %w", err))
+	}
+
+	return result.Ok(scoredContents(rows))
+}
+
+// VectorRanking ranks Content as VectorSearch does, selecting only the IDs and scores
+func (r *ContentRepository) VectorRanking(ctx context.Context, embedding []float32, filter content.SearchFilter, limit int) result.Result[[]content.ScoredID] {
+	var rows []scoredIDRow
+	if err := r.vectorQuery(ctx, "contents.id", embedding, filter).Limit(limit).Scan(&rows).Error; err != nil {
+		return result.Err[[]content.ScoredID](fmt.Errorf("failed to rank Content by vector: %w", err))
+	}
+
+	return result.Ok(scoredIDs(rows))
+}
+
+// vectorQuery selects the columns of the Content matching the filter, with their cosine similarity
+// to the embedding as score, using the pgvector extension
+func (r *ContentRepository) vectorQuery(ctx context.Context, columns string, embedding []float32, filter content.SearchFilter) *gorm.DB {
+	return applySearchFilter(r.db.WithContext(ctx).Model(&ContentModel{}), filter).
+		Select(columns+", 1 - (vector_embedding <=> ?) AS score", pq.Float32Array(embedding)).
+		Where("vector_embedding IS NOT NULL").
+		Order("score DESC, id ASC")
+}
+
+// applySearchFilter adds the conditions of a search filter to a query on contents
+func applySearchFilter(tx *gorm.DB, filter content.SearchFilter) *gorm.DB {
+	if filter.Domain != "" {
+		tx = tx.Where(contentHost+" = lower(?)", filter.Domain)
+	}
+	if filter.Language != "" {
+		tx = tx.Where("language = ?", filter.Language)
+	}
+	if filter.ContentType != "" {
+		tx = tx.Where("classification = ?", string(filter.ContentType))
+	}
+	if !filter.CreatedAfter.IsZero() {
+		tx = tx.Where("created_at >= ?", filter.CreatedAfter)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		tx = tx.Where("created_at < ?", filter.CreatedBefore)
+	}
+	if filter.Query != nil {
+		condition, args := compileQuery(filter.Query, filter.Language)
+		tx = tx.Where(condition, args...)
+	}
+	return tx
+}
+
+func scoredContents(rows []scoredContentRow) []content.ScoredContent {
+	// Convert to domain objects with minimal loading
+	scored := make([]content.ScoredContent, len(rows))
+	for i, row := range rows {
+		scored[i] = content.ScoredContent{
+			Content: *row.ContentModel.ToDomain(),
+			Score:   row.Score,
+		}
+	}
+	return scored
+}
+
+func scoredIDs(rows []scoredIDRow) []content.ScoredID {
+	scored := make([]content.ScoredID, len(rows))
+	for i, row := range rows {
+		scored[i] = content.ScoredID{ID: row.ID, Score: row.Score}
+	}
+	return scored
+}
+
 // CountByContentType counts Content by content type
 func (r *ContentRepository) CountByContentType(ctx context.Context, contentType content.ContentType) result.Result[int] {
 	tx := r.db.WithContext(ctx)
@@ -292,19 +514,201 @@ func (r *ContentRepository) CountByContentType(ctx context.Context, contentType
 	return result.Ok(int(count))
 }
 
-// DeleteOlderThan deletes Content older than the given duration
-func (r *ContentRepository) DeleteOlderThan(ctx context.Context, days int) result.Result[int] {
+// CountMatching counts Content matching the filter
+func (r *ContentRepository) CountMatching(ctx context.Context, filter content.SearchFilter) result.Result[int] {
+	tx := r.db.WithContext(ctx)
+	var count int64
+
+	if err := applySearchFilter(tx.Model(&ContentModel{}), filter).Count(&count).Error; err != nil {
+		return result.Err[int](fmt.Errorf("failed to count Content: %w", err))
+	}
+
+	return result.Ok(int(count))
+}
+
+// facetRow is a facet value and its count returned by an aggregation query
+type facetRow struct {
+	Value string
+	Count int
+}
+
+func facetBuckets(rows []facetRow) []content.FacetBucket {
+	buckets := make([]content.FacetBucket, len(rows))
+	for i, row := range rows {
+		buckets[i] = content.FacetBucket{Value: row.Value, Count: row.Count}
+	}
+	return buckets
+}
+
+// CountByFacet counts Content matching the filter by facet value, for the limit most frequent values
+func (r *ContentRepository) CountByFacet(ctx context.Context, facet content.Facet, filter content.SearchFilter, limit int) result.Result[[]content.FacetBucket] {
+	tx := r.db.WithContext(ctx)
+	var q *gorm.DB
+
+	switch facet {
+	case content.FacetClassification:
+		q = applySearchFilter(tx.Model(&ContentModel{}), filter).
+			Select("classification AS value, COUNT(*) AS count").
+			Group("classification")
+	case content.FacetLanguage:
+		q = applySearchFilter(tx.Model(&ContentModel{}), filter).
+			Select("language AS value, COUNT(*) AS count").
+			Group("language")
+	case content.FacetDomain:
+		q = applySearchFilter(tx.Model(&ContentModel{}), filter).
+			Select(contentHost + " AS value, COUNT(*) AS count").
+			Group("value")
+	case content.FacetEntityType, content.FacetTopic:
+		// Entities and topics are counted once per Content matching the filter
+		matching := applySearchFilter(tx.Model(&ContentModel{}), filter).Select("id")
+		if facet == content.FacetEntityType {
+			q = tx.Model(&NamedEntityModel{}).Select("type AS value, COUNT(DISTINCT content_id) AS count").Group("type")
+		} else {
+			q = tx.Model(&TopicModel{}).Select("name AS value, COUNT(DISTINCT content_id) AS count").Group("name")
+		}
+		q = q.Where("content_id IN (?)", matching)
+	default:
+		return result.Err[[]content.FacetBucket](fmt.Errorf("unsupported facet: %s", facet))
+	}
+
+	var rows []facetRow
+	if err := q.Order("count DESC, value ASC").Limit(limit).Scan(&rows).Error; err != nil {
+		return result.Err[[]content.FacetBucket](fmt.Errorf("failed to count Content by %s: %w", facet, err))
+	}
+
+	return result.Ok(facetBuckets(rows))
+}
+
+// CountByDate counts Content matching the filter by crawl date, in chronological order
+func (r *ContentRepository) CountByDate(ctx context.Context, interval content.DateInterval, filter content.SearchFilter) result.Result[[]content.FacetBucket] {
+	switch interval {
+	case content.DateIntervalDay, content.DateIntervalWeek, content.DateIntervalMonth:
+	default:
+		return result.Err[[]content.FacetBucket](fmt.Errorf("unsupported date interval: %s", interval))
+	}
+
+	tx := r.db.WithContext(ctx)
+	var rows []facetRow
+
+	if err := applySearchFilter(tx.Model(&ContentModel{}), filter).
+		Select(fmt.Sprintf("to_char(date_trunc('%s', created_at), 'YYYY-MM-DD') AS value, COUNT(*) AS count", interval)).
+		Group("value").
+		Order("value ASC").
+		Scan(&rows).Error; err != nil {
+		return result.Err[[]content.FacetBucket](fmt.Errorf("failed to count Content by date: %w", err))
+	}
+
+	return result.Ok(facetBuckets(rows))
+}
+
+// Histogram counts Content matching the filter in buckets of the given width of a numeric field
+func (r *ContentRepository) Histogram(ctx context.Context, field content.HistogramField, width float64, filter content.SearchFilter) result.Result[[]content.HistogramBucket] {
+	var column string
+	switch field {
+	case content.HistogramReadabilityScore:
+		column = "readability_score"
+	case content.HistogramWordCount:
+		column = "word_count"
+	default:
+		return result.Err[[]content.HistogramBucket](fmt.Errorf("unsupported histogram field: %s", field))
+	}
+	if width <= 0 {
+		return result.ErrMsg[[]content.HistogramBucket]("histogram width must be positive")
+	}
+
+	tx := r.db.WithContext(ctx)
+	var rows []struct {
+		Bucket float64
+		Count  int
+	}
+
+	if err := applySearchFilter(tx.Model(&ContentModel{}), filter).
+		Select("floor("+column+" / ?) AS bucket, COUNT(*) AS count", width).
+		Group("bucket").
+		Order("bucket ASC").
+		Scan(&rows).Error; err != nil {
+		return result.Err[[]content.HistogramBucket](fmt.Errorf("failed to compute %s histogram: %w", field, err))
+	}
+
+	buckets := make([]content.HistogramBucket, len(rows))
+	for i, row := range rows {
+		buckets[i] = content.HistogramBucket{From: row.Bucket * width, To: (row.Bucket + 1) * width, Count: row.Count}
+	}
+
+	return result.Ok(buckets)
+}
+
+// FindForReanalysis finds Content matching the filter, ordered by ID
+func (r *ContentRepository) FindForReanalysis(ctx context.Context, filter content.ReanalysisFilter, limit int) result.Result[[]content.Content] {
+	tx := r.db.WithContext(ctx).Model(&ContentModel{})
+
+	if filter.AfterID != uuid.Nil {
+		tx = tx.Where("id > ?", filter.AfterID)
+	}
+	if filter.ContentType != "" {
+		tx = tx.Where("classification = ?", string(filter.ContentType))
+	}
+	if filter.Language != "" {
+		tx = tx.Where("language = ?", filter.Language)
+	}
+	if filter.URLPrefix != "" {
+		tx = tx.Where(`url LIKE ? ESCAPE '\'`, escapeLike(filter.URLPrefix)+"%")
+	}
+	if !filter.CreatedBefore.IsZero() {
+		tx = tx.Where("created_at < ?", filter.CreatedBefore)
+	}
+
+	if len(filter.StaleVersions) > 0 {
+		stale := r.db.Where("1 = 0")
+		for step, version := range filter.StaleVersions {
+			stale = stale.Or("(model_versions ->> ?) IS DISTINCT FROM ?", string(step), version)
+		}
+		tx = tx.Where(stale)
+	}
+
+	var models []ContentModel
+	if err := tx.Order("id ASC").
+		Limit(limit).
+		Find(&models).Error; err != nil {
+		return result.Err[[]content.Content](fmt.Errorf("failed to find Content for reanalysis: %w", err))
+	}
+
+	// Convert to domain objects with minimal loading
+	contents := make([]content.Content, len(models))
+	for i, model := range models {
+		contents[i] = *model.ToDomain()
+	}
+
+	return result.Ok(contents)
+}
+
+// FindIDsOlderThan finds the IDs of the Content created before the given number of days
+func (r *ContentRepository) FindIDsOlderThan(ctx context.Context, days int) result.Result[[]uuid.UUID] {
 	tx := r.db.WithContext(ctx)
 	cutoff := time.Now().AddDate(0, 0, -days)
 
-	// Delete associated entities first
 	var contentIDs []uuid.UUID
 	if err := tx.Model(&ContentModel{}).
 		Where("created_at < ?", cutoff).
 		Pluck("id", &contentIDs).Error; err != nil {
-		return result.Err[int](fmt.Errorf("failed to get old Content IDs: %w", err))
+		return result.Err[[]uuid.UUID](fmt.Errorf("failed to get old Content IDs: %w", err))
 	}
 
+	return result.Ok(contentIDs)
+}
+
+// DeleteOlderThan deletes Content older than the given duration
+func (r *ContentRepository) DeleteOlderThan(ctx context.Context, days int) result.Result[int] {
+	tx := r.db.WithContext(ctx)
+	cutoff := time.Now().AddDate(0, 0, -days)
+
+	// Delete associated entities first
+	idsResult := r.FindIDsOlderThan(ctx, days)
+	if idsResult.IsErr() {
+		return result.Err[int](idsResult.Error())
+	}
+	contentIDs := idsResult.Unwrap()
+
 	if len(contentIDs) > 0 {
 		if err := tx.Where("content_id IN ?", contentIDs).Delete(&NamedEntityModel{}).Error; err != nil {
 			return result.Err[int](fmt.Errorf("failed to delete NamedEntities: %w", err))
@@ -314,6 +718,10 @@ func (r *ContentRepository) DeleteOlderThan(ctx context.Context, days int) resul
 			return result.Err[int](fmt.Errorf("failed to delete Topics: %w", err))
 		}
 
+		if err := tx.Where("content_id IN ?", contentIDs).Delete(&ChunkModel{}).Error; err != nil {
+			return result.Err[int](fmt.Errorf("failed to delete Chunks: %w", err))
+		}
+
 		if err := tx.Where("content_id IN ? OR similar_to_id IN ?", contentIDs, contentIDs).Delete(&SimilarContentModel{}).Error; err != nil {
 			return result.Err[int](fmt.Errorf("failed to delete SimilarContents: %w", err))
 		}
@@ -325,5 +733,5 @@ func (r *ContentRepository) DeleteOlderThan(ctx context.Context, days int) resul
 		return result.Err[int](fmt.Errorf("failed to delete old Contents: %w", resultD.Error))
 	}
 
-	return result.Ok(int(result.RowsAffected))
+	return result.Ok(int(resultD.RowsAffected))
 }
@@ -0,0 +1,34 @@
+package content
+
+import (
+	"fmt"
+
+	"github.com/gerthdala/webcrawler/internal/infrastructure/persistence/postgres/textsearch"
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+	"gorm.io/gorm"
+)
+
+// Migrate creates or updates the content tables, with the pgvector extension of their embeddings, and their search vectors
+func Migrate(db *gorm.DB) result.Result[bool] {
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS vector").Error; err != nil {
+		return result.Err[bool](fmt.Errorf("failed to create vector extension: %w", err))
+	}
+	if err := db.AutoMigrate(&ContentModel{}, &NamedEntityModel{}, &TopicModel{}, &ChunkModel{}, &SimilarContentModel{}); err != nil {
+		return result.Err[bool](fmt.Errorf("failed to migrate content tables: %w", err))
+	}
+
+	return MigrateSearchVectors(db)
+}
+
+// MigrateSearchVectors adds the language-aware generated search vector and its GIN index to the contents table
+func MigrateSearchVectors(db *gorm.DB) result.Result[bool] {
+	err := textsearch.AddVectorColumn(db, ContentModel{}.TableName(), "language",
+		textsearch.WeightedColumn{Name: "title", Weight: "A"},
+		textsearch.WeightedColumn{Name: "text", Weight: "B"},
+	)
+	if err != nil {
+		return result.Err[bool](err)
+	}
+
+	return result.Ok(true)
+}
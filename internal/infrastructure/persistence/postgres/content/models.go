@@ -23,6 +23,9 @@ type ContentModel struct {
 	WordCount        int
 	SentenceCount    int
 	VectorEmbedding  pq.Float32Array `gorm:"type:vector(384)"`  // Adjust vector dimension as needed
+	AnalysisReport   *AnalysisReportModel `gorm:"type:jsonb;serializer:json"`
+	ModelVersions    map[string]string    `gorm:"type:jsonb;serializer:json"`
+	Feed             *FeedMetadataModel   `gorm:"type:jsonb;serializer:json"`
 	CreatedAt        time.Time       `gorm:"index;not null"`
 	UpdatedAt        time.Time       `gorm:"not null"`
 }
@@ -48,6 +51,9 @@ func (m *ContentModel) ToDomain() *content.Content {
 		WordCount:        m.WordCount,
 		SentenceCount:    m.SentenceCount,
 		VectorEmbedding:  []float32(m.VectorEmbedding),
+		AnalysisReport:   m.AnalysisReport.ToDomain(),
+		ModelVersions:    modelVersionsToDomain(m.ModelVersions),
+		Feed:             m.Feed.ToDomain(),
 		CreatedAt:        m.CreatedAt,
 		UpdatedAt:        m.UpdatedAt,
 	}
@@ -70,11 +76,134 @@ func ContentModelFromDomain(c *content.Content) *ContentModel {
 		WordCount:        c.WordCount,
 		SentenceCount:    c.SentenceCount,
 		VectorEmbedding:  pq.Float32Array(c.VectorEmbedding),
+		AnalysisReport:   AnalysisReportModelFromDomain(c.AnalysisReport),
+		ModelVersions:    modelVersionsFromDomain(c.ModelVersions),
+		Feed:             FeedMetadataModelFromDomain(c.Feed),
 		CreatedAt:        c.CreatedAt,
 		UpdatedAt:        c.UpdatedAt,
 	}
 }
 
+func modelVersionsToDomain(versions map[string]string) map[content.AnalysisStep]string {
+	if versions == nil {
+		return nil
+	}
+	byStep := make(map[content.AnalysisStep]string, len(versions))
+	for step, version := range versions {
+		byStep[content.AnalysisStep(step)] = version
+	}
+	return byStep
+}
+
+func modelVersionsFromDomain(versions map[content.AnalysisStep]string) map[string]string {
+	if versions == nil {
+		return nil
+	}
+	byName := make(map[string]string, len(versions))
+	for step, version := range versions {
+		byName[string(step)] = version
+	}
+	return byName
+}
+
+// AnalysisReportModel is the JSON representation of an AnalysisReport
+type AnalysisReportModel struct {
+	Steps      []StepReportModel `json:"steps"`
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt time.Time         `json:"finished_at"`
+}
+
+// StepReportModel is the JSON representation of a StepReport
+type StepReportModel struct {
+	Step         string `json:"step"`
+	ModelVersion string `json:"model_version,omitempty"`
+	DurationMS   int64  `json:"duration_ms"`
+	Succeeded    bool   `json:"succeeded"`
+	Error        string `json:"error,omitempty"`
+}
+
+// ToDomain converts AnalysisReportModel to domain AnalysisReport
+func (m *AnalysisReportModel) ToDomain() *content.AnalysisReport {
+	if m == nil {
+		return nil
+	}
+
+	steps := make([]content.StepReport, len(m.Steps))
+	for i, step := range m.Steps {
+		steps[i] = content.StepReport{
+			Step:         content.AnalysisStep(step.Step),
+			ModelVersion: step.ModelVersion,
+			Duration:     time.Duration(step.DurationMS) * time.Millisecond,
+			Succeeded:    step.Succeeded,
+			Error:        step.Error,
+		}
+	}
+
+	return &content.AnalysisReport{
+		Steps:      steps,
+		StartedAt:  m.StartedAt,
+		FinishedAt: m.FinishedAt,
+	}
+}
+
+// AnalysisReportModelFromDomain converts domain AnalysisReport to AnalysisReportModel
+func AnalysisReportModelFromDomain(r *content.AnalysisReport) *AnalysisReportModel {
+	if r == nil {
+		return nil
+	}
+
+	steps := make([]StepReportModel, len(r.Steps))
+	for i, step := range r.Steps {
+		steps[i] = StepReportModel{
+			Step:         string(step.Step),
+			ModelVersion: step.ModelVersion,
+			DurationMS:   step.Duration.Milliseconds(),
+			Succeeded:    step.Succeeded,
+			Error:        step.Error,
+		}
+	}
+
+	return &AnalysisReportModel{
+		Steps:      steps,
+		StartedAt:  r.StartedAt,
+		FinishedAt: r.FinishedAt,
+	}
+}
+
+// FeedMetadataModel is the JSON representation of FeedMetadata
+type FeedMetadataModel struct {
+	FeedURL    string    `json:"feed_url"`
+	Published  time.Time `json:"published,omitempty"`
+	Author     string    `json:"author,omitempty"`
+	Categories []string  `json:"categories,omitempty"`
+}
+
+// ToDomain converts FeedMetadataModel to domain FeedMetadata
+func (m *FeedMetadataModel) ToDomain() *content.FeedMetadata {
+	if m == nil {
+		return nil
+	}
+	return &content.FeedMetadata{
+		FeedURL:    m.FeedURL,
+		Published:  m.Published,
+		Author:     m.Author,
+		Categories: m.Categories,
+	}
+}
+
+// FeedMetadataModelFromDomain converts domain FeedMetadata to FeedMetadataModel
+func FeedMetadataModelFromDomain(f *content.FeedMetadata) *FeedMetadataModel {
+	if f == nil {
+		return nil
+	}
+	return &FeedMetadataModel{
+		FeedURL:    f.FeedURL,
+		Published:  f.Published,
+		Author:     f.Author,
+		Categories: f.Categories,
+	}
+}
+
 // NamedEntityModel is the database model for NamedEntity
 type NamedEntityModel struct {
 	ID         uuid.UUID      `gorm:"type:uuid;primary_key"`
@@ -158,6 +287,54 @@ func TopicModelFromDomain(t content.Topic, contentID uuid.UUID) *TopicModel {
 	}
 }
 
+// ChunkModel is the database model for Chunk
+type ChunkModel struct {
+	ID              uuid.UUID       `gorm:"type:uuid;primary_key"`
+	ContentID       uuid.UUID       `gorm:"type:uuid;index;not null"`
+	Index           int             `gorm:"column:chunk_index;not null"`
+	Heading         string
+	Text            string          `gorm:"type:text;not null"`
+	StartOffset     int             `gorm:"not null"`
+	EndOffset       int             `gorm:"not null"`
+	TokenCount      int             `gorm:"not null"`
+	VectorEmbedding pq.Float32Array `gorm:"type:vector(384)"` // Must match the vectorizer dimensions
+}
+
+// TableName returns the table name for the Chunk model
+func (ChunkModel) TableName() string {
+	return "content_chunks"
+}
+
+// ToDomain converts ChunkModel to domain Chunk
+func (m *ChunkModel) ToDomain() content.Chunk {
+	return content.Chunk{
+		ID:              m.ID,
+		ContentID:       m.ContentID,
+		Index:           m.Index,
+		Heading:         m.Heading,
+		Text:            m.Text,
+		StartOffset:     m.StartOffset,
+		EndOffset:       m.EndOffset,
+		TokenCount:      m.TokenCount,
+		VectorEmbedding: []float32(m.VectorEmbedding),
+	}
+}
+
+// FromDomain converts domain Chunk to ChunkModel
+func ChunkModelFromDomain(c content.Chunk, contentID uuid.UUID) *ChunkModel {
+	return &ChunkModel{
+		ID:              c.ID,
+		ContentID:       contentID,
+		Index:           c.Index,
+		Heading:         c.Heading,
+		Text:            c.Text,
+		StartOffset:     c.StartOffset,
+		EndOffset:       c.EndOffset,
+		TokenCount:      c.TokenCount,
+		VectorEmbedding: pq.Float32Array(c.VectorEmbedding),
+	}
+}
+
 // SimilarContentModel is the database model for SimilarContent
 type SimilarContentModel struct {
 	ContentID       uuid.UUID `gorm:"type:uuid;index;not null"`
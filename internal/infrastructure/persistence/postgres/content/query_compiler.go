@@ -0,0 +1,132 @@
+package content
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gerthdala/webcrawler/internal/domain/search/query"
+	"github.com/gerthdala/webcrawler/internal/infrastructure/persistence/postgres/textsearch"
+)
+
+// contentHost extracts the host of a content URL
+const contentHost = "lower(substring(url from '^[a-zA-Z][a-zA-Z0-9+.-]*://([^/:?#]+)'))"
+
+// compileQuery compiles a parsed search query into a SQL condition on the contents table.
+// Free text is matched with the text search configuration of language, or of each row's language when empty.
+func compileQuery(n query.Node, language string) (string, []interface{}) {
+	c := &queryCompiler{language: language}
+	c.compile(n)
+	return c.sql.String(), c.args
+}
+
+type queryCompiler struct {
+	sql      strings.Builder
+	args     []interface{}
+	language string
+}
+
+func (c *queryCompiler) compile(n query.Node) {
+	switch n := n.(type) {
+	case query.And:
+		c.join(n.Children, " AND ")
+	case query.Or:
+		c.join(n.Children, " OR ")
+	case query.Not:
+		c.sql.WriteString("NOT (")
+		c.compile(n.Child)
+		c.sql.WriteString(")")
+	case query.Term:
+		condition, args := textsearch.Match("language", tsqueryFunc(n.Phrase), c.language, n.Text)
+		c.write(condition, args...)
+	case query.FieldTerm:
+		c.field(n)
+	default:
+		c.sql.WriteString("FALSE")
+	}
+}
+
+func (c *queryCompiler) join(nodes []query.Node, sep string) {
+	c.sql.WriteString("(")
+	for i, child := range nodes {
+		if i > 0 {
+			c.sql.WriteString(sep)
+		}
+		c.compile(child)
+	}
+	c.sql.WriteString(")")
+}
+
+// columnMatch matches a single text column, which has no stored search vector
+func (c *queryCompiler) columnMatch(column, text string, phrase bool) {
+	config := textsearch.ConfigExpr("language")
+	c.write(fmt.Sprintf("to_tsvector(%s, coalesce(%s, '')) @@ %s(%s, ?)", config, column, tsqueryFunc(phrase), config), text)
+}
+
+func tsqueryFunc(phrase bool) string {
+	if phrase {
+		return "phraseto_tsquery"
+	}
+	return "plainto_tsquery"
+}
+
+func (c *queryCompiler) field(t query.FieldTerm) {
+	switch t.Field {
+	case query.FieldTitle:
+		c.columnMatch("title", t.Value, t.Phrase)
+	case query.FieldText:
+		c.columnMatch("text", t.Value, t.Phrase)
+	case query.FieldDomain:
+		c.write("("+contentHost+" = lower(?) OR "+contentHost+` LIKE ('%.' || lower(?)) ESCAPE '\')`, t.Value, escapeLike(t.Value))
+	case query.FieldLanguage:
+		c.write("lower(language) = lower(?)", t.Value)
+	case query.FieldType:
+		c.write("lower(classification) = lower(?)", t.Value)
+	case query.FieldEntity:
+		if t.Qualifier != "" {
+			c.write("EXISTS (SELECT 1 FROM named_entities ne WHERE ne.content_id = contents.id AND ne.type = ? AND lower(ne.text) = lower(?))",
+				t.Qualifier, t.Value)
+		} else {
+			c.write("EXISTS (SELECT 1 FROM named_entities ne WHERE ne.content_id = contents.id AND lower(ne.text) = lower(?))", t.Value)
+		}
+	case query.FieldTopic:
+		c.write(`EXISTS (SELECT 1 FROM topics tp WHERE tp.content_id = contents.id AND tp.name ILIKE ? ESCAPE '\')`, "%"+escapeLike(t.Value)+"%")
+	case query.FieldKeyword:
+		c.write("EXISTS (SELECT 1 FROM unnest(keywords) kw WHERE lower(kw) = lower(?))", t.Value)
+	case query.FieldURL:
+		c.write(`url LIKE ? ESCAPE '\'`, escapeLike(t.Value)+"%")
+	case query.FieldAfter:
+		c.write("created_at >= ?", t.Time)
+	case query.FieldBefore:
+		c.write("created_at < ?", t.Time)
+	default:
+		c.sql.WriteString("FALSE")
+	}
+}
+
+func (c *queryCompiler) write(sql string, args ...interface{}) {
+	c.sql.WriteString(sql)
+	c.args = append(c.args, args...)
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern, matched with ESCAPE '\'
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// rankingText returns the free text terms of a query OR'd together in websearch_to_tsquery syntax,
+// phrases quoted, so that Content is ranked by any of the terms it contains
+func rankingText(n query.Node) string {
+	terms := query.FreeText(n)
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		text := strings.TrimSpace(strings.ReplaceAll(term.Text, `"`, " "))
+		if text == "" {
+			continue
+		}
+		if term.Phrase {
+			text = `"` + text + `"`
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, " or ")
+}
@@ -0,0 +1,116 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FeedRepository implements crawler.FeedRepository using PostgreSQL
+type FeedRepository struct {
+	db *gorm.DB
+}
+
+// NewFeedRepository creates a new FeedRepository
+func NewFeedRepository(db *gorm.DB) *FeedRepository {
+	return &FeedRepository{
+		db: db,
+	}
+}
+
+// Save stores a Feed
+func (r *FeedRepository) Save(ctx context.Context, feed *crawler.Feed) result.Result[*crawler.Feed] {
+	tx := r.db.WithContext(ctx)
+	model := FeedModelFromDomain(feed)
+
+	if err := tx.Create(model).Error; err != nil {
+		return result.Err[*crawler.Feed](fmt.Errorf("failed to save Feed: %w", err))
+	}
+
+	return result.Ok(feed)
+}
+
+// Update updates a stored Feed
+func (r *FeedRepository) Update(ctx context.Context, feed *crawler.Feed) result.Result[*crawler.Feed] {
+	tx := r.db.WithContext(ctx)
+	model := FeedModelFromDomain(feed)
+
+	if err := tx.Save(model).Error; err != nil {
+		return result.Err[*crawler.Feed](fmt.Errorf("failed to update Feed: %w", err))
+	}
+
+	return result.Ok(feed)
+}
+
+// FindByURL finds a Feed by its URL
+func (r *FeedRepository) FindByURL(ctx context.Context, url string) result.Result[*crawler.Feed] {
+	tx := r.db.WithContext(ctx)
+	var model FeedModel
+
+	if err := tx.Where("url = ?", url).First(&model).Error; err != nil {
+		return result.Err[*crawler.Feed](fmt.Errorf("failed to find Feed by URL: %w", err))
+	}
+
+	return result.Ok(model.ToDomain())
+}
+
+// FindDue finds Feeds due to be polled at the given time, most overdue first
+func (r *FeedRepository) FindDue(ctx context.Context, now time.Time, limit int) result.Result[[]crawler.Feed] {
+	tx := r.db.WithContext(ctx)
+	var models []FeedModel
+
+	if err := tx.Where("next_poll_at <= ?", now).
+		Order("next_poll_at ASC").
+		Limit(limit).
+		Find(&models).Error; err != nil {
+		return result.Err[[]crawler.Feed](fmt.Errorf("failed to find due Feeds: %w", err))
+	}
+
+	feeds := make([]crawler.Feed, len(models))
+	for i, model := range models {
+		feeds[i] = *model.ToDomain()
+	}
+
+	return result.Ok(feeds)
+}
+
+// SaveItems stores the items of a Feed and returns the items that were not stored before
+func (r *FeedRepository) SaveItems(ctx context.Context, feedID uuid.UUID, items []crawler.FeedItem) result.Result[[]crawler.FeedItem] {
+	newItems := make([]crawler.FeedItem, 0)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, item := range items {
+			model := &FeedItemModel{
+				FeedID:     feedID,
+				GUID:       item.GUID,
+				Link:       item.Link,
+				Title:      item.Title,
+				Published:  item.Published,
+				Author:     item.Author,
+				Categories: item.Categories,
+				CreatedAt:  time.Now(),
+			}
+
+			// Items seen in a previous poll conflict on the feed and GUID and are skipped
+			insert := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(model)
+			if insert.Error != nil {
+				return insert.Error
+			}
+			if insert.RowsAffected > 0 {
+				newItems = append(newItems, item)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return result.Err[[]crawler.FeedItem](fmt.Errorf("failed to save Feed items: %w", err))
+	}
+
+	return result.Ok(newItems)
+}
@@ -0,0 +1,85 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FetchErrorRepository implements crawler.FetchErrorRepository using PostgreSQL
+type FetchErrorRepository struct {
+	db *gorm.DB
+}
+
+// NewFetchErrorRepository creates a new FetchErrorRepository
+func NewFetchErrorRepository(db *gorm.DB) *FetchErrorRepository {
+	return &FetchErrorRepository{
+		db: db,
+	}
+}
+
+// Record counts a fetch error of a host, incrementing the count of its kind in place
+func (r *FetchErrorRepository) Record(ctx context.Context, host string, fetchErr *crawler.FetchError, at time.Time) result.Result[bool] {
+	tx := r.db.WithContext(ctx)
+	model := &FetchErrorModel{
+		Host:      host,
+		Kind:      string(fetchErr.Kind),
+		Count:     1,
+		LastError: fetchErr.Err.Error(),
+		LastAt:    at,
+	}
+
+	if err := tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "host"}, {Name: "kind"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"count":      gorm.Expr(model.TableName() + ".count + 1"),
+			"last_error": model.LastError,
+			"last_at":    at,
+		}),
+	}).Create(model).Error; err != nil {
+		return result.Err[bool](fmt.Errorf("failed to record fetch error: %w", err))
+	}
+
+	return result.Ok(true)
+}
+
+// FindByHost finds the fetch error counts of a host, most errors first
+func (r *FetchErrorRepository) FindByHost(ctx context.Context, host string) result.Result[[]crawler.HostFetchErrors] {
+	tx := r.db.WithContext(ctx)
+	var models []FetchErrorModel
+
+	if err := tx.Where("host = ?", host).
+		Order("count DESC, kind ASC").
+		Find(&models).Error; err != nil {
+		return result.Err[[]crawler.HostFetchErrors](fmt.Errorf("failed to find fetch errors by host: %w", err))
+	}
+
+	return result.Ok(fetchErrorsToDomain(models))
+}
+
+// FindTop finds the fetch error counts with the most errors
+func (r *FetchErrorRepository) FindTop(ctx context.Context, limit int) result.Result[[]crawler.HostFetchErrors] {
+	tx := r.db.WithContext(ctx)
+	var models []FetchErrorModel
+
+	if err := tx.Order("count DESC, host ASC, kind ASC").
+		Limit(limit).
+		Find(&models).Error; err != nil {
+		return result.Err[[]crawler.HostFetchErrors](fmt.Errorf("failed to find top fetch errors: %w", err))
+	}
+
+	return result.Ok(fetchErrorsToDomain(models))
+}
+
+func fetchErrorsToDomain(models []FetchErrorModel) []crawler.HostFetchErrors {
+	counts := make([]crawler.HostFetchErrors, len(models))
+	for i, model := range models {
+		counts[i] = *model.ToDomain()
+	}
+	return counts
+}
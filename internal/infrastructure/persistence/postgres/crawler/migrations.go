@@ -0,0 +1,31 @@
+package crawler
+
+import (
+	"fmt"
+
+	"github.com/gerthdala/webcrawler/internal/infrastructure/persistence/postgres/textsearch"
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+	"gorm.io/gorm"
+)
+
+// Migrate creates or updates the crawler tables and their search vectors
+func Migrate(db *gorm.DB) result.Result[bool] {
+	if err := db.AutoMigrate(&URLModel{}, &PageModel{}, &PageVersionModel{}, &CrawlJobModel{}, &FeedModel{}, &FeedItemModel{}, &FetchErrorModel{}); err != nil {
+		return result.Err[bool](fmt.Errorf("failed to migrate crawler tables: %w", err))
+	}
+
+	return MigrateSearchVectors(db)
+}
+
+// MigrateSearchVectors adds the language-aware generated search vector and its GIN index to the pages table
+func MigrateSearchVectors(db *gorm.DB) result.Result[bool] {
+	err := textsearch.AddVectorColumn(db, PageModel{}.TableName(), "language",
+		textsearch.WeightedColumn{Name: "title", Weight: "A"},
+		textsearch.WeightedColumn{Name: "plain_text", Weight: "B"},
+	)
+	if err != nil {
+		return result.Err[bool](err)
+	}
+
+	return result.Ok(true)
+}
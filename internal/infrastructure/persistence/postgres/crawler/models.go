@@ -52,16 +52,31 @@ func NewDB(config DBConfig) result.Result[*gorm.DB] {
 
 // URLModel is the database model for URL
 type URLModel struct {
-	ID            uuid.UUID `gorm:"type:uuid;primary_key"`
-	URL           string    `gorm:"index;not null"`
-	NormalizedURL string    `gorm:"uniqueIndex;not null"`
-	Depth         int       `gorm:"not null"`
-	Status        string    `gorm:"index;not null"`
-	ParentURL     string    `gorm:"index"`
-	AttemptCount  int       `gorm:"not null;default:0"`
-	LastAttempt   time.Time
-	CreatedAt     time.Time `gorm:"index;not null"`
-	UpdatedAt     time.Time `gorm:"not null"`
+	ID              uuid.UUID `gorm:"type:uuid;primary_key"`
+	URL             string    `gorm:"index;not null"`
+	NormalizedURL   string    `gorm:"uniqueIndex;not null"`
+	Depth           int       `gorm:"not null"`
+	Status          string    `gorm:"index;not null"`
+	ParentURL       string    `gorm:"index"`
+	OffDomainHops   int       `gorm:"not null;default:0"`
+	StatusReason    string    `gorm:"index"`
+	StatusDetail    string
+	AttemptCount    int `gorm:"not null;default:0"`
+	LastAttempt     time.Time
+	LastModified    time.Time
+	ChangeFrequency string
+	Priority        float64
+	NextCrawlAt     time.Time `gorm:"index"`
+	ContentHash     string
+	LastFetchedAt   time.Time
+	LastChangedAt   time.Time
+	Revisits        int           `gorm:"not null;default:0"`
+	Changes         int           `gorm:"not null;default:0"`
+	RevisitSpanMS   int64         `gorm:"not null;default:0"`
+	Failures        int           `gorm:"not null;default:0"`
+	FeedItem        *FeedItemJSON `gorm:"type:jsonb;serializer:json"`
+	CreatedAt       time.Time     `gorm:"index;not null"`
+	UpdatedAt       time.Time     `gorm:"not null"`
 }
 
 // TableName returns the table name for the URL model
@@ -72,48 +87,87 @@ func (URLModel) TableName() string {
 // ToDomain converts URLModel to domain URL
 func (m *URLModel) ToDomain() *crawler.URL {
 	return &crawler.URL{
-		ID:            m.ID,
-		URL:           m.URL,
-		NormalizedURL: m.NormalizedURL,
-		Depth:         m.Depth,
-		Status:        crawler.Status(m.Status),
-		ParentURL:     m.ParentURL,
-		AttemptCount:  m.AttemptCount,
-		LastAttempt:   m.LastAttempt,
-		CreatedAt:     m.CreatedAt,
-		UpdatedAt:     m.UpdatedAt,
+		ID:              m.ID,
+		URL:             m.URL,
+		NormalizedURL:   m.NormalizedURL,
+		Depth:           m.Depth,
+		Status:          crawler.Status(m.Status),
+		ParentURL:       m.ParentURL,
+		OffDomainHops:   m.OffDomainHops,
+		StatusReason:    m.StatusReason,
+		StatusDetail:    m.StatusDetail,
+		AttemptCount:    m.AttemptCount,
+		LastAttempt:     m.LastAttempt,
+		LastModified:    m.LastModified,
+		ChangeFrequency: crawler.ChangeFrequency(m.ChangeFrequency),
+		Priority:        m.Priority,
+		NextCrawlAt:     m.NextCrawlAt,
+		ContentHash:     m.ContentHash,
+		LastFetchedAt:   m.LastFetchedAt,
+		LastChangedAt:   m.LastChangedAt,
+		Revisits:        m.Revisits,
+		Changes:         m.Changes,
+		RevisitSpan:     time.Duration(m.RevisitSpanMS) * time.Millisecond,
+		Failures:        m.Failures,
+		FeedItem:        m.FeedItem.ToDomain(),
+		CreatedAt:       m.CreatedAt,
+		UpdatedAt:       m.UpdatedAt,
 	}
 }
 
 // FromDomain converts domain URL to URLModel
 func URLModelFromDomain(url *crawler.URL) *URLModel {
 	return &URLModel{
-		ID:            url.ID,
-		URL:           url.URL,
-		NormalizedURL: url.NormalizedURL,
-		Depth:         url.Depth,
-		Status:        string(url.Status),
-		ParentURL:     url.ParentURL,
-		AttemptCount:  url.AttemptCount,
-		LastAttempt:   url.LastAttempt,
-		CreatedAt:     url.CreatedAt,
-		UpdatedAt:     url.UpdatedAt,
+		ID:              url.ID,
+		URL:             url.URL,
+		NormalizedURL:   url.NormalizedURL,
+		Depth:           url.Depth,
+		Status:          string(url.Status),
+		ParentURL:       url.ParentURL,
+		OffDomainHops:   url.OffDomainHops,
+		StatusReason:    url.StatusReason,
+		StatusDetail:    url.StatusDetail,
+		AttemptCount:    url.AttemptCount,
+		LastAttempt:     url.LastAttempt,
+		LastModified:    url.LastModified,
+		ChangeFrequency: string(url.ChangeFrequency),
+		Priority:        url.Priority,
+		NextCrawlAt:     url.NextCrawlAt,
+		ContentHash:     url.ContentHash,
+		LastFetchedAt:   url.LastFetchedAt,
+		LastChangedAt:   url.LastChangedAt,
+		Revisits:        url.Revisits,
+		Changes:         url.Changes,
+		RevisitSpanMS:   url.RevisitSpan.Milliseconds(),
+		Failures:        url.Failures,
+		FeedItem:        FeedItemJSONFromDomain(url.FeedItem),
+		CreatedAt:       url.CreatedAt,
+		UpdatedAt:       url.UpdatedAt,
 	}
 }
 
 // PageModel is the database model for Page
 type PageModel struct {
-	ID          uuid.UUID `gorm:"type:uuid;primary_key"`
-	URL         string    `gorm:"uniqueIndex;not null"`
-	StatusCode  int       `gorm:"not null"`
-	Title       string
-	HTML        string            `gorm:"type:text"`
-	PlainText   string            `gorm:"type:text"`
-	Headers     map[string]string `gorm:"type:jsonb"`
-	Links       []string          `gorm:"type:jsonb"`
-	ContentType string
-	FetchedAt   time.Time `gorm:"index;not null"`
-	ParsedAt    time.Time
+	ID           uuid.UUID `gorm:"type:uuid;primary_key"`
+	URL          string    `gorm:"uniqueIndex;not null"`
+	StatusCode   int       `gorm:"not null"`
+	Title        string
+	HTML         string            `gorm:"type:text"`
+	PlainText    string            `gorm:"type:text"`
+	Headers      map[string]string `gorm:"type:jsonb"`
+	Links        []string          `gorm:"type:jsonb"`
+	ContentType  string
+	Language     string         `gorm:"index"`
+	Feeds        []string       `gorm:"type:jsonb;serializer:json"`
+	FeedItem     *FeedItemJSON  `gorm:"type:jsonb;serializer:json"`
+	CanonicalURL string         `gorm:"index"`
+	DuplicateOf  string         `gorm:"index"`
+	RequestedURL string         `gorm:"index"`
+	Redirects    []RedirectJSON `gorm:"type:jsonb;serializer:json"`
+	Truncated    bool           `gorm:"not null;default:false"`
+	Charset      string         `gorm:"size:40"`
+	FetchedAt    time.Time      `gorm:"index;not null"`
+	ParsedAt     time.Time
 }
 
 // TableName returns the table name for the Page model
@@ -124,34 +178,103 @@ func (PageModel) TableName() string {
 // ToDomain converts PageModel to domain Page
 func (m *PageModel) ToDomain() *crawler.Page {
 	return &crawler.Page{
+		ID:           m.ID,
+		URL:          m.URL,
+		StatusCode:   m.StatusCode,
+		Title:        m.Title,
+		HTML:         m.HTML,
+		PlainText:    m.PlainText,
+		Headers:      m.Headers,
+		Links:        m.Links,
+		ContentType:  m.ContentType,
+		Language:     m.Language,
+		Feeds:        m.Feeds,
+		FeedItem:     m.FeedItem.ToDomain(),
+		CanonicalURL: m.CanonicalURL,
+		DuplicateOf:  m.DuplicateOf,
+		RequestedURL: m.RequestedURL,
+		Redirects:    redirectsToDomain(m.Redirects),
+		Truncated:    m.Truncated,
+		Charset:      m.Charset,
+		FetchedAt:    m.FetchedAt,
+		ParsedAt:     m.ParsedAt,
+	}
+}
+
+// FromDomain converts domain Page to PageModel
+func PageModelFromDomain(page *crawler.Page) *PageModel {
+	return &PageModel{
+		ID:           page.ID,
+		URL:          page.URL,
+		StatusCode:   page.StatusCode,
+		Title:        page.Title,
+		HTML:         page.HTML,
+		PlainText:    page.PlainText,
+		Headers:      page.Headers,
+		Links:        page.Links,
+		ContentType:  page.ContentType,
+		Language:     page.Language,
+		Feeds:        page.Feeds,
+		FeedItem:     FeedItemJSONFromDomain(page.FeedItem),
+		CanonicalURL: page.CanonicalURL,
+		DuplicateOf:  page.DuplicateOf,
+		RequestedURL: page.RequestedURL,
+		Redirects:    redirectsFromDomain(page.Redirects),
+		Truncated:    page.Truncated,
+		Charset:      page.Charset,
+		FetchedAt:    page.FetchedAt,
+		ParsedAt:     page.ParsedAt,
+	}
+}
+
+// PageVersionModel is the database model for PageVersion, unique by URL and version
+type PageVersionModel struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key"`
+	PageID      uuid.UUID `gorm:"type:uuid;index;not null"`
+	URL         string    `gorm:"uniqueIndex:idx_page_versions_url_version;not null"`
+	Version     int       `gorm:"uniqueIndex:idx_page_versions_url_version;not null"`
+	ContentHash string    `gorm:"not null"`
+	StatusCode  int       `gorm:"not null"`
+	Title       string
+	HTML        string    `gorm:"type:text"`
+	PlainText   string    `gorm:"type:text"`
+	FetchedAt   time.Time `gorm:"index;not null"`
+}
+
+// TableName returns the table name for the PageVersion model
+func (PageVersionModel) TableName() string {
+	return "page_versions"
+}
+
+// ToDomain converts PageVersionModel to domain PageVersion
+func (m *PageVersionModel) ToDomain() *crawler.PageVersion {
+	return &crawler.PageVersion{
 		ID:          m.ID,
+		PageID:      m.PageID,
 		URL:         m.URL,
+		Version:     m.Version,
+		ContentHash: m.ContentHash,
 		StatusCode:  m.StatusCode,
 		Title:       m.Title,
 		HTML:        m.HTML,
 		PlainText:   m.PlainText,
-		Headers:     m.Headers,
-		Links:       m.Links,
-		ContentType: m.ContentType,
 		FetchedAt:   m.FetchedAt,
-		ParsedAt:    m.ParsedAt,
 	}
 }
 
-// FromDomain converts domain Page to PageModel
-func PageModelFromDomain(page *crawler.Page) *PageModel {
-	return &PageModel{
-		ID:          page.ID,
-		URL:         page.URL,
-		StatusCode:  page.StatusCode,
-		Title:       page.Title,
-		HTML:        page.HTML,
-		PlainText:   page.PlainText,
-		Headers:     page.Headers,
-		Links:       page.Links,
-		ContentType: page.ContentType,
-		FetchedAt:   page.FetchedAt,
-		ParsedAt:    page.ParsedAt,
+// PageVersionModelFromDomain converts domain PageVersion to PageVersionModel
+func PageVersionModelFromDomain(version *crawler.PageVersion) *PageVersionModel {
+	return &PageVersionModel{
+		ID:          version.ID,
+		PageID:      version.PageID,
+		URL:         version.URL,
+		Version:     version.Version,
+		ContentHash: version.ContentHash,
+		StatusCode:  version.StatusCode,
+		Title:       version.Title,
+		HTML:        version.HTML,
+		PlainText:   version.PlainText,
+		FetchedAt:   version.FetchedAt,
 	}
 }
 
@@ -198,3 +321,172 @@ func CrawlJobModelFromDomain(job *crawler.CrawlJob) *CrawlJobModel {
 		CreatedAt: job.CreatedAt,
 	}
 }
+
+// FeedModel is the database model for Feed
+type FeedModel struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key"`
+	URL            string    `gorm:"uniqueIndex;not null"`
+	Title          string
+	SiteURL        string
+	ETag           string
+	LastModified   string
+	PollIntervalMS int64
+	LastPolledAt   time.Time
+	NextPollAt     time.Time `gorm:"index;not null"`
+	CreatedAt      time.Time `gorm:"not null"`
+	UpdatedAt      time.Time `gorm:"not null"`
+}
+
+// TableName returns the table name for the Feed model
+func (FeedModel) TableName() string {
+	return "feeds"
+}
+
+// ToDomain converts FeedModel to domain Feed
+func (m *FeedModel) ToDomain() *crawler.Feed {
+	return &crawler.Feed{
+		ID:           m.ID,
+		URL:          m.URL,
+		Title:        m.Title,
+		SiteURL:      m.SiteURL,
+		ETag:         m.ETag,
+		LastModified: m.LastModified,
+		PollInterval: time.Duration(m.PollIntervalMS) * time.Millisecond,
+		LastPolledAt: m.LastPolledAt,
+		NextPollAt:   m.NextPollAt,
+		CreatedAt:    m.CreatedAt,
+		UpdatedAt:    m.UpdatedAt,
+	}
+}
+
+// FeedModelFromDomain converts domain Feed to FeedModel
+func FeedModelFromDomain(feed *crawler.Feed) *FeedModel {
+	return &FeedModel{
+		ID:             feed.ID,
+		URL:            feed.URL,
+		Title:          feed.Title,
+		SiteURL:        feed.SiteURL,
+		ETag:           feed.ETag,
+		LastModified:   feed.LastModified,
+		PollIntervalMS: feed.PollInterval.Milliseconds(),
+		LastPolledAt:   feed.LastPolledAt,
+		NextPollAt:     feed.NextPollAt,
+		CreatedAt:      feed.CreatedAt,
+		UpdatedAt:      feed.UpdatedAt,
+	}
+}
+
+// FeedItemModel is the database model for FeedItem, unique by feed and GUID
+type FeedItemModel struct {
+	ID         int64     `gorm:"primaryKey;autoIncrement"`
+	FeedID     uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_feed_items_feed_guid;not null"`
+	GUID       string    `gorm:"uniqueIndex:idx_feed_items_feed_guid;not null"`
+	Link       string    `gorm:"index"`
+	Title      string
+	Published  time.Time
+	Author     string
+	Categories []string  `gorm:"type:jsonb;serializer:json"`
+	CreatedAt  time.Time `gorm:"not null"`
+}
+
+// TableName returns the table name for the FeedItem model
+func (FeedItemModel) TableName() string {
+	return "feed_items"
+}
+
+// RedirectJSON is the JSON representation of a Redirect followed to fetch a Page
+type RedirectJSON struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+	Location   string `json:"location"`
+}
+
+func redirectsToDomain(redirects []RedirectJSON) []crawler.Redirect {
+	if len(redirects) == 0 {
+		return nil
+	}
+	chain := make([]crawler.Redirect, len(redirects))
+	for i, r := range redirects {
+		chain[i] = crawler.Redirect{URL: r.URL, StatusCode: r.StatusCode, Location: r.Location}
+	}
+	return chain
+}
+
+func redirectsFromDomain(redirects []crawler.Redirect) []RedirectJSON {
+	if len(redirects) == 0 {
+		return nil
+	}
+	chain := make([]RedirectJSON, len(redirects))
+	for i, r := range redirects {
+		chain[i] = RedirectJSON{URL: r.URL, StatusCode: r.StatusCode, Location: r.Location}
+	}
+	return chain
+}
+
+// FeedItemJSON is the JSON representation of the FeedItem a URL or Page was discovered from
+type FeedItemJSON struct {
+	GUID       string    `json:"guid"`
+	FeedURL    string    `json:"feed_url"`
+	Link       string    `json:"link"`
+	Title      string    `json:"title,omitempty"`
+	Published  time.Time `json:"published,omitempty"`
+	Author     string    `json:"author,omitempty"`
+	Categories []string  `json:"categories,omitempty"`
+}
+
+// ToDomain converts FeedItemJSON to domain FeedItem
+func (m *FeedItemJSON) ToDomain() *crawler.FeedItem {
+	if m == nil {
+		return nil
+	}
+	return &crawler.FeedItem{
+		GUID:       m.GUID,
+		FeedURL:    m.FeedURL,
+		Link:       m.Link,
+		Title:      m.Title,
+		Published:  m.Published,
+		Author:     m.Author,
+		Categories: m.Categories,
+	}
+}
+
+// FeedItemJSONFromDomain converts domain FeedItem to FeedItemJSON
+func FeedItemJSONFromDomain(item *crawler.FeedItem) *FeedItemJSON {
+	if item == nil {
+		return nil
+	}
+	return &FeedItemJSON{
+		GUID:       item.GUID,
+		FeedURL:    item.FeedURL,
+		Link:       item.Link,
+		Title:      item.Title,
+		Published:  item.Published,
+		Author:     item.Author,
+		Categories: item.Categories,
+	}
+}
+
+// FetchErrorModel is the database model counting the fetch errors of a host by kind
+type FetchErrorModel struct {
+	Host      string `gorm:"primaryKey"`
+	Kind      string `gorm:"primaryKey"`
+	Count     int    `gorm:"not null;default:0"`
+	LastError string
+	LastAt    time.Time `gorm:"not null"`
+}
+
+// TableName returns the table name for the FetchError model
+func (FetchErrorModel) TableName() string {
+	return "host_fetch_errors"
+}
+
+// ToDomain converts FetchErrorModel to domain HostFetchErrors
+func (m *FetchErrorModel) ToDomain() *crawler.HostFetchErrors {
+	return &crawler.HostFetchErrors{
+		Host:      m.Host,
+		Kind:      crawler.FetchErrorKind(m.Kind),
+		Count:     m.Count,
+		LastError: m.LastError,
+		LastAt:    m.LastAt,
+	}
+}
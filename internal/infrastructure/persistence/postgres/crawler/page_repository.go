@@ -6,9 +6,11 @@ import (
 	"time"
 
 	"github.com/gerthdala/webcrawler/internal/domain/crawler"
+	"github.com/gerthdala/webcrawler/internal/infrastructure/persistence/postgres/textsearch"
 	result "github.com/gerthdala/webcrawler/pkg/utils/result"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // PageRepository implements crawler.PageRepository using PostgreSQL
@@ -23,18 +25,46 @@ func NewPageRepository(db *gorm.DB) *PageRepository {
 	}
 }
 
-// Save stores a Page
+// Save stores a Page, updating the stored Page with the same URL in place on recrawl
 func (r *PageRepository) Save(ctx context.Context, page *crawler.Page) result.Result[*crawler.Page] {
 	tx := r.db.WithContext(ctx)
 	model := PageModelFromDomain(page)
 
-	if err := tx.Create(model).Error; err != nil {
+	if err := tx.Clauses(
+		clause.OnConflict{
+			Columns:   []clause.Column{{Name: "url"}},
+			DoUpdates: clause.AssignmentColumns(pageUpdateColumns),
+		},
+		clause.Returning{Columns: []clause.Column{{Name: "id"}}},
+	).Create(model).Error; err != nil {
 		return result.Err[*crawler.Page](fmt.Errorf("failed to save Page: %w", err))
 	}
 
+	// A recrawled Page keeps the ID of the stored Page
+	page.ID = model.ID
+
 	return result.Ok(page)
 }
 
+// pageUpdateColumns are the columns overwritten when a recrawled Page is saved
+var pageUpdateColumns = []string{
+	"status_code", "title", "html", "plain_text", "headers", "links", "content_type",
+	"language", "feeds", "feed_item", "canonical_url", "duplicate_of", "requested_url", "redirects", "truncated", "charset", "fetched_at", "parsed_at",
+}
+
+// MarkNotModified records that a stored Page was fetched again unchanged, without rewriting its body
+func (r *PageRepository) MarkNotModified(ctx context.Context, id uuid.UUID, fetchedAt time.Time) result.Result[*crawler.Page] {
+	tx := r.db.WithContext(ctx)
+
+	if err := tx.Model(&PageModel{}).
+		Where("id = ?", id).
+		Update("fetched_at", fetchedAt).Error; err != nil {
+		return result.Err[*crawler.Page](fmt.Errorf("failed to mark Page not modified: %w", err))
+	}
+
+	return r.FindByID(ctx, id)
+}
+
 // FindByID finds a Page by its ID
 func (r *PageRepository) FindByID(ctx context.Context, id uuid.UUID) result.Result[*crawler.Page] {
 	tx := r.db.WithContext(ctx)
@@ -47,6 +77,27 @@ func (r *PageRepository) FindByID(ctx context.Context, id uuid.UUID) result.Resu
 	return result.Ok(model.ToDomain())
 }
 
+// FindByIDs finds the stored Pages among the given IDs
+func (r *PageRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) result.Result[[]crawler.Page] {
+	if len(ids) == 0 {
+		return result.Ok([]crawler.Page{})
+	}
+
+	tx := r.db.WithContext(ctx)
+	var models []PageModel
+
+	if err := tx.Where("id IN ?", ids).Find(&models).Error; err != nil {
+		return result.Err[[]crawler.Page](fmt.Errorf("failed to find Pages by IDs: %w", err))
+	}
+
+	pages := make([]crawler.Page, len(models))
+	for i, model := range models {
+		pages[i] = *model.ToDomain()
+	}
+
+	return result.Ok(pages)
+}
+
 // FindByURL finds a Page by its URL
 func (r *PageRepository) FindByURL(ctx context.Context, url string) result.Result[*crawler.Page] {
 	tx := r.db.WithContext(ctx)
@@ -95,8 +146,9 @@ func (r *PageRepository) Search(ctx context.Context, query string, limit int) re
 	tx := r.db.WithContext(ctx)
 	var models []PageModel
 
-	// Use PostgreSQL's full-text search
-	if err := tx.Where("to_tsvector('english', title || ' ' || plain_text) @@ plainto_tsquery('english', ?)", query).
+	// Use PostgreSQL's full-text search on the language-aware search vector
+	condition, args := textsearch.Match("language", "plainto_tsquery", "", query)
+	if err := tx.Where(condition, args...).
 		Order("fetched_at DESC").
 		Limit(limit).
 		Find(&models).Error; err != nil {
@@ -111,6 +163,19 @@ func (r *PageRepository) Search(ctx context.Context, query string, limit int) re
 	return result.Ok(pages)
 }
 
+// FindIDsOlderThan finds the IDs of the pages fetched before the given number of days
+func (r *PageRepository) FindIDsOlderThan(ctx context.Context, days int) result.Result[[]uuid.UUID] {
+	tx := r.db.WithContext(ctx)
+	cutoff := time.Now().AddDate(0, 0, -days)
+
+	var ids []uuid.UUID
+	if err := tx.Model(&PageModel{}).Where("fetched_at < ?", cutoff).Pluck("id", &ids).Error; err != nil {
+		return result.Err[[]uuid.UUID](fmt.Errorf("failed to get old Page IDs: %w", err))
+	}
+
+	return result.Ok(ids)
+}
+
 // DeleteOlderThan deletes pages older than the given duration
 func (r *PageRepository) DeleteOlderThan(ctx context.Context, days int) result.Result[int] {
 	tx := r.db.WithContext(ctx)
@@ -0,0 +1,122 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+	"gorm.io/gorm"
+)
+
+// hostExpr extracts the host of the url column
+const hostExpr = "substring(url from '://([^/:]+)')"
+
+// PageVersionRepository implements crawler.PageVersionRepository using PostgreSQL
+type PageVersionRepository struct {
+	db *gorm.DB
+}
+
+// NewPageVersionRepository creates a new PageVersionRepository
+func NewPageVersionRepository(db *gorm.DB) *PageVersionRepository {
+	return &PageVersionRepository{
+		db: db,
+	}
+}
+
+// Save stores a PageVersion
+func (r *PageVersionRepository) Save(ctx context.Context, version *crawler.PageVersion) result.Result[*crawler.PageVersion] {
+	tx := r.db.WithContext(ctx)
+	model := PageVersionModelFromDomain(version)
+
+	if err := tx.Create(model).Error; err != nil {
+		return result.Err[*crawler.PageVersion](fmt.Errorf("failed to save PageVersion: %w", err))
+	}
+
+	return result.Ok(version)
+}
+
+// FindLatest finds the latest PageVersion of a URL
+func (r *PageVersionRepository) FindLatest(ctx context.Context, url string) result.Result[*crawler.PageVersion] {
+	tx := r.db.WithContext(ctx)
+	var model PageVersionModel
+
+	if err := tx.Where("url = ?", url).Order("version DESC").First(&model).Error; err != nil {
+		return result.Err[*crawler.PageVersion](fmt.Errorf("failed to find latest PageVersion: %w", err))
+	}
+
+	return result.Ok(model.ToDomain())
+}
+
+// FindByURL finds the PageVersions of a URL, latest first
+func (r *PageVersionRepository) FindByURL(ctx context.Context, url string, limit int) result.Result[[]crawler.PageVersion] {
+	tx := r.db.WithContext(ctx)
+	var models []PageVersionModel
+
+	if err := tx.Where("url = ?", url).
+		Order("version DESC").
+		Limit(limit).
+		Find(&models).Error; err != nil {
+		return result.Err[[]crawler.PageVersion](fmt.Errorf("failed to find PageVersions by URL: %w", err))
+	}
+
+	versions := make([]crawler.PageVersion, len(models))
+	for i, model := range models {
+		versions[i] = *model.ToDomain()
+	}
+
+	return result.Ok(versions)
+}
+
+// FindVersion finds a PageVersion of a URL by its number
+func (r *PageVersionRepository) FindVersion(ctx context.Context, url string, version int) result.Result[*crawler.PageVersion] {
+	tx := r.db.WithContext(ctx)
+	var model PageVersionModel
+
+	if err := tx.Where("url = ? AND version = ?", url, version).First(&model).Error; err != nil {
+		return result.Err[*crawler.PageVersion](fmt.Errorf("failed to find PageVersion: %w", err))
+	}
+
+	return result.Ok(model.ToDomain())
+}
+
+// FindChangesSince summarizes the versions of the pages of a domain and its subdomains fetched since
+// the given time, most recently changed first. Every domain is included when domain is empty.
+func (r *PageVersionRepository) FindChangesSince(ctx context.Context, domain string, since time.Time, limit int) result.Result[[]crawler.PageChange] {
+	tx := r.db.WithContext(ctx)
+	var rows []struct {
+		URL           string
+		Created       bool
+		Versions      int
+		LatestVersion int
+		ChangedAt     time.Time
+	}
+
+	query := tx.Model(&PageVersionModel{}).
+		Select("url, MIN(version) = 1 AS created, COUNT(*) AS versions, MAX(version) AS latest_version, MAX(fetched_at) AS changed_at").
+		Where("fetched_at >= ?", since)
+	if domain != "" {
+		query = query.Where("("+hostExpr+" = ? OR "+hostExpr+" LIKE ?)", domain, "%."+domain)
+	}
+
+	if err := query.Group("url").
+		Order("changed_at DESC").
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return result.Err[[]crawler.PageChange](fmt.Errorf("failed to find page changes: %w", err))
+	}
+
+	changes := make([]crawler.PageChange, len(rows))
+	for i, row := range rows {
+		changes[i] = crawler.PageChange{
+			URL:           row.URL,
+			Created:       row.Created,
+			Versions:      row.Versions,
+			LatestVersion: row.LatestVersion,
+			ChangedAt:     row.ChangedAt,
+		}
+	}
+
+	return result.Ok(changes)
+}
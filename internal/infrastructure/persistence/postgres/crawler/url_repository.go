@@ -71,14 +71,23 @@ func (r *URLRepository) FindPending(ctx context.Context, limit int) result.Resul
 	return result.Ok(urls)
 }
 
-func (r *URLRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status crawler.Status) result.Result[*crawler.URL] {
+func (r *URLRepository) UpdateStatus(ctx context.Context, id uuid.UUID, from, status crawler.Status, reason, detail string) result.Result[*crawler.URL] {
 	tx := r.db.WithContext(ctx)
 
-	if err := tx.Model(&URLModel{}).
-		Where("id = ?", id).
-		Update("status", string(status)).
-		Error; err != nil {
-		return result.Err[*crawler.URL](fmt.Errorf("failed to update URL status: %w", err))
+	// The status is only set from the one the URL was read in, so that concurrent workers cannot both claim it
+	dbResult := tx.Model(&URLModel{}).
+		Where("id = ? AND status = ?", id, string(from)).
+		Updates(map[string]interface{}{
+			"status":        string(status),
+			"status_reason": reason,
+			"status_detail": detail,
+			"updated_at":    time.Now(),
+		})
+	if dbResult.Error != nil {
+		return result.Err[*crawler.URL](fmt.Errorf("failed to update URL status: %w", dbResult.Error))
+	}
+	if dbResult.RowsAffected == 0 {
+		return result.Err[*crawler.URL](fmt.Errorf("%w: URL %s is no longer %s", crawler.ErrInvalidTransition, id, from))
 	}
 
 	return r.FindByID(ctx, id)
@@ -132,6 +141,35 @@ func (r *URLRepository) CountByStatus(ctx context.Context, status crawler.Status
 	return result.Ok(int(count))
 }
 
+// CountByStatusAndReason counts URLs by status and reason code
+func (r *URLRepository) CountByStatusAndReason(ctx context.Context) result.Result[[]crawler.StatusCount] {
+	tx := r.db.WithContext(ctx)
+	var rows []struct {
+		Status       string
+		StatusReason string
+		Count        int
+	}
+
+	if err := tx.Model(&URLModel{}).
+		Select("status, status_reason, COUNT(*) AS count").
+		Group("status, status_reason").
+		Order("status ASC, count DESC, status_reason ASC").
+		Scan(&rows).Error; err != nil {
+		return result.Err[[]crawler.StatusCount](fmt.Errorf("failed to count URLs by status and reason: %w", err))
+	}
+
+	counts := make([]crawler.StatusCount, len(rows))
+	for i, row := range rows {
+		counts[i] = crawler.StatusCount{
+			Status: crawler.Status(row.Status),
+			Reason: row.StatusReason,
+			Count:  row.Count,
+		}
+	}
+
+	return result.Ok(counts)
+}
+
 // DeleteOlderThan deletes URLs older than the given duration
 func (r *URLRepository) DeleteOlderThan(ctx context.Context, days int) result.Result[int] {
 	tx := r.db.WithContext(ctx)
@@ -143,4 +181,72 @@ func (r *URLRepository) DeleteOlderThan(ctx context.Context, days int) result.Re
 	}
 
 	return result.Ok(int(dbResult.RowsAffected))
-}
\ No newline at end of file
+}
+
+// Update updates a stored URL
+func (r *URLRepository) Update(ctx context.Context, url *crawler.URL) result.Result[*crawler.URL] {
+	tx := r.db.WithContext(ctx)
+	model := URLModelFromDomain(url)
+
+	if err := tx.Save(model).Error; err != nil {
+		return result.Err[*crawler.URL](fmt.Errorf("failed to update URL: %w", err))
+	}
+
+	return result.Ok(url)
+}
+
+// FindDueForRecrawl finds crawled URLs due to be recrawled at the given time, most overdue first
+func (r *URLRepository) FindDueForRecrawl(ctx context.Context, now time.Time, limit int) result.Result[[]crawler.URL] {
+	tx := r.db.WithContext(ctx)
+	var models []URLModel
+
+	statuses := make([]string, 0, len(crawler.CrawledStatuses()))
+	for _, status := range crawler.CrawledStatuses() {
+		statuses = append(statuses, string(status))
+	}
+
+	if err := tx.Where("status IN ?", statuses).
+		Where("next_crawl_at > ? AND next_crawl_at <= ?", time.Time{}, now).
+		Order("next_crawl_at ASC").
+		Limit(limit).
+		Find(&models).Error; err != nil {
+		return result.Err[[]crawler.URL](fmt.Errorf("failed to find URLs due for recrawl: %w", err))
+	}
+
+	urls := make([]crawler.URL, len(models))
+	for i, model := range models {
+		urls[i] = *model.ToDomain()
+	}
+
+	return result.Ok(urls)
+}
+
+// CountRecrawlsByDomain counts the recrawls scheduled before the given time by domain, most recrawls first
+func (r *URLRepository) CountRecrawlsByDomain(ctx context.Context, before time.Time) result.Result[[]crawler.DomainRecrawls] {
+	tx := r.db.WithContext(ctx)
+	var rows []struct {
+		Domain string
+		Count  int
+		NextAt time.Time
+	}
+
+	if err := tx.Model(&URLModel{}).
+		Select(hostExpr+" AS domain, COUNT(*) AS count, MIN(next_crawl_at) AS next_at").
+		Where("next_crawl_at > ? AND next_crawl_at <= ?", time.Time{}, before).
+		Group("domain").
+		Order("count DESC, domain ASC").
+		Scan(&rows).Error; err != nil {
+		return result.Err[[]crawler.DomainRecrawls](fmt.Errorf("failed to count recrawls by domain: %w", err))
+	}
+
+	recrawls := make([]crawler.DomainRecrawls, len(rows))
+	for i, row := range rows {
+		recrawls[i] = crawler.DomainRecrawls{
+			Domain: row.Domain,
+			Count:  row.Count,
+			NextAt: row.NextAt,
+		}
+	}
+
+	return result.Ok(recrawls)
+}
@@ -0,0 +1,144 @@
+package textsearch
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// VectorColumn is the name of the generated tsvector column of searchable tables
+const VectorColumn = "search_vector"
+
+// DefaultConfig is the text search configuration of unknown languages, which are stemmed as English
+const DefaultConfig = "english"
+
+// configs maps ISO 639-1 codes and English language names to built-in PostgreSQL text search configurations
+var configs = map[string]string{
+	"ar": "arabic", "arabic": "arabic",
+	"da": "danish", "danish": "danish",
+	"de": "german", "german": "german",
+	"en": "english", "english": "english",
+	"es": "spanish", "spanish": "spanish",
+	"fi": "finnish", "finnish": "finnish",
+	"fr": "french", "french": "french",
+	"hu": "hungarian", "hungarian": "hungarian",
+	"id": "indonesian", "indonesian": "indonesian",
+	"it": "italian", "italian": "italian",
+	"nl": "dutch", "dutch": "dutch",
+	"no": "norwegian", "nb": "norwegian", "nn": "norwegian", "norwegian": "norwegian",
+	"pt": "portuguese", "portuguese": "portuguese",
+	"ro": "romanian", "romanian": "romanian",
+	"ru": "russian", "russian": "russian",
+	"sv": "swedish", "swedish": "swedish",
+	"tr": "turkish", "turkish": "turkish",
+}
+
+// ConfigFor returns the text search configuration of a language code such as "fr", "de-CH" or "english"
+func ConfigFor(language string) string {
+	if config, ok := configs[baseLanguage(language)]; ok {
+		return config
+	}
+	return DefaultConfig
+}
+
+// Configs returns every configuration a row can be indexed with
+func Configs() []string {
+	seen := map[string]bool{DefaultConfig: true}
+	names := []string{DefaultConfig}
+	for _, config := range configs {
+		if !seen[config] {
+			seen[config] = true
+			names = append(names, config)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func baseLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if idx := strings.IndexAny(language, "-_"); idx > 0 {
+		language = language[:idx]
+	}
+	return language
+}
+
+// ConfigExpr returns an immutable SQL expression mapping a language column to its
+// regconfig, usable in generated columns and matching ConfigFor
+func ConfigExpr(languageColumn string) string {
+	keys := make([]string, 0, len(configs))
+	for key := range configs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "CASE lower(split_part(split_part(coalesce(%s, ''), '-', 1), '_', 1))", languageColumn)
+	for _, key := range keys {
+		fmt.Fprintf(&b, " WHEN '%s' THEN '%s'::regconfig", key, configs[key])
+	}
+	fmt.Fprintf(&b, " ELSE '%s'::regconfig END", DefaultConfig)
+	return b.String()
+}
+
+// WeightedColumn is a text column indexed with a weight from A (highest) to D
+type WeightedColumn struct {
+	Name   string
+	Weight string
+}
+
+// AddVectorColumn adds a stored tsvector column generated from the weighted columns of a table,
+// with the configuration of each row's language, and a GIN index on it
+func AddVectorColumn(db *gorm.DB, table, languageColumn string, columns ...WeightedColumn) error {
+	config := ConfigExpr(languageColumn)
+
+	parts := make([]string, len(columns))
+	for i, column := range columns {
+		parts[i] = fmt.Sprintf("setweight(to_tsvector(%s, coalesce(%s, '')), '%s')", config, column.Name, column.Weight)
+	}
+
+	statements := []string{
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s tsvector GENERATED ALWAYS AS (%s) STORED",
+			table, VectorColumn, strings.Join(parts, " || ")),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_%s ON %s USING GIN (%s)", table, VectorColumn, table, VectorColumn),
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to add search vector to %s: %w", table, err)
+		}
+	}
+
+	return nil
+}
+
+// Match returns a condition matching the search vector against text parsed by tsqueryFunc
+// (plainto_tsquery, phraseto_tsquery or websearch_to_tsquery).
+// With a language, text is parsed with the configuration of that language and only rows
+// in it match. Without, each row is matched with the configuration of its own language;
+// every configuration is matched separately so that the GIN index can be used.
+func Match(languageColumn, tsqueryFunc, language, text string) (string, []interface{}) {
+	if language != "" {
+		config := ConfigFor(language)
+		return fmt.Sprintf("(%s @@ %s('%s', ?) AND %s = '%s'::regconfig)",
+			VectorColumn, tsqueryFunc, config, ConfigExpr(languageColumn), config), []interface{}{text}
+	}
+
+	configExpr := ConfigExpr(languageColumn)
+	all := Configs()
+	conditions := make([]string, len(all))
+	args := make([]interface{}, len(all))
+	for i, config := range all {
+		conditions[i] = fmt.Sprintf("(%s @@ %s('%s', ?) AND %s = '%s'::regconfig)",
+			VectorColumn, tsqueryFunc, config, configExpr, config)
+		args[i] = text
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// RankExpr returns an expression ranking the search vector against text parsed by tsqueryFunc
+// with the configuration of the row's language, text is its single argument
+func RankExpr(languageColumn, tsqueryFunc string) string {
+	return fmt.Sprintf("ts_rank_cd(%s, %s(%s, ?))", VectorColumn, tsqueryFunc, ConfigExpr(languageColumn))
+}
@@ -0,0 +1,119 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gerthdala/webcrawler/internal/domain/watch"
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DeliveryRepository implements watch.DeliveryRepository using PostgreSQL
+type DeliveryRepository struct {
+	db *gorm.DB
+}
+
+// NewDeliveryRepository creates a new DeliveryRepository
+func NewDeliveryRepository(db *gorm.DB) *DeliveryRepository {
+	return &DeliveryRepository{
+		db: db,
+	}
+}
+
+// Save stores a Delivery
+func (r *DeliveryRepository) Save(ctx context.Context, delivery *watch.Delivery) result.Result[*watch.Delivery] {
+	tx := r.db.WithContext(ctx)
+	model := DeliveryModelFromDomain(delivery)
+
+	if err := tx.Create(model).Error; err != nil {
+		return result.Err[*watch.Delivery](fmt.Errorf("failed to save Delivery: %w", err))
+	}
+
+	return result.Ok(delivery)
+}
+
+// Update updates a stored Delivery
+func (r *DeliveryRepository) Update(ctx context.Context, delivery *watch.Delivery) result.Result[*watch.Delivery] {
+	tx := r.db.WithContext(ctx)
+	model := DeliveryModelFromDomain(delivery)
+
+	if err := tx.Save(model).Error; err != nil {
+		return result.Err[*watch.Delivery](fmt.Errorf("failed to update Delivery: %w", err))
+	}
+
+	return result.Ok(delivery)
+}
+
+// RecordAttempt updates a stored Delivery and adds an attempt to its log
+func (r *DeliveryRepository) RecordAttempt(ctx context.Context, delivery *watch.Delivery, attempt *watch.DeliveryAttempt) result.Result[*watch.Delivery] {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(DeliveryModelFromDomain(delivery)).Error; err != nil {
+			return err
+		}
+		return tx.Create(DeliveryAttemptModelFromDomain(attempt)).Error
+	})
+	if err != nil {
+		return result.Err[*watch.Delivery](fmt.Errorf("failed to record Delivery attempt: %w", err))
+	}
+
+	return result.Ok(delivery)
+}
+
+// FindAttempts finds the attempts of a Delivery, first attempt first
+func (r *DeliveryRepository) FindAttempts(ctx context.Context, deliveryID uuid.UUID) result.Result[[]watch.DeliveryAttempt] {
+	tx := r.db.WithContext(ctx)
+	var models []DeliveryAttemptModel
+
+	if err := tx.Where("delivery_id = ?", deliveryID).
+		Order("attempt ASC").
+		Find(&models).Error; err != nil {
+		return result.Err[[]watch.DeliveryAttempt](fmt.Errorf("failed to find Delivery attempts: %w", err))
+	}
+
+	attempts := make([]watch.DeliveryAttempt, len(models))
+	for i, model := range models {
+		attempts[i] = *model.ToDomain()
+	}
+	return result.Ok(attempts)
+}
+
+// FindDue finds pending Deliveries due to be attempted at the given time, most overdue first
+func (r *DeliveryRepository) FindDue(ctx context.Context, now time.Time, limit int) result.Result[[]watch.Delivery] {
+	tx := r.db.WithContext(ctx)
+	var models []DeliveryModel
+
+	if err := tx.Where("status = ? AND next_attempt_at <= ?", string(watch.DeliveryPending), now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&models).Error; err != nil {
+		return result.Err[[]watch.Delivery](fmt.Errorf("failed to find due Deliveries: %w", err))
+	}
+
+	return result.Ok(toDeliveries(models))
+}
+
+// FindByRule finds the Deliveries of a Rule, latest first
+func (r *DeliveryRepository) FindByRule(ctx context.Context, ruleID uuid.UUID, limit int) result.Result[[]watch.Delivery] {
+	tx := r.db.WithContext(ctx)
+	var models []DeliveryModel
+
+	if err := tx.Where("rule_id = ?", ruleID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&models).Error; err != nil {
+		return result.Err[[]watch.Delivery](fmt.Errorf("failed to find Deliveries by Rule: %w", err))
+	}
+
+	return result.Ok(toDeliveries(models))
+}
+
+func toDeliveries(models []DeliveryModel) []watch.Delivery {
+	deliveries := make([]watch.Delivery, len(models))
+	for i, model := range models {
+		deliveries[i] = *model.ToDomain()
+	}
+	return deliveries
+}
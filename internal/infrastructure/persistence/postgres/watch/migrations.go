@@ -0,0 +1,17 @@
+package watch
+
+import (
+	"fmt"
+
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+	"gorm.io/gorm"
+)
+
+// Migrate creates or updates the watch rule, delivery and delivery attempt tables
+func Migrate(db *gorm.DB) result.Result[bool] {
+	if err := db.AutoMigrate(&RuleModel{}, &DeliveryModel{}, &DeliveryAttemptModel{}); err != nil {
+		return result.Err[bool](fmt.Errorf("failed to migrate watch tables: %w", err))
+	}
+
+	return result.Ok(true)
+}
@@ -0,0 +1,178 @@
+package watch
+
+import (
+	"time"
+
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
+	"github.com/gerthdala/webcrawler/internal/domain/watch"
+	"github.com/google/uuid"
+)
+
+// RuleModel is the database model for Rule
+type RuleModel struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key"`
+	Name       string    `gorm:"not null"`
+	URLPattern string    `gorm:"not null"`
+	Selector   string
+	Keyword    string
+	MinChange  int       `gorm:"not null;default:0"`
+	Events     []string  `gorm:"type:jsonb;serializer:json"`
+	WebhookURL string    `gorm:"not null"`
+	Secret     string    `gorm:"not null"`
+	Enabled    bool      `gorm:"index;not null"`
+	CreatedAt  time.Time `gorm:"not null"`
+	UpdatedAt  time.Time `gorm:"not null"`
+}
+
+// TableName returns the table name for the Rule model
+func (RuleModel) TableName() string {
+	return "watch_rules"
+}
+
+// ToDomain converts RuleModel to domain Rule
+func (m *RuleModel) ToDomain() *watch.Rule {
+	events := make([]crawler.ChangeKind, len(m.Events))
+	for i, event := range m.Events {
+		events[i] = crawler.ChangeKind(event)
+	}
+
+	return &watch.Rule{
+		ID:         m.ID,
+		Name:       m.Name,
+		URLPattern: m.URLPattern,
+		Selector:   m.Selector,
+		Keyword:    m.Keyword,
+		MinChange:  m.MinChange,
+		Events:     events,
+		WebhookURL: m.WebhookURL,
+		Secret:     m.Secret,
+		Enabled:    m.Enabled,
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+	}
+}
+
+// RuleModelFromDomain converts domain Rule to RuleModel
+func RuleModelFromDomain(rule *watch.Rule) *RuleModel {
+	events := make([]string, len(rule.Events))
+	for i, event := range rule.Events {
+		events[i] = string(event)
+	}
+
+	return &RuleModel{
+		ID:         rule.ID,
+		Name:       rule.Name,
+		URLPattern: rule.URLPattern,
+		Selector:   rule.Selector,
+		Keyword:    rule.Keyword,
+		MinChange:  rule.MinChange,
+		Events:     events,
+		WebhookURL: rule.WebhookURL,
+		Secret:     rule.Secret,
+		Enabled:    rule.Enabled,
+		CreatedAt:  rule.CreatedAt,
+		UpdatedAt:  rule.UpdatedAt,
+	}
+}
+
+// DeliveryModel is the database model for Delivery
+type DeliveryModel struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key"`
+	RuleID         uuid.UUID `gorm:"type:uuid;index;not null"`
+	Event          string    `gorm:"not null"`
+	URL            string    `gorm:"not null"`
+	Payload        []byte    `gorm:"type:jsonb;not null"`
+	Status         string    `gorm:"index:idx_watch_deliveries_due;not null"`
+	Attempts       int       `gorm:"not null;default:0"`
+	LastAttemptAt  time.Time
+	LastStatusCode int
+	LastError      string
+	NextAttemptAt  time.Time `gorm:"index:idx_watch_deliveries_due"`
+	DeliveredAt    time.Time
+	CreatedAt      time.Time `gorm:"index;not null"`
+	UpdatedAt      time.Time `gorm:"not null"`
+}
+
+// TableName returns the table name for the Delivery model
+func (DeliveryModel) TableName() string {
+	return "watch_deliveries"
+}
+
+// ToDomain converts DeliveryModel to domain Delivery
+func (m *DeliveryModel) ToDomain() *watch.Delivery {
+	return &watch.Delivery{
+		ID:             m.ID,
+		RuleID:         m.RuleID,
+		Event:          crawler.ChangeKind(m.Event),
+		URL:            m.URL,
+		Payload:        m.Payload,
+		Status:         watch.DeliveryStatus(m.Status),
+		Attempts:       m.Attempts,
+		LastAttemptAt:  m.LastAttemptAt,
+		LastStatusCode: m.LastStatusCode,
+		LastError:      m.LastError,
+		NextAttemptAt:  m.NextAttemptAt,
+		DeliveredAt:    m.DeliveredAt,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+}
+
+// DeliveryAttemptModel is the database model for DeliveryAttempt
+type DeliveryAttemptModel struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key"`
+	DeliveryID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_watch_delivery_attempts_number;not null"`
+	Attempt    int       `gorm:"uniqueIndex:idx_watch_delivery_attempts_number;not null"`
+	StatusCode int
+	Error      string
+	At         time.Time `gorm:"not null"`
+}
+
+// TableName returns the table name for the DeliveryAttempt model
+func (DeliveryAttemptModel) TableName() string {
+	return "watch_delivery_attempts"
+}
+
+// ToDomain converts DeliveryAttemptModel to domain DeliveryAttempt
+func (m *DeliveryAttemptModel) ToDomain() *watch.DeliveryAttempt {
+	return &watch.DeliveryAttempt{
+		ID:         m.ID,
+		DeliveryID: m.DeliveryID,
+		Attempt:    m.Attempt,
+		StatusCode: m.StatusCode,
+		Error:      m.Error,
+		At:         m.At,
+	}
+}
+
+// DeliveryAttemptModelFromDomain converts domain DeliveryAttempt to DeliveryAttemptModel
+func DeliveryAttemptModelFromDomain(attempt *watch.DeliveryAttempt) *DeliveryAttemptModel {
+	return &DeliveryAttemptModel{
+		ID:         attempt.ID,
+		DeliveryID: attempt.DeliveryID,
+		Attempt:    attempt.Attempt,
+		StatusCode: attempt.StatusCode,
+		Error:      attempt.Error,
+		At:         attempt.At,
+	}
+}
+
+// DeliveryModelFromDomain converts domain Delivery to DeliveryModel
+func DeliveryModelFromDomain(delivery *watch.Delivery) *DeliveryModel {
+	return &DeliveryModel{
+		ID:             delivery.ID,
+		RuleID:         delivery.RuleID,
+		Event:          string(delivery.Event),
+		URL:            delivery.URL,
+		Payload:        delivery.Payload,
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		LastAttemptAt:  delivery.LastAttemptAt,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+		UpdatedAt:      delivery.UpdatedAt,
+	}
+}
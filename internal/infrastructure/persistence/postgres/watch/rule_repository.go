@@ -0,0 +1,98 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gerthdala/webcrawler/internal/domain/watch"
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RuleRepository implements watch.RuleRepository using PostgreSQL
+type RuleRepository struct {
+	db *gorm.DB
+}
+
+// NewRuleRepository creates a new RuleRepository
+func NewRuleRepository(db *gorm.DB) *RuleRepository {
+	return &RuleRepository{
+		db: db,
+	}
+}
+
+// Save stores a Rule
+func (r *RuleRepository) Save(ctx context.Context, rule *watch.Rule) result.Result[*watch.Rule] {
+	tx := r.db.WithContext(ctx)
+	model := RuleModelFromDomain(rule)
+
+	if err := tx.Create(model).Error; err != nil {
+		return result.Err[*watch.Rule](fmt.Errorf("failed to save Rule: %w", err))
+	}
+
+	return result.Ok(rule)
+}
+
+// FindByID finds a Rule by its ID
+func (r *RuleRepository) FindByID(ctx context.Context, id uuid.UUID) result.Result[*watch.Rule] {
+	tx := r.db.WithContext(ctx)
+	var model RuleModel
+
+	if err := tx.Where("id = ?", id).First(&model).Error; err != nil {
+		return result.Err[*watch.Rule](fmt.Errorf("failed to find Rule by ID: %w", err))
+	}
+
+	return result.Ok(model.ToDomain())
+}
+
+// FindAll finds all Rules, oldest first
+func (r *RuleRepository) FindAll(ctx context.Context) result.Result[[]watch.Rule] {
+	return r.find(ctx, r.db.WithContext(ctx))
+}
+
+// FindEnabled finds the enabled Rules
+func (r *RuleRepository) FindEnabled(ctx context.Context) result.Result[[]watch.Rule] {
+	return r.find(ctx, r.db.WithContext(ctx).Where("enabled = ?", true))
+}
+
+func (r *RuleRepository) find(ctx context.Context, tx *gorm.DB) result.Result[[]watch.Rule] {
+	var models []RuleModel
+
+	if err := tx.Order("created_at ASC").Find(&models).Error; err != nil {
+		return result.Err[[]watch.Rule](fmt.Errorf("failed to find Rules: %w", err))
+	}
+
+	rules := make([]watch.Rule, len(models))
+	for i, model := range models {
+		rules[i] = *model.ToDomain()
+	}
+
+	return result.Ok(rules)
+}
+
+// Delete deletes a Rule and its deliveries, reporting whether the Rule existed
+func (r *RuleRepository) Delete(ctx context.Context, id uuid.UUID) result.Result[bool] {
+	deleted := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		deliveries := tx.Model(&DeliveryModel{}).Select("id").Where("rule_id = ?", id)
+		if err := tx.Where("delivery_id IN (?)", deliveries).Delete(&DeliveryAttemptModel{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("rule_id = ?", id).Delete(&DeliveryModel{}).Error; err != nil {
+			return err
+		}
+		dbResult := tx.Where("id = ?", id).Delete(&RuleModel{})
+		if dbResult.Error != nil {
+			return dbResult.Error
+		}
+		deleted = dbResult.RowsAffected > 0
+		return nil
+	})
+	if err != nil {
+		return result.Err[bool](fmt.Errorf("failed to delete Rule: %w", err))
+	}
+
+	return result.Ok(deleted)
+}
@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gerthdala/webcrawler/internal/domain/watch"
+	result "github.com/gerthdala/webcrawler/pkg/utils/result"
+)
+
+// Webhook request headers
+const (
+	HeaderSignature = "X-Webcrawler-Signature"
+	HeaderTimestamp = "X-Webcrawler-Timestamp"
+	HeaderEvent     = "X-Webcrawler-Event"
+	HeaderDelivery  = "X-Webcrawler-Delivery"
+)
+
+// Sender implements the watch.WebhookSender interface over HTTP
+type Sender struct {
+	client    *http.Client
+	userAgent string
+}
+
+// SenderConfig configuration for the webhook sender
+type SenderConfig struct {
+	UserAgent string
+	// Timeout bounds each delivery attempt, 10 seconds by default
+	Timeout time.Duration
+}
+
+// NewSender creates a new Sender
+func NewSender(config SenderConfig) *Sender {
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	return &Sender{
+		client:    &http.Client{Timeout: timeout},
+		userAgent: config.UserAgent,
+	}
+}
+
+// Send posts the JSON payload of a delivery to webhookURL. The payload is signed with
+// HMAC-SHA256 over the timestamp header, a dot and the body, sent as sha256=<hex> in the signature header.
+func (s *Sender) Send(ctx context.Context, webhookURL, secret string, delivery *watch.Delivery) result.Result[int] {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return result.Err[int](fmt.Errorf("error creating request: %w", err))
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", s.userAgent)
+	req.Header.Set(HeaderEvent, string(delivery.Event))
+	req.Header.Set(HeaderDelivery, delivery.ID.String())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return result.Err[int](fmt.Errorf("error posting webhook to %s: %w", webhookURL, err))
+	}
+	defer resp.Body.Close()
+
+	// Drain the body so that the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	return result.Ok(resp.StatusCode)
+}
+
+// Sign returns the signature header value of a webhook body sent at timestamp, in Unix seconds
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of a webhook body sent at timestamp,
+// no more than tolerance ago, so that receivers can reject forged and replayed webhooks
+func Verify(secret, signature string, timestamp int64, body []byte, tolerance time.Duration) bool {
+	age := time.Since(time.Unix(timestamp, 0))
+	if age > tolerance || age < -tolerance {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body)))
+}
@@ -0,0 +1,115 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
+	"github.com/gerthdala/webcrawler/internal/domain/watch"
+	"github.com/google/uuid"
+)
+
+func TestSendSignsPayload(t *testing.T) {
+	const secret = "s3cret"
+	payload := []byte(`{"event":"changed"}`)
+	delivery := watch.NewDelivery(uuid.New(), uuid.New(), crawler.ChangeChanged, "https://example.com/", payload)
+
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sendResult := NewSender(SenderConfig{UserAgent: "test"}).Send(context.Background(), server.URL, secret, delivery)
+	if sendResult.IsErr() {
+		t.Fatalf("Send() error = %v", sendResult.Error())
+	}
+	if got := sendResult.Unwrap(); got != http.StatusAccepted {
+		t.Errorf("Send() status = %d, want %d", got, http.StatusAccepted)
+	}
+
+	if received.Method != http.MethodPost {
+		t.Errorf("method = %s, want POST", received.Method)
+	}
+	if got := received.Header.Get(HeaderEvent); got != string(crawler.ChangeChanged) {
+		t.Errorf("%s = %q, want %q", HeaderEvent, got, crawler.ChangeChanged)
+	}
+	if got := received.Header.Get(HeaderDelivery); got != delivery.ID.String() {
+		t.Errorf("%s = %q, want %q", HeaderDelivery, got, delivery.ID)
+	}
+	if string(body) != string(payload) {
+		t.Errorf("body = %s, want %s", body, payload)
+	}
+
+	timestamp, err := strconv.ParseInt(received.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		t.Fatalf("invalid %s: %v", HeaderTimestamp, err)
+	}
+	signature := received.Header.Get(HeaderSignature)
+	if signature != Sign(secret, timestamp, body) {
+		t.Errorf("%s = %q, want %q", HeaderSignature, signature, Sign(secret, timestamp, body))
+	}
+	if !Verify(secret, signature, timestamp, body, time.Minute) {
+		t.Error("Verify() = false for the signature sent")
+	}
+}
+
+func TestSendUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	delivery := watch.NewDelivery(uuid.New(), uuid.New(), crawler.ChangeChanged, "https://example.com/", []byte("{}"))
+	if sendResult := NewSender(SenderConfig{}).Send(context.Background(), server.URL, "secret", delivery); sendResult.IsOk() {
+		t.Error("Send() to a closed server succeeded")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	const secret = "s3cret"
+	body := []byte(`{"a":1}`)
+	now := time.Now().Unix()
+	signature := Sign(secret, now, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		timestamp int64
+		body      []byte
+		want      bool
+	}{
+		{"valid", secret, signature, now, body, true},
+		{"wrong secret", "other", signature, now, body, false},
+		{"tampered body", secret, signature, now, []byte(`{"a":2}`), false},
+		{"tampered timestamp", secret, signature, now - 1, body, false},
+		{"malformed signature", secret, "sha256=zz", now, body, false},
+		{"expired", secret, Sign(secret, now-600, body), now - 600, body, false},
+		{"from the future", secret, Sign(secret, now+600, body), now + 600, body, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.signature, tt.timestamp, tt.body, 5*time.Minute); got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignFormat(t *testing.T) {
+	got := Sign("key", 1700000000, []byte("{}"))
+	if len(got) != len("sha256=")+64 || got[:7] != "sha256=" {
+		t.Errorf("Sign() = %q, want sha256=<64 hex digits>", got)
+	}
+	if got != Sign("key", 1700000000, []byte("{}")) {
+		t.Error("Sign() is not deterministic")
+	}
+}
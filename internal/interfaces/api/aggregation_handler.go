@@ -0,0 +1,142 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gerthdala/webcrawler/internal/domain/aggregation"
+	"github.com/gerthdala/webcrawler/internal/domain/content"
+	"github.com/gerthdala/webcrawler/internal/domain/search/query"
+)
+
+const maxFacetLimit = 1000
+
+// AggregationHandler exposes faceted counts over crawled content
+type AggregationHandler struct {
+	service *aggregation.AggregationService
+}
+
+// NewAggregationHandler creates a new AggregationHandler
+func NewAggregationHandler(service *aggregation.AggregationService) *AggregationHandler {
+	return &AggregationHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the aggregation routes on mux
+func (h *AggregationHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/content/aggregations", h.Aggregate)
+}
+
+type facetBucketResponse struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type histogramBucketResponse struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int     `json:"count"`
+}
+
+type aggregationResponse struct {
+	Total      int                                  `json:"total"`
+	Facets     map[string][]facetBucketResponse     `json:"facets"`
+	Histograms map[string][]histogramBucketResponse `json:"histograms"`
+}
+
+// Aggregate handles GET /api/content/aggregations?q=&facets=&facet_limit=&interval=&histograms=&domain=&lang=&type=&after=&before=
+// facets is a comma separated list of facets, histograms a comma separated list of field[:width], e.g. word_count:500
+func (h *AggregationHandler) Aggregate(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	filter, err := parseSearchFilter(params)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	req := aggregation.AggregationRequest{
+		Query:        params.Get("q"),
+		Filter:       filter,
+		DateInterval: content.DateInterval(params.Get("interval")),
+	}
+
+	for _, name := range splitList(params.Get("facets")) {
+		req.Facets = append(req.Facets, content.Facet(name))
+	}
+
+	if raw := params.Get("facet_limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > maxFacetLimit {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("facet_limit must be between 1 and %d", maxFacetLimit))
+			return
+		}
+		req.FacetLimit = parsed
+	}
+
+	if params.Has("histograms") {
+		req.Histograms = make(map[content.HistogramField]float64)
+		for _, spec := range splitList(params.Get("histograms")) {
+			name, rawWidth, hasWidth := strings.Cut(spec, ":")
+			width := 0.0
+			if hasWidth {
+				parsed, err := strconv.ParseFloat(rawWidth, 64)
+				if err != nil || parsed <= 0 {
+					writeError(w, http.StatusBadRequest, fmt.Errorf("invalid histogram width %q for %s", rawWidth, name))
+					return
+				}
+				width = parsed
+			}
+			req.Histograms[content.HistogramField(name)] = width
+		}
+	}
+
+	aggResult := h.service.Aggregate(r.Context(), req)
+	if aggResult.IsErr() {
+		status := http.StatusInternalServerError
+		var parseErr *query.ParseError
+		if errors.Is(aggResult.Error(), aggregation.ErrInvalidRequest) || errors.As(aggResult.Error(), &parseErr) {
+			status = http.StatusBadRequest
+		}
+		writeError(w, status, aggResult.Error())
+		return
+	}
+
+	agg := aggResult.Unwrap()
+	resp := aggregationResponse{
+		Total:      agg.Total,
+		Facets:     make(map[string][]facetBucketResponse, len(agg.Facets)),
+		Histograms: make(map[string][]histogramBucketResponse, len(agg.Histograms)),
+	}
+	for facet, buckets := range agg.Facets {
+		out := make([]facetBucketResponse, len(buckets))
+		for i, bucket := range buckets {
+			out[i] = facetBucketResponse{Value: bucket.Value, Count: bucket.Count}
+		}
+		resp.Facets[string(facet)] = out
+	}
+	for field, buckets := range agg.Histograms {
+		out := make([]histogramBucketResponse, len(buckets))
+		for i, bucket := range buckets {
+			out[i] = histogramBucketResponse{From: bucket.From, To: bucket.To, Count: bucket.Count}
+		}
+		resp.Histograms[string(field)] = out
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// splitList splits a comma separated parameter, ignoring blank items
+func splitList(raw string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
+)
+
+const maxFetchErrorLimit = 1000
+
+// FetchErrorHandler exposes the fetch error counts by host and kind
+type FetchErrorHandler struct {
+	service *crawler.CrawlService
+}
+
+// NewFetchErrorHandler creates a new FetchErrorHandler
+func NewFetchErrorHandler(service *crawler.CrawlService) *FetchErrorHandler {
+	return &FetchErrorHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the fetch error routes on mux
+func (h *FetchErrorHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/crawler/fetch-errors", h.List)
+}
+
+type fetchErrorsResponse struct {
+	Host      string    `json:"host"`
+	Kind      string    `json:"kind"`
+	Retryable bool      `json:"retryable"`
+	Count     int       `json:"count"`
+	LastError string    `json:"last_error"`
+	LastAt    time.Time `json:"last_at"`
+}
+
+// List handles GET /api/crawler/fetch-errors?host=&limit=
+// The counts of a host are listed by kind, and without host those with the most errors across hosts
+func (h *FetchErrorHandler) List(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > maxFetchErrorLimit {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("limit must be between 1 and %d", maxFetchErrorLimit))
+			return
+		}
+		limit = parsed
+	}
+
+	countsResult := h.service.FetchErrors(r.Context(), r.URL.Query().Get("host"), limit)
+	if countsResult.IsErr() {
+		writeError(w, http.StatusInternalServerError, countsResult.Error())
+		return
+	}
+
+	counts := countsResult.Unwrap()
+	resp := make([]fetchErrorsResponse, len(counts))
+	for i, c := range counts {
+		resp[i] = fetchErrorsResponse{
+			Host:      c.Host,
+			Kind:      string(c.Kind),
+			Retryable: c.Kind.IsRetryable(),
+			Count:     c.Count,
+			LastError: c.LastError,
+			LastAt:    c.LastAt,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
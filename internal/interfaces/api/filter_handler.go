@@ -0,0 +1,54 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
+)
+
+// FilterHandler exposes the include and exclude rules of the URL filter
+type FilterHandler struct {
+	filter crawler.URLFilterService
+}
+
+// NewFilterHandler creates a new FilterHandler
+func NewFilterHandler(filter crawler.URLFilterService) *FilterHandler {
+	return &FilterHandler{
+		filter: filter,
+	}
+}
+
+// RegisterRoutes registers the filter routes on mux
+func (h *FilterHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/crawler/filter/rules", h.GetRules)
+	mux.HandleFunc("PUT /api/crawler/filter/rules", h.UpdateRules)
+}
+
+// GetRules handles GET /api/crawler/filter/rules
+func (h *FilterHandler) GetRules(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.filter.Rules())
+}
+
+// UpdateRules handles PUT /api/crawler/filter/rules
+// The rules are replaced as a whole, and left unchanged when any of them is invalid
+func (h *FilterHandler) UpdateRules(w http.ResponseWriter, r *http.Request) {
+	var rules crawler.FilterRules
+	if err := decodeJSON(r, &rules); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	updateResult := h.filter.UpdateRules(rules)
+	if updateResult.IsErr() {
+		status := http.StatusInternalServerError
+		if errors.Is(updateResult.Error(), crawler.ErrInvalidFilterRule) {
+			status = http.StatusBadRequest
+		}
+		writeError(w, status, updateResult.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.filter.Rules())
+}
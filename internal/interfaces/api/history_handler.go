@@ -0,0 +1,231 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
+)
+
+const maxHistoryLimit = 1000
+
+// HistoryHandler exposes the version history of crawled pages
+type HistoryHandler struct {
+	service *crawler.HistoryService
+}
+
+// NewHistoryHandler creates a new HistoryHandler
+func NewHistoryHandler(service *crawler.HistoryService) *HistoryHandler {
+	return &HistoryHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the history routes on mux
+func (h *HistoryHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/pages/versions", h.Versions)
+	mux.HandleFunc("GET /api/pages/diff", h.Diff)
+	mux.HandleFunc("GET /api/pages/changes", h.Changes)
+}
+
+type pageVersionResponse struct {
+	Version     int       `json:"version"`
+	ContentHash string    `json:"content_hash"`
+	StatusCode  int       `json:"status_code"`
+	Title       string    `json:"title,omitempty"`
+	FetchedAt   time.Time `json:"fetched_at"`
+}
+
+type pageVersionsResponse struct {
+	URL      string                `json:"url"`
+	Versions []pageVersionResponse `json:"versions"`
+}
+
+type diffEditResponse struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type pageDiffResponse struct {
+	URL   string              `json:"url"`
+	From  pageVersionResponse `json:"from"`
+	To    pageVersionResponse `json:"to"`
+	Edits []diffEditResponse  `json:"edits"`
+}
+
+type pageChangeResponse struct {
+	URL           string    `json:"url"`
+	Created       bool      `json:"created"`
+	Versions      int       `json:"versions"`
+	LatestVersion int       `json:"latest_version"`
+	ChangedAt     time.Time `json:"changed_at"`
+}
+
+type pageChangesResponse struct {
+	Domain  string               `json:"domain,omitempty"`
+	Since   time.Time            `json:"since"`
+	Changes []pageChangeResponse `json:"changes"`
+}
+
+// Versions handles GET /api/pages/versions?url=&limit=
+func (h *HistoryHandler) Versions(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	pageURL := params.Get("url")
+	if pageURL == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("url is required"))
+		return
+	}
+	limit, err := parseHistoryLimit(params)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	versionsResult := h.service.Versions(r.Context(), pageURL, limit)
+	if versionsResult.IsErr() {
+		writeError(w, http.StatusInternalServerError, versionsResult.Error())
+		return
+	}
+
+	versions := versionsResult.Unwrap()
+	resp := pageVersionsResponse{
+		URL:      pageURL,
+		Versions: make([]pageVersionResponse, len(versions)),
+	}
+	for i := range versions {
+		resp.Versions[i] = newPageVersionResponse(&versions[i])
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Diff handles GET /api/pages/diff?url=&from=&to=&granularity=line|word
+func (h *HistoryHandler) Diff(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	pageURL := params.Get("url")
+	if pageURL == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("url is required"))
+		return
+	}
+	from, err := strconv.Atoi(params.Get("from"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("from must be a version number"))
+		return
+	}
+	to, err := strconv.Atoi(params.Get("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("to must be a version number"))
+		return
+	}
+	granularity := crawler.DiffGranularity(params.Get("granularity"))
+	if granularity == "" {
+		granularity = crawler.DiffLines
+	}
+
+	diffResult := h.service.Diff(r.Context(), pageURL, from, to, granularity)
+	if diffResult.IsErr() {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(diffResult.Error(), crawler.ErrInvalidDiff):
+			status = http.StatusBadRequest
+		case errors.Is(diffResult.Error(), crawler.ErrVersionNotFound):
+			status = http.StatusNotFound
+		}
+		writeError(w, status, diffResult.Error())
+		return
+	}
+
+	diff := diffResult.Unwrap()
+	resp := pageDiffResponse{
+		URL:   pageURL,
+		From:  newPageVersionResponse(diff.From),
+		To:    newPageVersionResponse(diff.To),
+		Edits: make([]diffEditResponse, len(diff.Edits)),
+	}
+	for i, edit := range diff.Edits {
+		resp.Edits[i] = diffEditResponse{
+			Op:   string(edit.Op),
+			Text: edit.Text,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Changes handles GET /api/pages/changes?domain=&since=&limit=
+// since is a date (YYYY-MM-DD) or an RFC 3339 time
+func (h *HistoryHandler) Changes(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	raw := params.Get("since")
+	if raw == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("since is required"))
+		return
+	}
+	since, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		if since, err = time.Parse("2006-01-02", raw); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("since must be a date (YYYY-MM-DD) or an RFC 3339 time"))
+			return
+		}
+	}
+	limit, err := parseHistoryLimit(params)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	domain := params.Get("domain")
+	changesResult := h.service.ChangesSince(r.Context(), domain, since, limit)
+	if changesResult.IsErr() {
+		writeError(w, http.StatusInternalServerError, changesResult.Error())
+		return
+	}
+
+	changes := changesResult.Unwrap()
+	resp := pageChangesResponse{
+		Domain:  domain,
+		Since:   since,
+		Changes: make([]pageChangeResponse, len(changes)),
+	}
+	for i, change := range changes {
+		resp.Changes[i] = pageChangeResponse{
+			URL:           change.URL,
+			Created:       change.Created,
+			Versions:      change.Versions,
+			LatestVersion: change.LatestVersion,
+			ChangedAt:     change.ChangedAt,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// parseHistoryLimit parses the limit query parameter, 100 by default
+func parseHistoryLimit(params url.Values) (int, error) {
+	raw := params.Get("limit")
+	if raw == "" {
+		return 100, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit <= 0 || limit > maxHistoryLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d", maxHistoryLimit)
+	}
+	return limit, nil
+}
+
+func newPageVersionResponse(version *crawler.PageVersion) pageVersionResponse {
+	return pageVersionResponse{
+		Version:     version.Version,
+		ContentHash: version.ContentHash,
+		StatusCode:  version.StatusCode,
+		Title:       version.Title,
+		FetchedAt:   version.FetchedAt,
+	}
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
+)
+
+// RecrawlHandler exposes the recrawl schedule
+type RecrawlHandler struct {
+	service *crawler.RecrawlService
+}
+
+// NewRecrawlHandler creates a new RecrawlHandler
+func NewRecrawlHandler(service *crawler.RecrawlService) *RecrawlHandler {
+	return &RecrawlHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the recrawl routes on mux
+func (h *RecrawlHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/crawler/recrawls", h.Upcoming)
+}
+
+type domainRecrawlsResponse struct {
+	Domain string    `json:"domain"`
+	Count  int       `json:"count"`
+	NextAt time.Time `json:"next_at"`
+}
+
+type upcomingRecrawlsResponse struct {
+	Within  string                   `json:"within"`
+	Domains []domainRecrawlsResponse `json:"domains"`
+}
+
+// Upcoming handles GET /api/crawler/recrawls?within=24h
+func (h *RecrawlHandler) Upcoming(w http.ResponseWriter, r *http.Request) {
+	within := 24 * time.Hour
+	if raw := r.URL.Query().Get("within"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid within duration %q", raw))
+			return
+		}
+		within = parsed
+	}
+
+	upcomingResult := h.service.Upcoming(r.Context(), within)
+	if upcomingResult.IsErr() {
+		writeError(w, http.StatusInternalServerError, upcomingResult.Error())
+		return
+	}
+
+	domains := upcomingResult.Unwrap()
+	resp := upcomingRecrawlsResponse{
+		Within:  within.String(),
+		Domains: make([]domainRecrawlsResponse, len(domains)),
+	}
+	for i, d := range domains {
+		resp.Domains[i] = domainRecrawlsResponse{
+			Domain: d.Domain,
+			Count:  d.Count,
+			NextAt: d.NextAt,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
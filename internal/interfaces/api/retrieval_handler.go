@@ -0,0 +1,110 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gerthdala/webcrawler/internal/domain/content"
+	"github.com/gerthdala/webcrawler/internal/domain/retrieval"
+)
+
+const maxRetrieveK = 100
+
+// RetrievalHandler exposes passage retrieval over crawled content
+type RetrievalHandler struct {
+	service *retrieval.RetrievalService
+}
+
+// NewRetrievalHandler creates a new RetrievalHandler
+func NewRetrievalHandler(service *retrieval.RetrievalService) *RetrievalHandler {
+	return &RetrievalHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the retrieval routes on mux
+func (h *RetrievalHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/content/retrieve", h.Retrieve)
+}
+
+type retrieveRequest struct {
+	Query   string          `json:"query"`
+	K       int             `json:"k"`
+	Filters retrieveFilters `json:"filters"`
+}
+
+type retrieveFilters struct {
+	Domain      string `json:"domain"`
+	Language    string `json:"language"`
+	ContentType string `json:"content_type"`
+	URLPrefix   string `json:"url_prefix"`
+}
+
+type passageResponse struct {
+	ChunkID     string  `json:"chunk_id"`
+	ContentID   string  `json:"content_id"`
+	URL         string  `json:"url"`
+	Title       string  `json:"title"`
+	Heading     string  `json:"heading,omitempty"`
+	Text        string  `json:"text"`
+	StartOffset int     `json:"start_offset"`
+	EndOffset   int     `json:"end_offset"`
+	Score       float64 `json:"score"`
+}
+
+type retrieveResponse struct {
+	Passages []passageResponse `json:"passages"`
+}
+
+// Retrieve handles POST /api/content/retrieve
+func (h *RetrievalHandler) Retrieve(w http.ResponseWriter, r *http.Request) {
+	var req retrieveRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	if strings.TrimSpace(req.Query) == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("query is required"))
+		return
+	}
+	if req.K <= 0 {
+		req.K = 5
+	}
+	if req.K > maxRetrieveK {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("k must not exceed %d", maxRetrieveK))
+		return
+	}
+
+	filters := content.ChunkFilter{
+		Domain:      req.Filters.Domain,
+		Language:    req.Filters.Language,
+		ContentType: content.ContentType(req.Filters.ContentType),
+		URLPrefix:   req.Filters.URLPrefix,
+	}
+
+	retrieveResult := h.service.Retrieve(r.Context(), req.Query, req.K, filters)
+	if retrieveResult.IsErr() {
+		writeError(w, http.StatusInternalServerError, retrieveResult.Error())
+		return
+	}
+
+	passages := retrieveResult.Unwrap()
+	resp := retrieveResponse{Passages: make([]passageResponse, len(passages))}
+	for i, p := range passages {
+		resp.Passages[i] = passageResponse{
+			ChunkID:     p.ChunkID.String(),
+			ContentID:   p.ContentID.String(),
+			URL:         p.URL,
+			Title:       p.Title,
+			Heading:     p.Heading,
+			Text:        p.Text,
+			StartOffset: p.StartOffset,
+			EndOffset:   p.EndOffset,
+			Score:       p.Score,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
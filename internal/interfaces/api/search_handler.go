@@ -0,0 +1,139 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gerthdala/webcrawler/internal/domain/content"
+	"github.com/gerthdala/webcrawler/internal/domain/search"
+	"github.com/gerthdala/webcrawler/internal/domain/search/query"
+)
+
+const maxSearchLimit = 100
+
+// SearchHandler exposes hybrid search over crawled content
+type SearchHandler struct {
+	service *search.SearchService
+}
+
+// NewSearchHandler creates a new SearchHandler
+func NewSearchHandler(service *search.SearchService) *SearchHandler {
+	return &SearchHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the search routes on mux
+func (h *SearchHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/content/search", h.Search)
+}
+
+type searchHitResponse struct {
+	ID             string  `json:"id"`
+	URL            string  `json:"url"`
+	Title          string  `json:"title"`
+	Summary        string  `json:"summary,omitempty"`
+	Language       string  `json:"language,omitempty"`
+	Classification string  `json:"classification,omitempty"`
+	Score          float64 `json:"score"`
+	KeywordRank    int     `json:"keyword_rank,omitempty"`
+	VectorRank     int     `json:"vector_rank,omitempty"`
+	Snippet        string  `json:"snippet"`
+}
+
+type searchResponse struct {
+	Hits       []searchHitResponse `json:"hits"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+// Search handles GET /api/content/search?q=&limit=&cursor=&domain=&lang=&type=&after=&before=
+// q accepts the query language of query.Parse, e.g. title:"release notes" -lang:fr
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	q := strings.TrimSpace(params.Get("q"))
+	if q == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("q is required"))
+		return
+	}
+
+	limit := 10
+	if raw := params.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > maxSearchLimit {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("limit must be between 1 and %d", maxSearchLimit))
+			return
+		}
+		limit = parsed
+	}
+
+	filter, err := parseSearchFilter(params)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	searchResult := h.service.Search(r.Context(), search.SearchRequest{
+		Query:  q,
+		Filter: filter,
+		Limit:  limit,
+		Cursor: params.Get("cursor"),
+	})
+	if searchResult.IsErr() {
+		status := http.StatusInternalServerError
+		var parseErr *query.ParseError
+		if errors.Is(searchResult.Error(), search.ErrInvalidCursor) || errors.As(searchResult.Error(), &parseErr) {
+			status = http.StatusBadRequest
+		}
+		writeError(w, status, searchResult.Error())
+		return
+	}
+
+	page := searchResult.Unwrap()
+	resp := searchResponse{
+		Hits:       make([]searchHitResponse, len(page.Hits)),
+		NextCursor: page.NextCursor,
+	}
+	for i, hit := range page.Hits {
+		resp.Hits[i] = searchHitResponse{
+			ID:             hit.Content.ID.String(),
+			URL:            hit.Content.URL,
+			Title:          hit.Content.Title,
+			Summary:        hit.Content.Summary,
+			Language:       hit.Content.Language,
+			Classification: string(hit.Content.Classification),
+			Score:          hit.Score,
+			KeywordRank:    hit.KeywordRank,
+			VectorRank:     hit.VectorRank,
+			Snippet:        hit.Snippet,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// parseSearchFilter parses the domain, lang, type, after and before query parameters
+func parseSearchFilter(params url.Values) (content.SearchFilter, error) {
+	filter := content.SearchFilter{
+		Domain:      params.Get("domain"),
+		Language:    params.Get("lang"),
+		ContentType: content.ContentType(params.Get("type")),
+	}
+	for name, dst := range map[string]*time.Time{"after": &filter.CreatedAfter, "before": &filter.CreatedBefore} {
+		raw := params.Get(name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return filter, fmt.Errorf("%s must be a date (YYYY-MM-DD)", name)
+		}
+		*dst = parsed
+	}
+	return filter, nil
+}
@@ -0,0 +1,255 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
+	"github.com/gerthdala/webcrawler/internal/domain/watch"
+	"github.com/google/uuid"
+)
+
+const maxDeliveryLimit = 1000
+
+// WatchHandler exposes the watch rules and their webhook delivery log
+type WatchHandler struct {
+	service *watch.WatchService
+}
+
+// NewWatchHandler creates a new WatchHandler
+func NewWatchHandler(service *watch.WatchService) *WatchHandler {
+	return &WatchHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the watch routes on mux
+func (h *WatchHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/watch/rules", h.CreateRule)
+	mux.HandleFunc("GET /api/watch/rules", h.ListRules)
+	mux.HandleFunc("DELETE /api/watch/rules/{id}", h.DeleteRule)
+	mux.HandleFunc("GET /api/watch/rules/{id}/deliveries", h.ListDeliveries)
+	mux.HandleFunc("GET /api/watch/deliveries/{id}/attempts", h.ListAttempts)
+}
+
+type createRuleRequest struct {
+	Name       string   `json:"name"`
+	URLPattern string   `json:"url_pattern"`
+	Selector   string   `json:"selector"`
+	Keyword    string   `json:"keyword"`
+	MinChange  int      `json:"min_change"`
+	Events     []string `json:"events"`
+	WebhookURL string   `json:"webhook_url"`
+	Secret     string   `json:"secret"`
+}
+
+type ruleResponse struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	URLPattern string    `json:"url_pattern"`
+	Selector   string    `json:"selector,omitempty"`
+	Keyword    string    `json:"keyword,omitempty"`
+	MinChange  int       `json:"min_change"`
+	Events     []string  `json:"events,omitempty"`
+	WebhookURL string    `json:"webhook_url"`
+	Secret     string    `json:"secret,omitempty"`
+	Enabled    bool      `json:"enabled"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type deliveryResponse struct {
+	ID             string     `json:"id"`
+	Event          string     `json:"event"`
+	URL            string     `json:"url"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type attemptResponse struct {
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	At         time.Time `json:"at"`
+}
+
+// CreateRule handles POST /api/watch/rules
+// The secret signing the webhooks is generated when not given, and only returned on creation
+func (h *WatchHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	var req createRuleRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	rule := watch.NewRule(req.Name, req.URLPattern, req.WebhookURL, req.Secret)
+	rule.Selector = req.Selector
+	rule.Keyword = req.Keyword
+	rule.MinChange = req.MinChange
+	for _, event := range req.Events {
+		rule.Events = append(rule.Events, crawler.ChangeKind(event))
+	}
+
+	createResult := h.service.CreateRule(r.Context(), rule)
+	if createResult.IsErr() {
+		status := http.StatusInternalServerError
+		if errors.Is(createResult.Error(), watch.ErrInvalidRule) {
+			status = http.StatusBadRequest
+		}
+		writeError(w, status, createResult.Error())
+		return
+	}
+
+	resp := newRuleResponse(createResult.Unwrap())
+	resp.Secret = rule.Secret
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// ListRules handles GET /api/watch/rules
+func (h *WatchHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	rulesResult := h.service.Rules(r.Context())
+	if rulesResult.IsErr() {
+		writeError(w, http.StatusInternalServerError, rulesResult.Error())
+		return
+	}
+
+	rules := rulesResult.Unwrap()
+	resp := make([]ruleResponse, len(rules))
+	for i := range rules {
+		resp[i] = newRuleResponse(&rules[i])
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// DeleteRule handles DELETE /api/watch/rules/{id}
+func (h *WatchHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid rule ID"))
+		return
+	}
+
+	deleteResult := h.service.DeleteRule(r.Context(), id)
+	if deleteResult.IsErr() {
+		writeError(w, http.StatusInternalServerError, deleteResult.Error())
+		return
+	}
+	if !deleteResult.Unwrap() {
+		writeError(w, http.StatusNotFound, fmt.Errorf("rule %s not found", id))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries handles GET /api/watch/rules/{id}/deliveries?limit=
+func (h *WatchHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid rule ID"))
+		return
+	}
+
+	limit := 100
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > maxDeliveryLimit {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("limit must be between 1 and %d", maxDeliveryLimit))
+			return
+		}
+		limit = parsed
+	}
+
+	deliveriesResult := h.service.Deliveries(r.Context(), id, limit)
+	if deliveriesResult.IsErr() {
+		writeError(w, http.StatusInternalServerError, deliveriesResult.Error())
+		return
+	}
+
+	deliveries := deliveriesResult.Unwrap()
+	resp := make([]deliveryResponse, len(deliveries))
+	for i, d := range deliveries {
+		resp[i] = deliveryResponse{
+			ID:             d.ID.String(),
+			Event:          string(d.Event),
+			URL:            d.URL,
+			Status:         string(d.Status),
+			Attempts:       d.Attempts,
+			LastStatusCode: d.LastStatusCode,
+			LastError:      d.LastError,
+			LastAttemptAt:  optionalTime(d.LastAttemptAt),
+			DeliveredAt:    optionalTime(d.DeliveredAt),
+			CreatedAt:      d.CreatedAt,
+		}
+		if d.Status == watch.DeliveryPending {
+			resp[i].NextAttemptAt = optionalTime(d.NextAttemptAt)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// ListAttempts handles GET /api/watch/deliveries/{id}/attempts
+func (h *WatchHandler) ListAttempts(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid delivery ID"))
+		return
+	}
+
+	attemptsResult := h.service.Attempts(r.Context(), id)
+	if attemptsResult.IsErr() {
+		writeError(w, http.StatusInternalServerError, attemptsResult.Error())
+		return
+	}
+
+	attempts := attemptsResult.Unwrap()
+	resp := make([]attemptResponse, len(attempts))
+	for i, a := range attempts {
+		resp[i] = attemptResponse{
+			Attempt:    a.Attempt,
+			StatusCode: a.StatusCode,
+			Error:      a.Error,
+			At:         a.At,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func newRuleResponse(rule *watch.Rule) ruleResponse {
+	events := make([]string, len(rule.Events))
+	for i, event := range rule.Events {
+		events[i] = string(event)
+	}
+
+	return ruleResponse{
+		ID:         rule.ID.String(),
+		Name:       rule.Name,
+		URLPattern: rule.URLPattern,
+		Selector:   rule.Selector,
+		Keyword:    rule.Keyword,
+		MinChange:  rule.MinChange,
+		Events:     events,
+		WebhookURL: rule.WebhookURL,
+		Enabled:    rule.Enabled,
+		CreatedAt:  rule.CreatedAt,
+	}
+}
+
+// optionalTime returns nil for the zero time, omitted from responses
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
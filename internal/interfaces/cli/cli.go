@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Command is a webcrawler sub-command
+type Command interface {
+	// Name returns the name used to invoke the command
+	Name() string
+
+	// Usage returns a one-line description of the command
+	Usage() string
+
+	// Run runs the command with the arguments following its name
+	Run(ctx context.Context, args []string) error
+}
+
+// CLI dispatches command line arguments to sub-commands
+type CLI struct {
+	commands map[string]Command
+	out      io.Writer
+}
+
+// New creates a new CLI with the given commands
+func New(out io.Writer, commands ...Command) *CLI {
+	byName := make(map[string]Command, len(commands))
+	for _, command := range commands {
+		byName[command.Name()] = command
+	}
+
+	return &CLI{
+		commands: byName,
+		out:      out,
+	}
+}
+
+// Run runs the command named by the first argument
+func (c *CLI) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		c.printUsage()
+		return fmt.Errorf("no command given")
+	}
+
+	command, ok := c.commands[args[0]]
+	if !ok {
+		c.printUsage()
+		return fmt.Errorf("unknown command: %s", args[0])
+	}
+
+	return command.Run(ctx, args[1:])
+}
+
+func (c *CLI) printUsage() {
+	names := make([]string, 0, len(c.commands))
+	for name := range c.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(c.out, "Usage: webcrawler <command> [flags]")
+	fmt.Fprintln(c.out)
+	fmt.Fprintln(c.out, "Commands:")
+	for _, name := range names {
+		fmt.Fprintf(c.out, "  %-12s %s\n", name, c.commands[name].Usage())
+	}
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
+)
+
+// ExplainCommand dry-runs the admission pipeline for a URL and prints the decision of each check
+type ExplainCommand struct {
+	service *crawler.CrawlService
+	out     io.Writer
+}
+
+// NewExplainCommand creates a new ExplainCommand
+func NewExplainCommand(service *crawler.CrawlService, out io.Writer) *ExplainCommand {
+	return &ExplainCommand{
+		service: service,
+		out:     out,
+	}
+}
+
+// Name returns the name of the command
+func (c *ExplainCommand) Name() string {
+	return "explain"
+}
+
+// Usage returns a one-line description of the command
+func (c *ExplainCommand) Usage() string {
+	return "Explain whether a URL would be crawled, and which check decides it"
+}
+
+// Run runs the command
+func (c *ExplainCommand) Run(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	flags.SetOutput(c.out)
+
+	depth := flags.Int("depth", -1, "depth to check the URL at (default: its recorded depth, or 0)")
+	hops := flags.Int("hops", -1, "off-domain hops to check the URL at (default: its recorded hops, or 0)")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: webcrawler explain [flags] <url>")
+	}
+
+	explainResult := c.service.Explain(ctx, flags.Arg(0), *depth, *hops)
+	if explainResult.IsErr() {
+		return fmt.Errorf("explain failed: %w", explainResult.Error())
+	}
+
+	admission := explainResult.Unwrap()
+	url := admission.URL
+	fmt.Fprintf(c.out, "url:        %s\n", url.URL)
+	fmt.Fprintf(c.out, "normalized: %s\n", url.NormalizedURL)
+	if admission.Known {
+		fmt.Fprintf(c.out, "recorded:   yes, status %s\n", url.Status)
+		if url.StatusReason != "" {
+			fmt.Fprintf(c.out, "reason:     %s: %s\n", url.StatusReason, url.StatusDetail)
+		}
+	} else {
+		fmt.Fprintln(c.out, "recorded:   no")
+	}
+	fmt.Fprintf(c.out, "depth:      %d, off-domain hops %d\n\n", url.Depth, url.OffDomainHops)
+
+	for _, decision := range admission.Trace {
+		outcome := "pass"
+		if !decision.Allowed {
+			outcome = "DENY"
+		}
+		fmt.Fprintf(c.out, "  %-4s  %-14s %s\n", outcome, decision.Check, decision.Reason)
+	}
+
+	if denial, denied := admission.Trace.Denial(); denied {
+		fmt.Fprintf(c.out, "\ndecision: denied by %s\n", denial.Check)
+	} else {
+		fmt.Fprintln(c.out, "\ndecision: allowed")
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gerthdala/webcrawler/internal/domain/analysis"
+	"github.com/gerthdala/webcrawler/internal/domain/content"
+	"github.com/google/uuid"
+)
+
+// ReanalyzeCommand re-runs analysis steps on stored content, in resumable batches
+type ReanalyzeCommand struct {
+	service *analysis.ReanalysisService
+	out     io.Writer
+}
+
+// NewReanalyzeCommand creates a new ReanalyzeCommand
+func NewReanalyzeCommand(service *analysis.ReanalysisService, out io.Writer) *ReanalyzeCommand {
+	return &ReanalyzeCommand{
+		service: service,
+		out:     out,
+	}
+}
+
+// Name returns the name of the command
+func (c *ReanalyzeCommand) Name() string {
+	return "reanalyze"
+}
+
+// Usage returns a one-line description of the command
+func (c *ReanalyzeCommand) Usage() string {
+	return "Re-run analysis steps on content analysed with outdated models"
+}
+
+// Run runs the command
+func (c *ReanalyzeCommand) Run(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	flags.SetOutput(c.out)
+
+	steps := flags.String("steps", "", "comma-separated steps to force re-run (default: only steps with outdated model versions)")
+	contentType := flags.String("type", "", "only content with this classification")
+	language := flags.String("lang", "", "only content in this language")
+	urlPrefix := flags.String("url-prefix", "", "only content whose URL starts with this prefix")
+	before := flags.String("before", "", "only content created before this date (YYYY-MM-DD)")
+	batchSize := flags.Int("batch-size", 100, "number of content items per batch")
+	after := flags.String("after", "", "resume after the content with this ID")
+	checkpointPath := flags.String("checkpoint", "", "file storing the last processed ID, used to resume interrupted runs")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	opts := analysis.ReanalysisOptions{
+		Filter: content.ReanalysisFilter{
+			ContentType: content.ContentType(*contentType),
+			Language:    *language,
+			URLPrefix:   *urlPrefix,
+		},
+		BatchSize: *batchSize,
+	}
+
+	if *steps != "" {
+		parsed, err := parseSteps(*steps, c.service.EnabledSteps())
+		if err != nil {
+			return err
+		}
+		opts.Steps = parsed
+	}
+
+	if *before != "" {
+		createdBefore, err := time.Parse("2006-01-02", *before)
+		if err != nil {
+			return fmt.Errorf("invalid --before date: %w", err)
+		}
+		opts.Filter.CreatedBefore = createdBefore
+	}
+
+	afterID, err := resumeID(*after, *checkpointPath)
+	if err != nil {
+		return err
+	}
+	opts.Filter.AfterID = afterID
+
+	runResult := c.service.Run(ctx, opts, func(progress analysis.ReanalysisProgress) error {
+		fmt.Fprintf(c.out, "scanned=%d updated=%d failed=%d last_id=%s\n",
+			progress.Scanned, progress.Updated, progress.Failed, progress.LastID)
+
+		if *checkpointPath == "" {
+			return nil
+		}
+		return os.WriteFile(*checkpointPath, []byte(progress.LastID.String()+"\n"), 0o644)
+	})
+	if runResult.IsErr() {
+		return fmt.Errorf("reanalysis failed: %w", runResult.Error())
+	}
+
+	progress := runResult.Unwrap()
+	fmt.Fprintf(c.out, "done: scanned=%d updated=%d failed=%d\n", progress.Scanned, progress.Updated, progress.Failed)
+
+	return nil
+}
+
+// parseSteps parses comma-separated step names, accepting only known steps the service has enabled
+func parseSteps(value string, enabled []content.AnalysisStep) ([]content.AnalysisStep, error) {
+	steps := make([]content.AnalysisStep, 0)
+	for _, name := range strings.Split(value, ",") {
+		step := content.AnalysisStep(strings.TrimSpace(name))
+		if !slices.Contains(content.AllAnalysisSteps, step) {
+			return nil, fmt.Errorf("invalid --steps: unknown step %q, valid steps are %s", step, joinSteps(content.AllAnalysisSteps))
+		}
+		if !slices.Contains(enabled, step) {
+			return nil, fmt.Errorf("invalid --steps: step %q is not configured, configured steps are %s", step, joinSteps(enabled))
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+func joinSteps(steps []content.AnalysisStep) string {
+	names := make([]string, len(steps))
+	for i, step := range steps {
+		names[i] = string(step)
+	}
+	return strings.Join(names, ", ")
+}
+
+// resumeID returns the ID to resume from, an explicit --after takes precedence over the checkpoint file
+func resumeID(after, checkpointPath string) (uuid.UUID, error) {
+	if after != "" {
+		id, err := uuid.Parse(after)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("invalid --after ID: %w", err)
+		}
+		return id, nil
+	}
+
+	if checkpointPath == "" {
+		return uuid.Nil, nil
+	}
+
+	data, err := os.ReadFile(checkpointPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return uuid.Nil, nil
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	id, err := uuid.Parse(strings.TrimSpace(string(data)))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid checkpoint %s: %w", checkpointPath, err)
+	}
+	return id, nil
+}
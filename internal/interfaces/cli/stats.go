@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/gerthdala/webcrawler/internal/domain/crawler"
+)
+
+// StatsCommand prints the number of URLs by status, broken down by reason
+type StatsCommand struct {
+	service *crawler.CrawlService
+	out     io.Writer
+}
+
+// NewStatsCommand creates a new StatsCommand
+func NewStatsCommand(service *crawler.CrawlService, out io.Writer) *StatsCommand {
+	return &StatsCommand{
+		service: service,
+		out:     out,
+	}
+}
+
+// Name returns the name of the command
+func (c *StatsCommand) Name() string {
+	return "stats"
+}
+
+// Usage returns a one-line description of the command
+func (c *StatsCommand) Usage() string {
+	return "Show the number of URLs by status and reason"
+}
+
+// Run runs the command
+func (c *StatsCommand) Run(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	flags.SetOutput(c.out)
+
+	reasons := flags.Bool("reasons", true, "break each status down by reason")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	statsResult := c.service.Stats(ctx)
+	if statsResult.IsErr() {
+		return fmt.Errorf("stats failed: %w", statsResult.Error())
+	}
+
+	// Counts are ordered by status, so each status is totalled before its reasons are printed
+	counts := statsResult.Unwrap()
+	total := 0
+	for i := 0; i < len(counts); {
+		status := counts[i].Status
+		statusTotal := 0
+		j := i
+		for ; j < len(counts) && counts[j].Status == status; j++ {
+			statusTotal += counts[j].Count
+		}
+
+		fmt.Fprintf(c.out, "%-14s %8d\n", status, statusTotal)
+		for _, count := range counts[i:j] {
+			if *reasons && count.Reason != "" {
+				fmt.Fprintf(c.out, "  %-20s %8d\n", count.Reason, count.Count)
+			}
+		}
+
+		total += statusTotal
+		i = j
+	}
+	fmt.Fprintf(c.out, "%-14s %8d\n", "total", total)
+
+	return nil
+}